@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	cfg "github.com/danushk97/image-analyzer/internal/config"
+	"github.com/danushk97/image-analyzer/pkg/env"
+)
+
+var (
+	flags    = flag.NewFlagSet("config", flag.ExitOnError)
+	redacted = flags.Bool("redacted", false, "Replace the secrets with [REDACTED]")
+)
+
+func main() {
+	flags.Usage = usage
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("error parsing flags: %v", err)
+	}
+
+	switch command {
+	case "print":
+		// the config is loaded and validated as the services do
+		config := cfg.NewConfig(env.GetEnv())
+		if err := config.Print(os.Stdout, *redacted); err != nil {
+			log.Fatalf("could not print the config, err:%+v", err)
+		}
+
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Println("Usage: config COMMAND [flags]")
+	fmt.Println(usageCommands)
+	flags.PrintDefaults()
+}
+
+var usageCommands = `
+	Commands:
+		print                Print the config of the APP_ENV environment as JSON
+`
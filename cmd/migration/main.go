@@ -9,7 +9,7 @@ import (
 	"os"
 
 	cfg "github.com/danushk97/image-analyzer/internal/config"
-	_ "github.com/danushk97/image-analyzer/internal/database/migrations"
+	"github.com/danushk97/image-analyzer/internal/database/migrations"
 	"github.com/danushk97/image-analyzer/pkg/env"
 	storage "github.com/danushk97/image-analyzer/pkg/storage/sql"
 	"github.com/pressly/goose/v3"
@@ -35,6 +35,11 @@ func main() {
 		log.Fatalf("could not set dialect, err:%+v", err)
 	}
 
+	// the statements of the migrations are written in the dialect of the database
+	if err = migrations.SetDialect(config.Store.SQL.Dialect); err != nil {
+		log.Fatalf("could not set dialect, err:%+v", err)
+	}
+
 	// storage service is the service for main persistent store
 	db, err := storage.NewDb(config.Store.SQL)
 
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/danushk97/image-analyzer/internal/config"
+	"github.com/danushk97/image-analyzer/internal/database/migrations"
+	"github.com/danushk97/image-analyzer/internal/metrics"
+	"github.com/danushk97/image-analyzer/internal/outbox/relay"
+	"github.com/danushk97/image-analyzer/internal/outbox/sink"
+	"github.com/danushk97/image-analyzer/internal/webhook"
+	webhookCore "github.com/danushk97/image-analyzer/internal/webhook/service"
+	"github.com/danushk97/image-analyzer/pkg/env"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+// relayServiceSuffix tells the spans of the relay from the ones of the API server
+const relayServiceSuffix = "-relay"
+
+func main() {
+	env := env.GetEnv()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// load configurations and distribute parts of it in main
+	config := config.NewConfig(env)
+
+	// the logs are written to the sinks until the end of main
+	logger, err := pkgLogger.Init(config.Logger)
+	if err != nil {
+		fmt.Printf("could not create the logger, err:%+v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Close()
+
+	// the records held in the memory of the server are dispatched by the server itself
+	if config.Store.IsInProcess() {
+		logger.Fatalf("the relay cannot read the store held in the memory of the server, it runs in the server")
+	}
+
+	// the spans are exported until the end of main
+	tracerProvider, err := tracing.NewProvider(ctx, config.Tracing, config.App.ServiceName+relayServiceSuffix)
+	if err != nil {
+		logger.Fatalf("could not create the tracer provider, err:%+v", err)
+	}
+	defer func() {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), tracing.ShutdownTimeout)
+		defer cancelShutdown()
+		if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+			logger.Errorf("could not flush the spans, err:%+v", err)
+		}
+	}()
+
+	// storage service is the service for main persistent store
+	storageService, err := storage.New(ctx, config.Store)
+	if err != nil {
+		logger.Fatalf(
+			"could not create database, err:%+v", err,
+		)
+	}
+
+	// the SQLite database of the local development is migrated at startup
+	if sqlRepo, ok := storageService.(*sql.Repo); ok && sqlRepo.Db.GetDialect() == sql.DialectSQLite {
+		dbConn, err := sqlRepo.Db.GetInstance(ctx).DB()
+		if err == nil {
+			err = migrations.Up(ctx, dbConn, sql.DialectSQLite)
+		}
+		if err != nil {
+			logger.Fatalf("could not migrate the database, err:%+v", err)
+		}
+	}
+
+	// the connection pool stats are exported along with the other metrics
+	if sqlRepo, ok := storageService.(*sql.Repo); ok {
+		collector, err := sqlRepo.Db.NewStatsCollector()
+		if err != nil {
+			logger.Fatalf("could not collect the database stats, err:%+v", err)
+		}
+		metrics.Registry.MustRegister(collector)
+	}
+
+	webhookService := webhookCore.NewService(
+		webhookCore.WithStorage(storageService),
+		webhookCore.WithConfig(config.Webhooks),
+	)
+
+	sinks, err := sink.New(config.Outbox.Sinks)
+	if err != nil {
+		logger.Fatalf("could not create sinks, err:%+v", err)
+	}
+
+	// the events are always fanned out to the webhooks of the users
+	sinks = append(sinks, webhook.NewOutboxSink(webhookService))
+
+	outboxRelay := relay.NewRelay(
+		relay.WithStorage(storageService),
+		relay.WithSinks(sinks...),
+		relay.WithConfig(config.Outbox),
+	)
+	defer outboxRelay.Close()
+
+	go func() {
+		sigterm := make(chan os.Signal, 1)
+		signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
+		<-sigterm
+
+		logger.Info(ctx, "sigterm received")
+		cancel()
+	}()
+
+	logger.Info(ctx, "outbox relay running")
+
+	// the reloadable settings are applied when the config files change
+	configWatcher, err := config.Watch(env)
+	if err != nil {
+		logger.Fatalf("could not watch the config, err:%+v", err)
+	}
+
+	// delivers the enqueued events to the webhooks until the context is cancelled
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		webhookService.RunDispatcher(ctx)
+	}()
+
+	// the metrics of the relay are served only on their own listener
+	if config.Metrics.RelayAddress != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := metrics.NewServer(config.Metrics.RelayAddress).Run(ctx); err != nil {
+				logger.Errorf("metrics server shutdown with err(s):%+v", err)
+			}
+		}()
+	}
+
+	// excludes the unhealthy read replicas until the context is done
+	if sqlRepo, ok := storageService.(*sql.Repo); ok {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sqlRepo.Db.MonitorReplicas(ctx)
+		}()
+	}
+
+	// applies the changes of the config files until the context is done
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := configWatcher.Run(ctx); err != nil {
+			logger.Errorf("config watcher stopped with err(s):%+v", err)
+		}
+	}()
+
+	// run dispatches the events until the context is cancelled
+	outboxRelay.Run(ctx)
+
+	wg.Wait()
+
+	logger.Info(ctx, "gracefully shutdown")
+}
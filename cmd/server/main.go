@@ -8,14 +8,29 @@ import (
 	"sync"
 	"syscall"
 
-	"github.com/danushk97/image-analyzer/internal/config"
+	"github.com/danushk97/image-analyzer/internal/admin"
+	cfg "github.com/danushk97/image-analyzer/internal/config"
+	"github.com/danushk97/image-analyzer/internal/database/migrations"
+	featureFlagCore "github.com/danushk97/image-analyzer/internal/featureflag/service"
+	"github.com/danushk97/image-analyzer/internal/grpcserver"
 	health "github.com/danushk97/image-analyzer/internal/health"
+	idempotencyCore "github.com/danushk97/image-analyzer/internal/idempotency/service"
 	"github.com/danushk97/image-analyzer/internal/image_metadata"
+	imageEvents "github.com/danushk97/image-analyzer/internal/image_metadata/events"
 	imageMetaCore "github.com/danushk97/image-analyzer/internal/image_metadata/service"
+	"github.com/danushk97/image-analyzer/internal/metrics"
+	"github.com/danushk97/image-analyzer/internal/openapi"
+	"github.com/danushk97/image-analyzer/internal/outbox/relay"
+	outboxCore "github.com/danushk97/image-analyzer/internal/outbox/service"
+	"github.com/danushk97/image-analyzer/internal/outbox/sink"
 	srv "github.com/danushk97/image-analyzer/internal/server"
+	"github.com/danushk97/image-analyzer/internal/webhook"
+	webhookCore "github.com/danushk97/image-analyzer/internal/webhook/service"
 	"github.com/danushk97/image-analyzer/pkg/env"
 	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
 	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
 )
 
 func main() {
@@ -23,10 +38,29 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	logger := pkgLogger.NewLogger()
-
 	// load configurations and distribute parts of it in main
-	config := config.NewConfig(env)
+	config := cfg.NewConfig(env)
+
+	// the logs are written to the sinks until the end of main
+	logger, err := pkgLogger.Init(config.Logger)
+	if err != nil {
+		fmt.Printf("could not create the logger, err:%+v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Close()
+
+	// the spans are exported until the end of main
+	tracerProvider, err := tracing.NewProvider(ctx, config.Tracing, config.App.ServiceName)
+	if err != nil {
+		logger.Fatalf("could not create the tracer provider, err:%+v", err)
+	}
+	defer func() {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), tracing.ShutdownTimeout)
+		defer cancelShutdown()
+		if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+			logger.Error(shutdownCtx, fmt.Sprintf("could not flush the spans, err:%+v", err))
+		}
+	}()
 
 	// storage service is the service for main persistent store
 	storageService, err := storage.New(ctx, config.Store)
@@ -36,21 +70,142 @@ func main() {
 		)
 	}
 
+	// the SQLite database of the local development is migrated at startup
+	if sqlRepo, ok := storageService.(*sql.Repo); ok && sqlRepo.Db.GetDialect() == sql.DialectSQLite {
+		dbConn, err := sqlRepo.Db.GetInstance(ctx).DB()
+		if err == nil {
+			err = migrations.Up(ctx, dbConn, sql.DialectSQLite)
+		}
+		if err != nil {
+			logger.Fatalf("could not migrate the database, err:%+v", err)
+		}
+	}
+
+	// the connection pool stats are exported along with the other metrics
+	if sqlRepo, ok := storageService.(*sql.Repo); ok {
+		collector, err := sqlRepo.Db.NewStatsCollector()
+		if err != nil {
+			logger.Fatalf("could not collect the database stats, err:%+v", err)
+		}
+		metrics.Registry.MustRegister(collector)
+	}
+
+	outboxService := outboxCore.NewService(
+		outboxCore.WithStorage(storageService),
+	)
+
 	imageMetaService := imageMetaCore.NewService(
 		imageMetaCore.WithStorage(storageService),
+		imageMetaCore.WithOutbox(outboxService),
+	)
+
+	idempotencyService := idempotencyCore.NewService(
+		idempotencyCore.WithStorage(storageService),
+		idempotencyCore.WithConfig(config.Idempotency),
+	)
+
+	webhookService := webhookCore.NewService(
+		webhookCore.WithStorage(storageService),
+		webhookCore.WithConfig(config.Webhooks),
+	)
+
+	// the relay cannot read the records held in the memory of the server, the
+	// events and the webhook deliveries are dispatched by the server itself
+	var outboxRelay *relay.Relay
+	if config.Store.IsInProcess() {
+		sinks, err := sink.New(config.Outbox.Sinks)
+		if err != nil {
+			logger.Fatalf("could not create sinks, err:%+v", err)
+		}
+
+		outboxRelay = relay.NewRelay(
+			relay.WithStorage(storageService),
+			relay.WithSinks(append(sinks, webhook.NewOutboxSink(webhookService))...),
+			relay.WithConfig(config.Outbox),
+		)
+		defer outboxRelay.Close()
+	}
+
+	featureFlagService := featureFlagCore.NewService(
+		featureFlagCore.WithStorage(storageService),
+		featureFlagCore.WithConfig(config.Features),
 	)
+	// the configured flags apply until the stored ones are loaded
+	if err := featureFlagService.Refresh(ctx); err != nil {
+		logger.Warnf("could not load the stored feature flags, err:%+v", err)
+	}
+
+	eventOpts := []imageEvents.Option{imageEvents.WithStorage(storageService)}
+	// the events are pushed to the streams only when notifications are supported
+	if _, ok := storageService.(*sql.Repo); ok {
+		if listener, err := sql.NewListener(&config.Store.SQL, imageEvents.Channel); err == nil {
+			eventOpts = append(eventOpts, imageEvents.WithListener(listener))
+		} else {
+			logger.Warnf("image events are not streamed, err:%+v", err)
+		}
+	}
+	eventBroker := imageEvents.NewBroker(eventOpts...)
+
+	spec, err := openapi.NewSpec()
+	if err != nil {
+		logger.Fatalf("could not build the openapi document, err:%+v", err)
+	}
+
+	openAPIServer, err := openapi.NewServer(spec, config.OpenAPI, env)
+	if err != nil {
+		logger.Fatalf("could not create the openapi server, err:%+v", err)
+	}
 
 	healthServer := health.NewServer()
 
-	imageServer := image_metadata.NewServer(imageMetaService)
+	adminServer := admin.NewServer(config.Admin)
+
+	metricsServer := metrics.NewServer(config.Metrics.Address)
+
+	imageServer := image_metadata.NewServer(
+		imageMetaService, idempotencyService, eventBroker, featureFlagService, openAPIServer.Validator())
+
+	rpcServer := image_metadata.NewRPCServer(imageMetaService)
+
+	twirpServer := image_metadata.NewTwirpServer(rpcServer, idempotencyService)
 
-	server := srv.New(ctx, &srv.Config{})
+	graphQLServer := image_metadata.NewGraphQLServer(imageMetaService)
+
+	webhookServer := webhook.NewServer(webhookService, idempotencyService, openAPIServer.Validator())
+
+	server := srv.New(ctx, &srv.Config{
+		ShutdownTimeout: config.Server.ShutdownTimeout,
+		ServerAddress:   config.Server.ServerAddresses.HTTP,
+		ServiceName:     config.App.ServiceName,
+		AccessLog:       config.AccessLog,
+		DebugLogSecret:  config.Admin.Token,
+	})
 
 	server.WithOptions(
+		server.WithOpenAPIServer(openAPIServer),
 		server.WithHealthServer(healthServer),
+		server.WithAdminServer(adminServer),
+		server.WithMetricsServer(metricsServer),
 		server.WithImageMetadataServer(imageServer),
+		server.WithImageMetadataTwirpServer(twirpServer),
+		server.WithImageMetadataGraphQLServer(graphQLServer),
+		server.WithWebhookServer(webhookServer),
+	)
+
+	grpcServer := grpcserver.New(ctx, &config.GRPC)
+
+	grpcServer.WithOptions(
+		grpcServer.WithImageMetadataServer(rpcServer),
 	)
 
+	// the reloadable settings are applied when the config files change
+	configWatcher, err := config.Watch(env, func(reloaded *cfg.Config) {
+		featureFlagService.SetFlags(reloaded.Features.Flags)
+	})
+	if err != nil {
+		logger.Fatalf("could not watch the config, err:%+v", err)
+	}
+
 	// graceful shutdown, no libs required, understand just below
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
@@ -86,6 +241,77 @@ func main() {
 			cancel()
 		}
 	}()
+	// purges the expired idempotency keys until the context is done
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		idempotencyService.RunPurger(ctx)
+	}()
+
+	// serves the metrics when they have a separate listener
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := metricsServer.Run(ctx); err != nil {
+			logger.Error(ctx, fmt.Sprintf("metrics server shutdown with err(s):%+v", err))
+		}
+	}()
+
+	// grpc server shares the services of the http server
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := grpcServer.Run(ctx); err != nil {
+			logger.Error(ctx, fmt.Sprintf("grpc server shutdown with err(s):%+v", err))
+			cancel()
+		}
+	}()
+
+	// dispatches the events and the webhook deliveries held in memory until the context is done
+	if outboxRelay != nil {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			outboxRelay.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			webhookService.RunDispatcher(ctx)
+		}()
+	}
+
+	// listens to the image events until the context is done
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		eventBroker.Run(ctx)
+	}()
+
+	// excludes the unhealthy read replicas until the context is done
+	if sqlRepo, ok := storageService.(*sql.Repo); ok {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sqlRepo.Db.MonitorReplicas(ctx)
+		}()
+	}
+
+	// loads the stored feature flags until the context is done
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		featureFlagService.RunRefresher(ctx)
+	}()
+
+	// applies the changes of the config files until the context is done
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := configWatcher.Run(ctx); err != nil {
+			logger.Error(ctx, fmt.Sprintf("config watcher stopped with err(s):%+v", err))
+		}
+	}()
+
 	logger.Info(ctx, "server(s) running", "log_level", logger.Level())
 
 	// wait for all go routines to shutdown, then exit main
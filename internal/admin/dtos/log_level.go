@@ -0,0 +1,105 @@
+package dtos
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+const (
+	// DefaultDebugTokenTTL is the default time in seconds a debug log token is valid
+	DefaultDebugTokenTTL = 300
+	// MaxDebugTokenTTL is the maximum time in seconds a debug log token is valid
+	MaxDebugTokenTTL = 3600
+)
+
+// UpdateLogLevelRequest changes the global level and the levels of the packages
+type UpdateLogLevelRequest struct {
+	Level    string            `json:"level"`    // Global level, unchanged when empty
+	Packages map[string]string `json:"packages"` // Level per package, an empty level removes the override
+}
+
+func (u *UpdateLogLevelRequest) Validate() errors.IError {
+	err := validation.ValidateStruct(
+		u,
+		validation.Field(&u.Level, validation.By(isLevel)),
+		validation.Field(&u.Packages, validation.Each(validation.By(isLevel))),
+	)
+
+	if err != nil {
+		return errors.NewValidationError(internalErr.ValidationFailure, err)
+	}
+
+	return nil
+}
+
+// CreateDebugTokenRequest defines the validity of a debug log token
+type CreateDebugTokenRequest struct {
+	TTL int `json:"ttl"` // Time in seconds the token is valid
+}
+
+func (c *CreateDebugTokenRequest) Validate() errors.IError {
+	err := validation.ValidateStruct(
+		c,
+		validation.Field(&c.TTL, validation.Min(0), validation.Max(MaxDebugTokenTTL)),
+	)
+
+	if err != nil {
+		return errors.NewValidationError(internalErr.ValidationFailure, err)
+	}
+
+	return nil
+}
+
+// GetTTL returns the requested validity, the default one when unset
+func (c *CreateDebugTokenRequest) GetTTL() int {
+	if c.TTL == 0 {
+		return DefaultDebugTokenTTL
+	}
+
+	return c.TTL
+}
+
+// LogLevelResponse holds the global level and the levels of the packages
+type LogLevelResponse struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages"`
+}
+
+// DebugTokenResponse holds a token enabling the debug logs of the requests
+type DebugTokenResponse struct {
+	Header    string `json:"header"`
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// NewLogLevelResponse reads the current levels of the logger
+func NewLogLevelResponse() *LogLevelResponse {
+	packages := map[string]string{}
+	for pkg, lvl := range pkgLogger.GetPackageLevels() {
+		packages[pkg] = lvl.String()
+	}
+
+	return &LogLevelResponse{
+		Level:    pkgLogger.GetLevel().String(),
+		Packages: packages,
+	}
+}
+
+// isLevel validates the name of a level, empty values are allowed
+func isLevel(value interface{}) error {
+	text, _ := value.(string)
+	if text == "" {
+		return nil
+	}
+
+	if _, err := pkgLogger.ParseLevel(text); err != nil {
+		return fmt.Errorf("must be one of debug, info, warn, error, dpanic, panic, fatal")
+	}
+
+	return nil
+}
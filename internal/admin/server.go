@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/admin/dtos"
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// Config holds the admin configurations
+type Config struct {
+	// Token authenticates the admin requests as a bearer token and signs
+	// the debug log tokens, the admin routes are disabled when empty
+	Token string
+}
+
+// AdminServer serves the operational endpoints of the service
+type AdminServer struct {
+	config Config
+}
+
+// NewServer creates a new admin server
+func NewServer(config Config) *AdminServer {
+	return &AdminServer{config: config}
+}
+
+func (as *AdminServer) SetupRoutes(r *gin.Engine) {
+	if as.config.Token == "" {
+		return
+	}
+
+	adminApi := r.Group("/admin")
+	adminApi.Use(middlewares.AdminAuthMiddleware(as.config.Token))
+
+	adminApi.GET("/log/level", as.GetLogLevel)
+	adminApi.PUT("/log/level", as.UpdateLogLevel)
+	adminApi.POST("/log/debug-token", as.CreateDebugToken)
+}
+
+func (as *AdminServer) GetLogLevel(gc *gin.Context) {
+	gc.JSON(http.StatusOK, dtos.NewLogLevelResponse())
+}
+
+// UpdateLogLevel changes the levels of the logger until the next restart
+func (as *AdminServer) UpdateLogLevel(gc *gin.Context) {
+	logger := pkgLogger.Ctx(gc.Request.Context())
+
+	request := &dtos.UpdateLogLevelRequest{}
+	if bindErr := gc.ShouldBindJSON(request); bindErr != nil {
+		err := errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(bindErr)
+		logger.WithError(err).Error("INVALID_REQUEST")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		logger.WithError(err).Error("VALIDATION_FAILURE")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	// the levels are validated above
+	if request.Level != "" {
+		lvl, _ := pkgLogger.ParseLevel(request.Level)
+		pkgLogger.SetLevel(lvl)
+	}
+	for pkg, text := range request.Packages {
+		if text == "" {
+			pkgLogger.ResetPackageLevel(pkg)
+			continue
+		}
+		lvl, _ := pkgLogger.ParseLevel(text)
+		pkgLogger.SetPackageLevel(pkg, lvl)
+	}
+
+	response := dtos.NewLogLevelResponse()
+	logger.WithFields(map[string]interface{}{
+		"level":    response.Level,
+		"packages": response.Packages,
+	}).Warn("LOG_LEVEL_CHANGED")
+
+	gc.JSON(http.StatusOK, response)
+}
+
+// CreateDebugToken signs a token enabling the debug logs of the requests carrying it
+func (as *AdminServer) CreateDebugToken(gc *gin.Context) {
+	logger := pkgLogger.Ctx(gc.Request.Context())
+
+	request := &dtos.CreateDebugTokenRequest{}
+	if bindErr := gc.ShouldBindJSON(request); bindErr != nil {
+		err := errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(bindErr)
+		logger.WithError(err).Error("INVALID_REQUEST")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		logger.WithError(err).Error("VALIDATION_FAILURE")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(request.GetTTL()) * time.Second)
+
+	gc.JSON(http.StatusCreated, &dtos.DebugTokenResponse{
+		Header:    constants.HeaderDebugLog,
+		Token:     middlewares.NewDebugLogToken(as.config.Token, expiresAt),
+		ExpiresAt: expiresAt.Unix(),
+	})
+}
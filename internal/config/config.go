@@ -4,16 +4,60 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/danushk97/image-analyzer/internal/admin"
+	featureflag "github.com/danushk97/image-analyzer/internal/featureflag/service"
+	"github.com/danushk97/image-analyzer/internal/grpcserver"
+	idempotency "github.com/danushk97/image-analyzer/internal/idempotency/service"
+	"github.com/danushk97/image-analyzer/internal/metrics"
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	"github.com/danushk97/image-analyzer/internal/openapi"
+	"github.com/danushk97/image-analyzer/internal/outbox/relay"
+	webhook "github.com/danushk97/image-analyzer/internal/webhook/service"
 	"github.com/danushk97/image-analyzer/pkg/configloader"
+	"github.com/danushk97/image-analyzer/pkg/logger"
 	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
 )
 
+// ReloadableKeys are the keys, or sections, applied without a restart when
+// the config files change, the changes of the other keys are rejected
+var ReloadableKeys = []string{
+	"logger.level",
+	"accessLog",
+	"features.flags",
+}
+
 // Config holds the entire configuration for the service
 type Config struct {
 	// App configurations
 	App App
 
+	// Server configurations of the HTTP server
+	Server Server
+
+	Logger logger.Config
+
 	Store storage.Config
+
+	GRPC grpcserver.Config
+
+	OpenAPI openapi.Config
+
+	Metrics metrics.Config
+
+	AccessLog middlewares.AccessLogConfig
+
+	Admin admin.Config
+
+	Tracing tracing.Config
+
+	Idempotency idempotency.Config
+
+	Features featureflag.Config
+
+	Outbox relay.Config
+
+	Webhooks webhook.Config
 }
 
 // App contains application-specific config values
@@ -28,8 +72,22 @@ type App struct {
 	StoreChoice string // valid values: sql,dynamodb
 }
 
-// NewConfig creates new instance of the
-// Config from the config file: <env>.toml
+// Server contains the HTTP server config values
+type Server struct {
+	// ShutdownTimeout is the time in seconds allowed to serve the ongoing requests on shutdown
+	ShutdownTimeout int
+	// ServerAddresses are the addresses the server listens on
+	ServerAddresses ServerAddresses
+}
+
+// ServerAddresses contains the addresses of the servers
+type ServerAddresses struct {
+	// HTTP is the address of the HTTP server, e.g. :8081
+	HTTP string
+}
+
+// NewConfig creates new instance of the Config from the config
+// file: <env>.toml, exiting when it is invalid
 func NewConfig(env string) *Config {
 	var config Config
 
@@ -40,5 +98,37 @@ func NewConfig(env string) *Config {
 		os.Exit(1)
 	}
 
+	if err := config.Validate(); err != nil {
+		fmt.Printf("invalid config: %v\n", err)
+		os.Exit(1)
+	}
+
 	return &config
 }
+
+// Watch creates the watcher of the config files of the env, the
+// subscribers are called with the reloaded config
+func (c *Config) Watch(env string, subscribers ...func(*Config)) (*configloader.Watcher, error) {
+	watcher, err := configloader.NewDefaultLoader().Watch(env, c, ReloadableKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscriber := range append([]func(*Config){applyReloadable}, subscribers...) {
+		subscriber := subscriber
+		watcher.Subscribe(func(event configloader.Event) {
+			subscriber(event.Config.(*Config))
+		})
+	}
+
+	return watcher, nil
+}
+
+// applyReloadable applies the reloadable settings to the running services
+func applyReloadable(c *Config) {
+	if level, err := logger.ParseLevel(c.Logger.Level); err == nil && c.Logger.Level != "" {
+		logger.SetLevel(level)
+	}
+
+	middlewares.SetAccessLogConfig(c.AccessLog)
+}
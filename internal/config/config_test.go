@@ -0,0 +1,79 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/pkg/configloader"
+)
+
+// loadConfig loads the config of the env from the config files of the repo
+func loadConfig(t *testing.T, env string) Config {
+	t.Helper()
+
+	var config Config
+	loader := configloader.NewLoader(configloader.NewOptions(
+		configloader.DefaultConfigType, "../../config", configloader.DefaultConfigFileName,
+	))
+	if err := loader.Load(env, &config); err != nil {
+		t.Fatalf("load %v config: %v", env, err)
+	}
+
+	return config
+}
+
+func TestConfigFilesAreValid(t *testing.T) {
+	config := loadConfig(t, "dev")
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected the dev config to be valid, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(c *Config)
+		field  string
+	}{
+		{name: "missing service name", update: func(c *Config) { c.App.ServiceName = "" }, field: "ServiceName"},
+		{name: "missing shutdown timeout", update: func(c *Config) { c.Server.ShutdownTimeout = 0 }, field: "ShutdownTimeout"},
+		{name: "missing http address", update: func(c *Config) { c.Server.ServerAddresses.HTTP = "" }, field: "HTTP"},
+		{name: "unknown store", update: func(c *Config) { c.Store.Choice = "mongo" }, field: "Choice"},
+		{name: "missing database password", update: func(c *Config) { c.Store.SQL.Password = "" }, field: "Password"},
+		{name: "unknown trace exporter", update: func(c *Config) { c.Tracing.Exporter = "jaeger" }, field: "Exporter"},
+		{name: "trace sample ratio above 1", update: func(c *Config) { c.Tracing.SampleRatio = 2 }, field: "SampleRatio"},
+		{name: "negative access log sample rate", update: func(c *Config) { c.AccessLog.SampleRate = -0.5 }, field: "SampleRate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := loadConfig(t, "dev")
+			tt.update(&config)
+
+			err := config.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.field) {
+				t.Fatalf("expected an error on %v, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestPrintRedacted(t *testing.T) {
+	config := loadConfig(t, "dev")
+
+	var redacted, plain bytes.Buffer
+	if err := config.Print(&redacted, true); err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	if err := config.Print(&plain, false); err != nil {
+		t.Fatalf("print: %v", err)
+	}
+
+	if strings.Contains(redacted.String(), config.Store.SQL.Password) {
+		t.Error("expected the database password to be redacted")
+	}
+	if !strings.Contains(plain.String(), config.Store.SQL.Password) {
+		t.Error("expected the database password to be printed when not redacted")
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/danushk97/image-analyzer/pkg/configloader"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// Print writes the config as indented JSON, the secrets which are
+// set are replaced with [REDACTED] when redacted is true
+func (c *Config) Print(w io.Writer, redacted bool) error {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(encoded, &settings); err != nil {
+		return err
+	}
+
+	if redacted {
+		redactSecrets(settings)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(settings)
+}
+
+// redactSecrets replaces the non empty secrets of the settings and their sections
+func redactSecrets(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, setting := range v {
+			if configloader.IsSecretKey(key) && setting != "" {
+				v[key] = pkgLogger.Redacted
+				continue
+			}
+			redactSecrets(setting)
+		}
+
+	case []interface{}:
+		for _, setting := range v {
+			redactSecrets(setting)
+		}
+	}
+}
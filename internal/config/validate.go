@@ -0,0 +1,41 @@
+package config
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Validate checks the config, the sections implementing
+// validation.Validatable are validated along with it
+func (c Config) Validate() error {
+	return validation.ValidateStruct(
+		&c,
+		validation.Field(&c.App),
+		validation.Field(&c.Server),
+		validation.Field(&c.Store),
+		validation.Field(&c.Tracing),
+		validation.Field(&c.AccessLog),
+		validation.Field(&c.Features),
+	)
+}
+
+func (a App) Validate() error {
+	return validation.ValidateStruct(
+		&a,
+		validation.Field(&a.ServiceName, validation.Required),
+	)
+}
+
+func (s Server) Validate() error {
+	return validation.ValidateStruct(
+		&s,
+		validation.Field(&s.ShutdownTimeout, validation.Required, validation.Min(1)),
+		validation.Field(&s.ServerAddresses),
+	)
+}
+
+func (s ServerAddresses) Validate() error {
+	return validation.ValidateStruct(
+		&s,
+		validation.Field(&s.HTTP, validation.Required),
+	)
+}
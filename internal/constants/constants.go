@@ -3,8 +3,20 @@ package constants
 const (
 	StatusInitiated = "STATUS_INITIATED"
 
+	AggregateImage = "image"
+
+	EventImageCreated = "image.created"
+	EventImageDeleted = "image.deleted"
+
 	HeaderUserId    = "x-user-id"
 	HeaderRequestId = "x-request-id"
+	HeaderDebugLog  = "x-debug-log"
+
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
 
 	RequestPath = "request_path"
+
+	// FeatureImageEvents gates the stream of the image events
+	FeatureImageEvents = "image-events"
 )
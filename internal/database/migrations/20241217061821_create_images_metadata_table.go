@@ -13,9 +13,9 @@ func init() {
 
 func upCreateImagesMetadataTable(ctx context.Context, tx *sql.Tx) error {
 	// This code is executed when the migration is applied.
-	_, err := tx.Exec(`CREATE TABLE images_metadata (
-    	id UUID PRIMARY KEY,
-    	user_id UUID NOT NULL,
+	_, err := tx.Exec(statement(`CREATE TABLE images_metadata (
+    	id {{.UUID}} PRIMARY KEY,
+    	user_id {{.UUID}} NOT NULL,
     	filename VARCHAR(255) NOT NULL,
     	file_type VARCHAR(50) NOT NULL,
     	file_size BIGINT NOT NULL,
@@ -25,7 +25,7 @@ func upCreateImagesMetadataTable(ctx context.Context, tx *sql.Tx) error {
     	analysis_result TEXT,
     	created_at BIGINT NOT NULL,
     	updated_at BIGINT NOT NULL
-	);`)
+	);`))
 
 	return err
 }
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateIdempotencyKeysTable, downCreateIdempotencyKeysTable)
+}
+
+func upCreateIdempotencyKeysTable(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(statement(`CREATE TABLE idempotency_keys (
+    	id {{.UUID}} PRIMARY KEY,
+    	user_id {{.UUID}} NOT NULL,
+    	{{quote "key"}} VARCHAR(255) NOT NULL,
+    	request_hash VARCHAR(64) NOT NULL,
+    	response_status INT NOT NULL DEFAULT 0,
+    	response_type VARCHAR(255),
+    	response_body TEXT,
+    	expires_at BIGINT NOT NULL,
+    	created_at BIGINT NOT NULL,
+    	updated_at BIGINT NOT NULL
+	);`))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(statement(`CREATE UNIQUE INDEX idempotency_keys_user_id_key_idx
+		ON idempotency_keys (user_id, {{quote "key"}});`))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE INDEX idempotency_keys_expires_at_idx
+		ON idempotency_keys (expires_at);`)
+
+	return err
+}
+
+func downCreateIdempotencyKeysTable(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`DROP TABLE IF EXISTS idempotency_keys`)
+
+	return err
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+
+	storage "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateOutboxEventsTable, downCreateOutboxEventsTable)
+}
+
+func upCreateOutboxEventsTable(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(statement(`CREATE TABLE outbox_events (
+    	id {{.UUID}} PRIMARY KEY,
+    	sequence {{.Serial}},
+    	aggregate_type VARCHAR(50) NOT NULL,
+    	aggregate_id VARCHAR(255) NOT NULL,
+    	event_type VARCHAR(100) NOT NULL,
+    	payload TEXT NOT NULL,
+    	dispatched_at BIGINT NOT NULL DEFAULT 0,
+    	attempts INT NOT NULL DEFAULT 0,
+    	claimed_until BIGINT NOT NULL DEFAULT 0,
+    	dead_lettered_at BIGINT NOT NULL DEFAULT 0,
+    	last_error TEXT,
+    	created_at BIGINT NOT NULL,
+    	updated_at BIGINT NOT NULL
+	);`))
+	if err != nil {
+		return err
+	}
+
+	if dialect == storage.DialectSQLite {
+		_, err = tx.Exec(`CREATE TRIGGER outbox_events_sequence
+			AFTER INSERT ON outbox_events
+			BEGIN
+				UPDATE outbox_events SET sequence = NEW.rowid WHERE rowid = NEW.rowid;
+			END;`)
+		if err != nil {
+			return err
+		}
+	}
+
+	// only the pending events are polled by the relay
+	_, err = tx.Exec(statement(`CREATE INDEX outbox_events_pending_idx
+		ON outbox_events {{if .PartialIndexes}}(sequence) WHERE dispatched_at = 0 AND dead_lettered_at = 0{{else}}(dispatched_at, dead_lettered_at, sequence){{end}};`))
+
+	return err
+}
+
+func downCreateOutboxEventsTable(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`DROP TABLE IF EXISTS outbox_events`)
+
+	return err
+}
@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateWebhooksTables, downCreateWebhooksTables)
+}
+
+func upCreateWebhooksTables(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(statement(`CREATE TABLE webhooks (
+    	id {{.UUID}} PRIMARY KEY,
+    	user_id {{.UUID}} NOT NULL,
+    	url VARCHAR(2048) NOT NULL,
+    	secret VARCHAR(128) NOT NULL,
+    	events TEXT NOT NULL,
+    	active BOOLEAN NOT NULL,
+    	created_at BIGINT NOT NULL,
+    	updated_at BIGINT NOT NULL
+	);`))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE INDEX webhooks_user_id_idx ON webhooks (user_id);`)
+	if err != nil {
+		return err
+	}
+
+	// the foreign key is declared on the table, MySQL ignores the inline references
+	_, err = tx.Exec(statement(`CREATE TABLE webhook_deliveries (
+    	id {{.UUID}} PRIMARY KEY,
+    	webhook_id {{.UUID}} NOT NULL,
+    	event_id VARCHAR(255) NOT NULL,
+    	event_type VARCHAR(100) NOT NULL,
+    	payload TEXT NOT NULL,
+    	status VARCHAR(50) NOT NULL,
+    	attempts INT NOT NULL DEFAULT 0,
+    	response_code INT NOT NULL DEFAULT 0,
+    	last_error TEXT,
+    	next_attempt_at BIGINT NOT NULL,
+    	delivered_at BIGINT NOT NULL DEFAULT 0,
+    	created_at BIGINT NOT NULL,
+    	updated_at BIGINT NOT NULL,
+    	FOREIGN KEY (webhook_id) REFERENCES webhooks (id)
+	);`))
+	if err != nil {
+		return err
+	}
+
+	// an event is delivered at most once per webhook
+	_, err = tx.Exec(`CREATE UNIQUE INDEX webhook_deliveries_webhook_id_event_id_idx
+		ON webhook_deliveries (webhook_id, event_id);`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(statement(`CREATE INDEX webhook_deliveries_pending_idx
+		ON webhook_deliveries {{if .PartialIndexes}}(next_attempt_at) WHERE status = 'PENDING'{{else}}(status, next_attempt_at){{end}};`))
+
+	return err
+}
+
+func downCreateWebhooksTables(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`DROP TABLE IF EXISTS webhook_deliveries`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DROP TABLE IF EXISTS webhooks`)
+
+	return err
+}
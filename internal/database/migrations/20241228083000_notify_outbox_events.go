@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+
+	storage "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+func init() {
+	goose.AddMigrationContext(upNotifyOutboxEvents, downNotifyOutboxEvents)
+}
+
+func upNotifyOutboxEvents(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	// The image events are streamed only with the postgres notifications.
+	if dialect != storage.DialectPostgres {
+		return nil
+	}
+
+	// The notification is sent on commit, only the sequence and the
+	// owner are sent as the payload of a notification is size limited.
+	_, err := tx.Exec(`CREATE OR REPLACE FUNCTION notify_outbox_event() RETURNS trigger AS $$
+	BEGIN
+		PERFORM pg_notify('outbox_events', json_build_object(
+			'sequence', NEW.sequence,
+			'aggregate_type', NEW.aggregate_type,
+			'user_id', NEW.payload::jsonb ->> 'user_id'
+		)::text);
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql;`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TRIGGER outbox_events_notify
+		AFTER INSERT ON outbox_events
+		FOR EACH ROW EXECUTE FUNCTION notify_outbox_event();`)
+
+	return err
+}
+
+func downNotifyOutboxEvents(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	if dialect != storage.DialectPostgres {
+		return nil
+	}
+
+	_, err := tx.Exec(`DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DROP FUNCTION IF EXISTS notify_outbox_event()`)
+
+	return err
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddRequestContextToJobs, downAddRequestContextToJobs)
+}
+
+func upAddRequestContextToJobs(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	// The request ID and the trace of the request recording the jobs
+	// are kept to propagate them to the calls made by the workers.
+	statements := []string{
+		`ALTER TABLE outbox_events ADD COLUMN request_id VARCHAR(128) NOT NULL DEFAULT ''`,
+		`ALTER TABLE outbox_events ADD COLUMN trace_parent VARCHAR(55) NOT NULL DEFAULT ''`,
+		`ALTER TABLE webhook_deliveries ADD COLUMN request_id VARCHAR(128) NOT NULL DEFAULT ''`,
+		`ALTER TABLE webhook_deliveries ADD COLUMN trace_parent VARCHAR(55) NOT NULL DEFAULT ''`,
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func downAddRequestContextToJobs(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	statements := []string{
+		`ALTER TABLE webhook_deliveries DROP COLUMN trace_parent`,
+		`ALTER TABLE webhook_deliveries DROP COLUMN request_id`,
+		`ALTER TABLE outbox_events DROP COLUMN trace_parent`,
+		`ALTER TABLE outbox_events DROP COLUMN request_id`,
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
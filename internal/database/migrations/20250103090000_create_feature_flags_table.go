@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateFeatureFlagsTable, downCreateFeatureFlagsTable)
+}
+
+func upCreateFeatureFlagsTable(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(statement(`CREATE TABLE feature_flags (
+    	id {{.UUID}} PRIMARY KEY,
+    	name VARCHAR(255) NOT NULL,
+    	enabled BOOLEAN NOT NULL,
+    	percentage INTEGER,
+    	users TEXT NOT NULL,
+    	created_at BIGINT NOT NULL,
+    	updated_at BIGINT NOT NULL
+	);`))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE UNIQUE INDEX feature_flags_name_idx ON feature_flags (name);`)
+
+	return err
+}
+
+func downCreateFeatureFlagsTable(ctx context.Context, tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`DROP TABLE IF EXISTS feature_flags`)
+
+	return err
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	storage "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// dialectSyntax holds the syntax of the statements which differs between the dialects
+type dialectSyntax struct {
+	// UUID is the type of the identifiers
+	UUID string
+	// Serial is the type of the sequences generated by the database
+	Serial string
+	// PartialIndexes tells if the indexes can be limited to some rows
+	PartialIndexes bool
+	// quote is the character quoting the identifiers
+	quote string
+}
+
+var dialects = map[string]dialectSyntax{
+	storage.DialectPostgres: {
+		UUID:           "UUID",
+		Serial:         "BIGSERIAL NOT NULL",
+		PartialIndexes: true,
+		quote:          `"`,
+	},
+	storage.DialectMySQL: {
+		UUID: "CHAR(36)",
+		// the auto incremented columns must be indexed
+		Serial:         "BIGINT NOT NULL AUTO_INCREMENT UNIQUE",
+		PartialIndexes: false,
+		quote:          "`",
+	},
+	storage.DialectSQLite: {
+		UUID: "TEXT",
+		// only the rowid is generated, the sequence is set from it by a trigger
+		Serial:         "INTEGER NOT NULL DEFAULT 0",
+		PartialIndexes: true,
+		quote:          `"`,
+	},
+}
+
+// dialect is the dialect of the migrated database, postgres by default
+var dialect = storage.DialectPostgres
+
+// SetDialect sets the dialect the statements of the migrations are written in
+func SetDialect(name string) error {
+	if _, ok := dialects[name]; !ok {
+		return storage.ErrorUndefinedDialect
+	}
+
+	dialect = name
+	return nil
+}
+
+// statement writes the statement in the dialect, the template reads the
+// fields of dialectSyntax, e.g. {{.UUID}}, and quotes the reserved
+// words with quote, e.g. {{quote "key"}}
+func statement(text string) string {
+	syntax := dialects[dialect]
+
+	tmpl := template.Must(template.New("statement").Funcs(template.FuncMap{
+		"quote": func(identifier string) string {
+			return syntax.quote + identifier + syntax.quote
+		},
+	}).Parse(text))
+
+	var statement bytes.Buffer
+	if err := tmpl.Execute(&statement, syntax); err != nil {
+		panic(fmt.Sprintf("invalid migration statement: %v", err))
+	}
+
+	return statement.String()
+}
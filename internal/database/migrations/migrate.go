@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+
+	"github.com/pressly/goose/v3"
+
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	storage "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// gooseDialects maps the dialects of the storage to the ones of goose
+var gooseDialects = map[string]goose.Dialect{
+	storage.DialectPostgres: goose.DialectPostgres,
+	storage.DialectMySQL:    goose.DialectMySQL,
+	storage.DialectSQLite:   goose.DialectSQLite3,
+}
+
+// Up applies the pending migrations to the database, e.g. to the
+// SQLite database of the local development held in memory
+func Up(ctx context.Context, db *sql.DB, dialect string) error {
+	provider, err := newProvider(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		pkgLogger.Ctx(ctx).WithField("migration", filepath.Base(result.Source.Path)).Info("DB_MIGRATION_APPLIED")
+	}
+
+	return nil
+}
+
+// Reset rolls back all the applied migrations, e.g. to run
+// the repo tests on an empty database
+func Reset(ctx context.Context, db *sql.DB, dialect string) error {
+	provider, err := newProvider(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.DownTo(ctx, 0)
+	return err
+}
+
+// newProvider returns the provider running the migrations written in the dialect
+func newProvider(db *sql.DB, dialect string) (*goose.Provider, error) {
+	if err := SetDialect(dialect); err != nil {
+		return nil, err
+	}
+
+	return goose.NewProvider(gooseDialects[dialect], db, nil)
+}
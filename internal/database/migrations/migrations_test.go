@@ -0,0 +1,143 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pressly/goose/v3"
+
+	storage "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// maxStatements is more than the statements of any migration
+const maxStatements = 20
+
+// forbiddenSyntax matches the syntax a dialect does not support, or
+// ignores as MySQL does with the references declared on the columns
+var forbiddenSyntax = map[string][]*regexp.Regexp{
+	storage.DialectPostgres: {
+		regexp.MustCompile("`"),
+		regexp.MustCompile(`AUTO_INCREMENT`),
+	},
+	storage.DialectMySQL: {
+		regexp.MustCompile(`"`),
+		regexp.MustCompile(`\bUUID\b`),
+		regexp.MustCompile(`SERIAL`),
+		regexp.MustCompile(`(?i)CREATE (UNIQUE )?INDEX[^;]+WHERE`),
+		regexp.MustCompile(`(?i)NOT NULL REFERENCES`),
+		regexp.MustCompile(`(?i)\$\$|plpgsql|TRIGGER`),
+	},
+	storage.DialectSQLite: {
+		regexp.MustCompile("`"),
+		regexp.MustCompile(`AUTO_INCREMENT|SERIAL`),
+		regexp.MustCompile(`(?i)\$\$|plpgsql`),
+	},
+}
+
+// render runs the migration function on a mock of the database
+// and returns the statements it executed
+func render(t *testing.T, fn func(context.Context, *sql.Tx) error) []string {
+	t.Helper()
+
+	var statements []string
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+		func(_ string, actual string) error {
+			statements = append(statements, actual)
+			return nil
+		})))
+	if err != nil {
+		t.Fatalf("create the database mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	for i := 0; i < maxStatements; i++ {
+		mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err = fn(context.Background(), tx); err != nil {
+		t.Fatalf("run the migration: %v", err)
+	}
+
+	return statements
+}
+
+func TestMigrationsRenderInEveryDialect(t *testing.T) {
+	migrations, err := goose.CollectMigrations(".", 0, goose.MaxVersion)
+	if err != nil {
+		t.Fatalf("collect migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("expected the migrations to be registered")
+	}
+	defer func() { _ = SetDialect(storage.DialectPostgres) }()
+
+	for _, name := range []string{storage.DialectPostgres, storage.DialectMySQL, storage.DialectSQLite} {
+		if err := SetDialect(name); err != nil {
+			t.Fatalf("set dialect: %v", err)
+		}
+
+		for _, migration := range migrations {
+			for direction, fn := range map[string]func(context.Context, *sql.Tx) error{
+				"up":   migration.UpFnContext,
+				"down": migration.DownFnContext,
+			} {
+				t.Run(name+"/"+filepath.Base(migration.Source)+"/"+direction, func(t *testing.T) {
+					for _, statement := range render(t, fn) {
+						if strings.Contains(statement, "{{") || strings.Contains(statement, "}}") {
+							t.Errorf("the template is not rendered: %s", statement)
+						}
+						for _, syntax := range forbiddenSyntax[name] {
+							if syntax.MatchString(statement) {
+								t.Errorf("unsupported syntax %q in %s", syntax, statement)
+							}
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestReservedWordsAreQuoted(t *testing.T) {
+	defer func() { _ = SetDialect(storage.DialectPostgres) }()
+
+	for name, quoted := range map[string]string{
+		storage.DialectPostgres: `"key"`,
+		storage.DialectMySQL:    "`key`",
+		storage.DialectSQLite:   `"key"`,
+	} {
+		if err := SetDialect(name); err != nil {
+			t.Fatalf("set dialect: %v", err)
+		}
+
+		statements := strings.Join(render(t, upCreateIdempotencyKeysTable), "\n")
+		if !strings.Contains(statements, quoted+" VARCHAR") {
+			t.Errorf("expected the key column to be quoted in %s: %s", name, statements)
+		}
+	}
+}
+
+func TestWebhookDeliveriesForeignKey(t *testing.T) {
+	defer func() { _ = SetDialect(storage.DialectPostgres) }()
+
+	for _, name := range []string{storage.DialectPostgres, storage.DialectMySQL, storage.DialectSQLite} {
+		if err := SetDialect(name); err != nil {
+			t.Fatalf("set dialect: %v", err)
+		}
+
+		statements := strings.Join(render(t, upCreateWebhooksTables), "\n")
+		if !strings.Contains(statements, "FOREIGN KEY (webhook_id) REFERENCES webhooks (id)") {
+			t.Errorf("expected the foreign key of the deliveries in %s: %s", name, statements)
+		}
+	}
+}
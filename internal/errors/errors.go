@@ -7,4 +7,33 @@ const (
 	Unauthorized    = "unauthorized"
 	BadRequesterror = "bad_request_error"
 	ServerError     = "server_error"
+	Conflict        = "conflict"
+	NotFound        = "not_found"
+	Forbidden       = "forbidden"
+	Unprocessable   = "unprocessable_entity"
+	RateLimited     = "rate_limited"
+	Unavailable     = "service_unavailable"
+	NotImplemented  = "not_implemented"
+
+	InvalidLastEventID     = "invalid_last_event_id"
+	ImageEventsNotStreamed = "image_events_not_streamed"
+
+	ImageNotFound = "image_not_found"
+
+	InvalidAdminToken = "invalid_admin_token"
+
+	FeatureDisabled = "feature_disabled"
+
+	InvalidIdempotencyKey    = "invalid_idempotency_key"
+	IdempotencyKeyReused     = "idempotency_key_reused"
+	IdempotencyKeyInProgress = "idempotency_key_in_progress"
+
+	OutboxTransactionRequired = "outbox_transaction_required"
+	OutboxPayloadInvalid      = "outbox_payload_invalid"
+
+	WebhookNotFound        = "webhook_not_found"
+	WebhookSecretError     = "webhook_secret_error"
+	WebhookDeliveryInvalid = "webhook_delivery_invalid"
+
+	WebhookDestinationForbidden = "webhook_destination_forbidden"
 )
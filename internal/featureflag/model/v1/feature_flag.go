@@ -0,0 +1,112 @@
+package model
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+const (
+	EntityFeatureFlag = "feature_flags"
+
+	userSeparator = ","
+
+	// percentageBuckets is the number of buckets the users are spread in by the percentage flags
+	percentageBuckets = 100
+)
+
+// FeatureFlag represents the feature_flags table
+type FeatureFlag struct {
+	sql.Model         // Unique record ID
+	Name       string `gorm:"type:varchar(255);not null" json:"name"` // Name the flag is checked with
+	Enabled    bool   `gorm:"not null" json:"enabled"`                // The flag is off for everyone when false
+	Percentage *int   `json:"percentage"`                             // Share of the users the flag is on for, null for the boolean flags
+	Users      string `gorm:"type:text;not null" json:"users"`        // Comma separated users the flag is always on for, the only ones of a boolean flag
+}
+
+func NewFeatureFlag() *FeatureFlag {
+	return &FeatureFlag{}
+}
+
+// GetName retrieves the name of the flag
+func (f *FeatureFlag) GetName() string {
+	return f.Name
+}
+
+// IsEnabled returns false when the flag is off for everyone
+func (f *FeatureFlag) IsEnabled() bool {
+	return f.Enabled
+}
+
+// GetPercentage retrieves the share of the users the flag is on for, nil for the boolean flags
+func (f *FeatureFlag) GetPercentage() *int {
+	return f.Percentage
+}
+
+// GetUsers retrieves the users the flag is always on for
+func (f *FeatureFlag) GetUsers() []string {
+	if f.Users == "" {
+		return nil
+	}
+	return strings.Split(f.Users, userSeparator)
+}
+
+// SetUsers sets the users the flag is always on for
+func (f *FeatureFlag) SetUsers(users []string) {
+	f.Users = strings.Join(users, userSeparator)
+}
+
+// IsEnabledFor checks if the flag is on for the user. An enabled flag is on
+// for its users, then a boolean flag is on for everyone when it lists no users,
+// and a percentage flag is on for the percentage of the other users, which
+// keep their bucket across requests.
+func (f *FeatureFlag) IsEnabledFor(userID string) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	users := f.GetUsers()
+	if userID != "" {
+		for _, user := range users {
+			if user == userID {
+				return true
+			}
+		}
+	}
+
+	if f.Percentage == nil {
+		// a boolean flag listing users targets only them
+		return len(users) == 0
+	}
+	if userID == "" {
+		return false
+	}
+
+	return f.bucket(userID) < *f.Percentage
+}
+
+// bucket places the user in one of the buckets of the flag, the users
+// are spread independently for every flag
+func (f *FeatureFlag) bucket(userID string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(f.Name + ":" + userID))
+
+	return int(hash.Sum32() % percentageBuckets)
+}
+
+// TableName returns the table name of the entity
+func (f *FeatureFlag) TableName() string {
+	return EntityFeatureFlag
+}
+
+// EntityName returns the entity name
+func (f *FeatureFlag) EntityName() string {
+	return EntityFeatureFlag
+}
+
+// SetDefaults sets the default values of the entity
+func (f *FeatureFlag) SetDefaults() errors.IError {
+	return nil
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func percentage(p int) *int {
+	return &p
+}
+
+func TestIsEnabledFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		flag   FeatureFlag
+		userID string
+		want   bool
+	}{
+		{name: "disabled", flag: FeatureFlag{Name: "f", Percentage: percentage(100), Users: "user_1"}, userID: "user_1"},
+		{name: "boolean flag", flag: FeatureFlag{Name: "f", Enabled: true}, userID: "user_1", want: true},
+		{name: "boolean flag without user", flag: FeatureFlag{Name: "f", Enabled: true}, want: true},
+		{name: "boolean flag targeting the user", flag: FeatureFlag{Name: "f", Enabled: true, Users: "user_1,user_2"}, userID: "user_2", want: true},
+		{name: "boolean flag targeting other users", flag: FeatureFlag{Name: "f", Enabled: true, Users: "user_1,user_2"}, userID: "user_3"},
+		{name: "boolean flag targeting users without user", flag: FeatureFlag{Name: "f", Enabled: true, Users: "user_1"}},
+		{name: "percentage flag targeting the user", flag: FeatureFlag{Name: "f", Enabled: true, Percentage: percentage(0), Users: "user_1"}, userID: "user_1", want: true},
+		{name: "zero percentage", flag: FeatureFlag{Name: "f", Enabled: true, Percentage: percentage(0)}, userID: "user_1"},
+		{name: "full percentage", flag: FeatureFlag{Name: "f", Enabled: true, Percentage: percentage(100)}, userID: "user_1", want: true},
+		{name: "percentage without user", flag: FeatureFlag{Name: "f", Enabled: true, Percentage: percentage(100)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.IsEnabledFor(tt.userID); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBucketStability(t *testing.T) {
+	flag := FeatureFlag{Name: "image-events", Enabled: true, Percentage: percentage(30)}
+	other := FeatureFlag{Name: "other-feature", Enabled: true, Percentage: percentage(30)}
+
+	enabled, differ := 0, 0
+	for i := 0; i < 1000; i++ {
+		userID := fmt.Sprintf("user_%d", i)
+
+		bucket := flag.bucket(userID)
+		if bucket < 0 || bucket >= percentageBuckets {
+			t.Fatalf("expected a bucket between 0 and %d, got %d", percentageBuckets-1, bucket)
+		}
+		if flag.bucket(userID) != bucket || flag.IsEnabledFor(userID) != flag.IsEnabledFor(userID) {
+			t.Fatalf("expected %s to keep the same bucket", userID)
+		}
+		if other.bucket(userID) != bucket {
+			differ++
+		}
+		if flag.IsEnabledFor(userID) {
+			enabled++
+		}
+	}
+
+	// the users are spread close to the percentage, independently for every flag
+	if enabled < 250 || enabled > 350 {
+		t.Errorf("expected about 30%% of the users enabled, got %d of 1000", enabled)
+	}
+	if differ < 900 {
+		t.Errorf("expected the buckets to differ between the flags, %d of 1000 differ", differ)
+	}
+}
+
+func TestBucketMonotonic(t *testing.T) {
+	// raising the percentage keeps the users already enabled
+	for i := 0; i < 200; i++ {
+		userID := fmt.Sprintf("user_%d", i)
+		previous := false
+		for p := 0; p <= 100; p += 10 {
+			flag := FeatureFlag{Name: "image-events", Enabled: true, Percentage: percentage(p)}
+			enabled := flag.IsEnabledFor(userID)
+			if previous && !enabled {
+				t.Fatalf("expected %s to stay enabled at %d%%", userID, p)
+			}
+			previous = enabled
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danushk97/image-analyzer/internal/featureflag/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Repo is used to interact feature flags with the storage held in memory
+type Repo struct {
+	dataStore *memory.Store
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(store *memory.Store) *Repo {
+	return &Repo{
+		dataStore: store,
+	}
+}
+
+// ListFeatureFlags fetches all the feature flags
+func (r Repo) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, errors.IError) {
+	var flags []*model.FeatureFlag
+	r.dataStore.Each(ctx, model.NewFeatureFlag(), func(record sql.IModel) {
+		flags = append(flags, record.(*model.FeatureFlag))
+	})
+
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+
+	return flags, nil
+}
@@ -0,0 +1,15 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/danushk97/image-analyzer/internal/featureflag/model/v1"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+// Repo is the interface that is used to talk to storage layer
+// for feature flags
+type Repo interface {
+	ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, errors.IError)
+}
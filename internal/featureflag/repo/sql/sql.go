@@ -0,0 +1,44 @@
+package sql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/danushk97/image-analyzer/internal/featureflag/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Repo is used to interact feature flags with the storage
+type Repo struct {
+	dataStore *sql.Repo
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(db *sql.Repo) *Repo {
+	return &Repo{
+		dataStore: db,
+	}
+}
+
+// InstanceWithContext returns underlying instance of gorm db
+// with the context attached to it
+func (r Repo) InstanceWithContext(ctx context.Context) *gorm.DB {
+	return r.dataStore.DBInstance(ctx).
+		WithContext(context.WithoutCancel(ctx))
+}
+
+// ListFeatureFlags fetches all the feature flags
+func (r Repo) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, errors.IError) {
+	var flags []*model.FeatureFlag
+	q := r.InstanceWithContext(ctx).
+		Order("name").
+		Find(&flags)
+
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return flags, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+
+	featureFlagMemory "github.com/danushk97/image-analyzer/internal/featureflag/repo/memory"
+	featureFlagSql "github.com/danushk97/image-analyzer/internal/featureflag/repo/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Option is an option to feature flag Service to set
+// the dependencies and configurations
+type Option func(*Service)
+
+// WithStorage adds the storage the feature flags are loaded from
+func WithStorage(
+	store storage.Store,
+) Option {
+	return func(opts *Service) {
+		switch s := store.(type) {
+		case *sql.Repo:
+			opts.Repo = featureFlagSql.NewRepo(s)
+		case *memory.Store:
+			opts.Repo = featureFlagMemory.NewRepo(s)
+		default:
+			panic(fmt.Sprintf("unsupported store: %T", store))
+		}
+	}
+}
+
+// WithConfig sets the configurations of the Service
+func WithConfig(config Config) Option {
+	return func(opts *Service) {
+		opts.config = config
+	}
+}
+
+// NewOptions will create a new builder Service object and
+// apply all the options to that object and returns pointer
+// to the builder Service
+func NewOptions(opts ...Option) *Service {
+	s := &Service{}
+	// Loop through each option
+	for _, op := range opts {
+		op(s)
+	}
+
+	if s.config.RefreshInterval <= 0 {
+		s.config.RefreshInterval = DefaultRefreshInterval
+	}
+	s.SetFlags(s.config.Flags)
+
+	return s
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/danushk97/image-analyzer/internal/featureflag/model/v1"
+	"github.com/danushk97/image-analyzer/internal/featureflag/repo"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+const (
+	// DefaultRefreshInterval is the default time in seconds between loads of the stored flags
+	DefaultRefreshInterval = 30
+)
+
+// Config holds the feature flags configurations
+type Config struct {
+	// Flags defined in the config, the flags stored in the database override them
+	Flags []FlagConfig
+	// RefreshInterval is the time in seconds between loads of the stored flags
+	RefreshInterval int
+}
+
+// FlagConfig defines a feature flag
+type FlagConfig struct {
+	// Name the flag is checked with
+	Name string
+	// Enabled turns the flag on, it is off for everyone when false
+	Enabled bool
+	// Percentage of the users the flag is on for, between 0 and 100,
+	// the flag is a boolean flag on for everyone when not set
+	Percentage *int
+	// Users the flag is always on for, a boolean flag
+	// listing users is on only for them
+	Users []string
+}
+
+// Validate checks the flags
+func (c Config) Validate() error {
+	return validation.ValidateStruct(
+		&c,
+		validation.Field(&c.Flags),
+		validation.Field(&c.RefreshInterval, validation.Min(0)),
+	)
+}
+
+// Validate checks the name and the percentage of the flag
+func (f FlagConfig) Validate() error {
+	return validation.ValidateStruct(
+		&f,
+		validation.Field(&f.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&f.Percentage, validation.Min(0), validation.Max(100)),
+	)
+}
+
+// Service evaluates the feature flags of the config and the database,
+// the stored flags are loaded periodically and override the configured ones
+type Service struct {
+	Repo   repo.Repo
+	config Config
+
+	mu          sync.RWMutex
+	configFlags map[string]*model.FeatureFlag
+	storedFlags map[string]*model.FeatureFlag
+}
+
+// NewService returns the instance of Service with all options applied
+func NewService(opts ...Option) *Service {
+	svc := NewOptions(opts...)
+	return svc
+}
+
+// IsEnabled checks if the flag is on for the user of the context,
+// the unknown flags are off
+func (s *Service) IsEnabled(ctx context.Context, name string) bool {
+	s.mu.RLock()
+	flag, ok := s.storedFlags[name]
+	if !ok {
+		flag, ok = s.configFlags[name]
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return false
+	}
+
+	return flag.IsEnabledFor(contextkey.GetFromFromCtx(ctx, contextkey.UserID))
+}
+
+// SetFlags replaces the flags of the config, e.g. when it is reloaded
+func (s *Service) SetFlags(flags []FlagConfig) {
+	configFlags := make(map[string]*model.FeatureFlag, len(flags))
+	for _, flagConfig := range flags {
+		flag := model.NewFeatureFlag()
+		flag.Name = flagConfig.Name
+		flag.Enabled = flagConfig.Enabled
+		flag.Percentage = flagConfig.Percentage
+		flag.SetUsers(flagConfig.Users)
+
+		configFlags[flag.GetName()] = flag
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.configFlags = configFlags
+}
+
+// Refresh loads the flags stored in the database, the previous
+// ones are kept when they cannot be loaded
+func (s *Service) Refresh(ctx context.Context) (err errors.IError) {
+	if s.Repo == nil {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "FeatureFlagService.Refresh")
+	defer func() { tracing.End(span, err) }()
+
+	flags, err := s.Repo.ListFeatureFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	storedFlags := make(map[string]*model.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		storedFlags[flag.GetName()] = flag
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.storedFlags = storedFlags
+
+	return nil
+}
+
+// RunRefresher loads the stored flags periodically until the context is done
+func (s *Service) RunRefresher(ctx context.Context) {
+	if s.Repo == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(s.config.RefreshInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				pkgLogger.Ctx(ctx).WithError(err).Error("FEATURE_FLAGS_REFRESH_ERROR")
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/internal/featureflag/model/v1"
+	"github.com/danushk97/image-analyzer/internal/featureflag/repo"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+// fakeRepo serves the stored flags it holds
+type fakeRepo struct {
+	repo.Repo
+	flags []*model.FeatureFlag
+	err   errors.IError
+}
+
+func (r *fakeRepo) ListFeatureFlags(_ context.Context) ([]*model.FeatureFlag, errors.IError) {
+	return r.flags, r.err
+}
+
+func percentage(p int) *int {
+	return &p
+}
+
+func TestFlagConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    FlagConfig
+		wantErr bool
+	}{
+		{name: "boolean flag", flag: FlagConfig{Name: "f", Enabled: true}},
+		{name: "targeted boolean flag", flag: FlagConfig{Name: "f", Enabled: true, Users: []string{"user_1"}}},
+		{name: "lowest percentage", flag: FlagConfig{Name: "f", Percentage: percentage(0)}},
+		{name: "highest percentage", flag: FlagConfig{Name: "f", Percentage: percentage(100)}},
+		{name: "negative percentage", flag: FlagConfig{Name: "f", Percentage: percentage(-1)}, wantErr: true},
+		{name: "percentage above 100", flag: FlagConfig{Name: "f", Percentage: percentage(101)}, wantErr: true},
+		{name: "missing name", flag: FlagConfig{Enabled: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (Config{Flags: []FlagConfig{tt.flag}}).Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("expected an error to be %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestIsEnabled(t *testing.T) {
+	r := &fakeRepo{}
+	svc := NewService(WithConfig(Config{Flags: []FlagConfig{
+		{Name: "image-events", Enabled: true, Users: []string{"user_1"}},
+		{Name: "stored", Enabled: true},
+	}}))
+	svc.Repo = r
+
+	user1 := contextkey.SetInContext(context.Background(), contextkey.UserID, "user_1")
+	user2 := contextkey.SetInContext(context.Background(), contextkey.UserID, "user_2")
+
+	if !svc.IsEnabled(user1, "image-events") || svc.IsEnabled(user2, "image-events") {
+		t.Error("expected the flag of the config to be on only for the targeted user")
+	}
+	if svc.IsEnabled(user1, "unknown") {
+		t.Error("expected the unknown flags to be off")
+	}
+
+	// the stored flags override the configured ones
+	r.flags = []*model.FeatureFlag{{Name: "stored", Enabled: false}}
+	if err := svc.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if svc.IsEnabled(user1, "stored") {
+		t.Error("expected the stored flag to override the configured one")
+	}
+
+	// the stored flags are kept when they cannot be loaded
+	r.flags, r.err = nil, errors.NewServerError("db_error")
+	if err := svc.Refresh(context.Background()); err == nil {
+		t.Fatal("expected the refresh to fail")
+	}
+	if svc.IsEnabled(user1, "stored") {
+		t.Error("expected the previous stored flags to be kept")
+	}
+}
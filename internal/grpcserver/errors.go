@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+const serverErrorMessage = "Something went wrong, please try again in some time."
+
+var statusCodes = map[errors.ErrorType]codes.Code{
+	errors.BAD_REQUEST_ERROR:     codes.InvalidArgument,
+	errors.UNPROCESSABLE_ERROR:   codes.InvalidArgument,
+	errors.AUTHORIZATION_ERROR:   codes.Unauthenticated,
+	errors.FORBIDDEN_ERROR:       codes.PermissionDenied,
+	errors.NOT_FOUND_ERROR:       codes.NotFound,
+	errors.CONFLICT_ERROR:        codes.AlreadyExists,
+	errors.RATE_LIMITED_ERROR:    codes.ResourceExhausted,
+	errors.UNAVAILABLE_ERROR:     codes.Unavailable,
+	errors.NOT_IMPLEMENTED_ERROR: codes.Unimplemented,
+	errors.INTERNAL_SERVER_ERROR: codes.Internal,
+}
+
+// ToStatus converts the error to a gRPC status with the same semantics
+// as the problem details of the HTTP server. Details of server errors
+// are never exposed to the caller.
+func ToStatus(err error) *status.Status {
+	iErr, ok := err.(errors.IError)
+	if !ok {
+		// statuses returned by grpc itself are kept as is
+		if st, isStatus := status.FromError(err); isStatus {
+			return st
+		}
+		return status.New(codes.Internal, serverErrorMessage)
+	}
+
+	code, ok := statusCodes[iErr.GetType()]
+	if !ok || code == codes.Internal {
+		return status.New(codes.Internal, serverErrorMessage)
+	}
+
+	// retryable conflicts are reported as aborted so that the client retries
+	if iErr.IsOfType(errors.CONFLICT_ERROR) && iErr.IsRetryable() {
+		code = codes.Aborted
+	}
+
+	st := status.New(code, iErr.Error())
+
+	if len(iErr.Details()) == 0 {
+		return st
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, detail := range iErr.Details() {
+		badRequest.FieldViolations = append(badRequest.FieldViolations,
+			&errdetails.BadRequest_FieldViolation{
+				Field:       detail.Field,
+				Description: detail.Message,
+			})
+	}
+
+	if withDetails, detailErr := st.WithDetails(badRequest); detailErr == nil {
+		return withDetails
+	}
+
+	return st
+}
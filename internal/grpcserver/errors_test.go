@@ -0,0 +1,63 @@
+package grpcserver
+
+import (
+	goerr "errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{name: "bad request", err: errors.NewBadRequestError("bad_request"), code: codes.InvalidArgument, message: "bad_request"},
+		{name: "unprocessable", err: errors.NewUnprocessableError("invalid"), code: codes.InvalidArgument, message: "invalid"},
+		{name: "authorization", err: errors.NewAuthorizationError("unauthorized"), code: codes.Unauthenticated, message: "unauthorized"},
+		{name: "forbidden", err: errors.NewForbiddenError("forbidden"), code: codes.PermissionDenied, message: "forbidden"},
+		{name: "not found", err: errors.NewNotFoundError("image_not_found"), code: codes.NotFound, message: "image_not_found"},
+		{name: "conflict", err: errors.NewConflictError("conflict"), code: codes.AlreadyExists, message: "conflict"},
+		{name: "retryable conflict", err: errors.NewConflictError("conflict").AsRetryable(), code: codes.Aborted, message: "conflict"},
+		{name: "rate limited", err: errors.NewRateLimitedError("rate_limited"), code: codes.ResourceExhausted, message: "rate_limited"},
+		{name: "unavailable", err: errors.NewUnavailableError("unavailable"), code: codes.Unavailable, message: "unavailable"},
+		{name: "not implemented", err: errors.NewNotImplementedError("not_implemented"), code: codes.Unimplemented, message: "not_implemented"},
+		{name: "server error", err: errors.NewServerError("db_error"), code: codes.Internal, message: serverErrorMessage},
+		{name: "plain error", err: goerr.New("connection reset"), code: codes.Internal, message: serverErrorMessage},
+		{name: "grpc status", err: status.Error(codes.DeadlineExceeded, "deadline"), code: codes.DeadlineExceeded, message: "deadline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := ToStatus(tt.err)
+			if st.Code() != tt.code || st.Message() != tt.message {
+				t.Errorf("expected %v %q, got %v %q", tt.code, tt.message, st.Code(), st.Message())
+			}
+		})
+	}
+}
+
+func TestToStatusFieldViolations(t *testing.T) {
+	err := errors.NewUnprocessableError("validation_failure").WithDetails(
+		errors.FieldError{Field: "file_name", Message: "cannot be blank"},
+	)
+
+	st := ToStatus(err)
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected 1 detail, got %v", st.Details())
+	}
+
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(badRequest.FieldViolations) != 1 {
+		t.Fatalf("expected the field violations, got %v", st.Details()[0])
+	}
+	if v := badRequest.FieldViolations[0]; v.Field != "file_name" || v.Description != "cannot be blank" {
+		t.Errorf("unexpected field violation %v", v)
+	}
+}
@@ -0,0 +1,101 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
+)
+
+// CtxInterceptor sets the request ID and the method in the context,
+// it is the gRPC equivalent of middlewares.CtxMiddleware
+func CtxInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		requestID := metadataValue(ctx, constants.HeaderRequestId)
+		if !requestid.IsValid(requestID) {
+			// Generate a new ID if none or an invalid one is found
+			requestID = requestid.New()
+		}
+
+		ctx = contextkey.SetInContext(ctx, contextkey.RequestID, requestID)
+		ctx = contextkey.SetInContext(ctx, contextkey.RequestPath, info.FullMethod)
+
+		// echo the request ID back to the caller
+		_ = grpc.SetHeader(ctx, metadata.Pairs(constants.HeaderRequestId, requestID))
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthInterceptor sets the caller in the context from the x-user-id
+// metadata, it is the gRPC equivalent of middlewares.AuthMiddleware
+func AuthInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		logger := pkgLogger.Ctx(ctx)
+
+		logger.Info("AUTH_VALIDATION")
+
+		userID := metadataValue(ctx, constants.HeaderUserId)
+		if userID == "" {
+			logger.Warn("USER_ID_NOT_FOUND")
+			return nil, errors.NewAuthorizationError(internaErr.Unauthorized)
+		}
+
+		ctx = contextkey.SetInContext(ctx, contextkey.UserID, userID)
+
+		logger.Info("AUTH_VALIDATION_SUCCESS")
+
+		return handler(ctx, req)
+	}
+}
+
+// ErrorInterceptor converts the errors returned by the handlers
+// and the inner interceptors to gRPC statuses
+func ErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			pkgLogger.Ctx(ctx).WithError(err).Error(err.Error())
+			return nil, ToStatus(err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// metadataValue returns the first value of the incoming metadata key
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
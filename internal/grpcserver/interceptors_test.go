@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/image_metadata.v1.ImageMetadataService/GetImageMetadata"}
+
+// contextHandler returns the context the handler was called with
+func contextHandler(ctx context.Context, _ interface{}) (interface{}, error) {
+	return ctx, nil
+}
+
+func TestCtxInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		md        metadata.MD
+		requestID string
+	}{
+		{name: "request id of the caller", md: metadata.Pairs(constants.HeaderRequestId, "request_1"), requestID: "request_1"},
+		{name: "generated request id", md: metadata.MD{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			resp, err := CtxInterceptor()(ctx, nil, testInfo, contextHandler)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			handlerCtx := resp.(context.Context)
+			requestID := contextkey.GetFromFromCtx(handlerCtx, contextkey.RequestID)
+			if requestID == "" || (tt.requestID != "" && requestID != tt.requestID) {
+				t.Errorf("expected the request id %q, got %q", tt.requestID, requestID)
+			}
+			if path := contextkey.GetFromFromCtx(handlerCtx, contextkey.RequestPath); path != testInfo.FullMethod {
+				t.Errorf("expected the method in the context, got %q", path)
+			}
+		})
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(constants.HeaderUserId, "user_1"))
+
+	resp, err := AuthInterceptor()(ctx, nil, testInfo, contextHandler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userID := contextkey.GetFromFromCtx(resp.(context.Context), contextkey.UserID); userID != "user_1" {
+		t.Errorf("expected the caller in the context, got %q", userID)
+	}
+
+	called := false
+	_, err = AuthInterceptor()(context.Background(), nil, testInfo,
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+	if called {
+		t.Error("expected the handler not to be called without a caller")
+	}
+	if iErr, ok := err.(errors.IError); !ok || !iErr.IsOfType(errors.AUTHORIZATION_ERROR) {
+		t.Errorf("expected an authorization error, got %v", err)
+	}
+}
+
+func TestErrorInterceptor(t *testing.T) {
+	_, err := ErrorInterceptor()(context.Background(), nil, testInfo,
+		func(context.Context, interface{}) (interface{}, error) {
+			return nil, errors.NewNotFoundError("image_not_found")
+		})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound || st.Message() != "image_not_found" {
+		t.Errorf("expected a not found status, got %v", err)
+	}
+
+	resp, err := ErrorInterceptor()(context.Background(), nil, testInfo,
+		func(context.Context, interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+	if err != nil || resp != "ok" {
+		t.Errorf("expected the response as is, got %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,124 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/danushk97/image-analyzer/internal/image_metadata"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	image_metadatav1 "github.com/danushk97/image-analyzer/rpc/image_metadata/v1"
+)
+
+const (
+	// DefaultGRPCAddress for gRPC server
+	DefaultGRPCAddress = "0.0.0.0:9091"
+	// DefaultShutdownTimeout is the default time in seconds allowed to shutdown server
+	DefaultShutdownTimeout = 20
+)
+
+// Config holds the gRPC server configurations
+type Config struct {
+	ShutdownTimeout int
+	Address         string
+}
+
+type ServerOption func(s *Server) error
+
+// Server wraps the grpc.Server
+type Server struct {
+	server *grpc.Server
+	config *Config
+}
+
+// New creates a new gRPC server with the interceptors
+// equivalent to the middlewares of the HTTP server
+func New(ctx context.Context, config *Config) *Server {
+	logger := pkgLogger.Ctx(ctx)
+
+	if config.Address == "" {
+		config.Address = DefaultGRPCAddress
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			CtxInterceptor(),
+			ErrorInterceptor(),
+			AuthInterceptor(),
+		),
+	)
+
+	logger.Info(
+		fmt.Sprintf(
+			"registered server address grpc_server: %v",
+			config.Address,
+		),
+	)
+
+	return &Server{
+		server: server,
+		config: config,
+	}
+}
+
+// Run starts the gRPC server
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Address)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			pkgLogger.Ctx(ctx).WithError(err).Error("GRPC_SERVER_ERROR")
+		}
+	}()
+
+	<-ctx.Done()
+	return s.Shutdown(ctx)
+}
+
+// Shutdown gracefully shuts down the server, the in-flight
+// calls are cancelled once the shutdown timeout elapses
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(time.Duration(s.config.ShutdownTimeout) * time.Second)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+	case <-timer.C:
+		s.server.Stop()
+	}
+
+	return nil
+}
+
+func (s *Server) WithOptions(opts ...ServerOption) error {
+	for _, opt := range opts {
+		err := opt(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) WithImageMetadataServer(is *image_metadata.ImageMetadataRPCServer) ServerOption {
+	return func(s *Server) error {
+		image_metadatav1.RegisterImageMetadataServiceServer(s.server, is)
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+const (
+	EntityIdempotencyKey = "idempotency_keys"
+)
+
+// IdempotencyKey represents the idempotency keys table
+type IdempotencyKey struct {
+	sql.Model             // Unique record ID
+	UserID         string `gorm:"type:uuid;not null" json:"user_id"`             // User ID (Caller)
+	Key            string `gorm:"type:varchar(255);not null" json:"key"`         // Value of the Idempotency-Key header
+	RequestHash    string `gorm:"type:varchar(64);not null" json:"request_hash"` // Fingerprint of the original request
+	ResponseStatus int    `gorm:"not null" json:"response_status"`               // HTTP status of the stored response, 0 while in progress
+	ResponseType   string `gorm:"type:varchar(255)" json:"response_type"`        // Content type of the stored response
+	ResponseBody   string `gorm:"type:text" json:"response_body"`                // Body of the stored response
+	ExpiresAt      int64  `gorm:"not null" json:"expires_at"`                    // Unix time after which the key can be reused
+}
+
+func NewIdempotencyKey() *IdempotencyKey {
+	return &IdempotencyKey{}
+}
+
+// GetUserID retrieves the User ID
+func (i *IdempotencyKey) GetUserID() string {
+	return i.UserID
+}
+
+// GetKey retrieves the idempotency key
+func (i *IdempotencyKey) GetKey() string {
+	return i.Key
+}
+
+// GetRequestHash retrieves the request fingerprint
+func (i *IdempotencyKey) GetRequestHash() string {
+	return i.RequestHash
+}
+
+// GetResponseStatus retrieves the stored response status
+func (i *IdempotencyKey) GetResponseStatus() int {
+	return i.ResponseStatus
+}
+
+// GetResponseType retrieves the stored response content type
+func (i *IdempotencyKey) GetResponseType() string {
+	return i.ResponseType
+}
+
+// GetResponseBody retrieves the stored response body
+func (i *IdempotencyKey) GetResponseBody() string {
+	return i.ResponseBody
+}
+
+// GetExpiresAt retrieves the expiry time of the key
+func (i *IdempotencyKey) GetExpiresAt() int64 {
+	return i.ExpiresAt
+}
+
+// IsCompleted returns true once a response has been stored for the key
+func (i *IdempotencyKey) IsCompleted() bool {
+	return i.ResponseStatus != 0
+}
+
+// IsExpired returns true if the key is no longer valid at the given time
+func (i *IdempotencyKey) IsExpired(now int64) bool {
+	return i.ExpiresAt <= now
+}
+
+// TableName returns the table name of the entity
+func (i *IdempotencyKey) TableName() string {
+	return EntityIdempotencyKey
+}
+
+// EntityName returns the entity name
+func (i *IdempotencyKey) EntityName() string {
+	return EntityIdempotencyKey
+}
+
+// SetDefaults sets the default values of the entity
+func (i *IdempotencyKey) SetDefaults() errors.IError {
+	return nil
+}
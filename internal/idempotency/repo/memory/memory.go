@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/danushk97/image-analyzer/internal/idempotency/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Repo is used to interact idempotency keys with the storage held in memory
+type Repo struct {
+	dataStore *memory.Store
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(store *memory.Store) *Repo {
+	return &Repo{
+		dataStore: store,
+	}
+}
+
+// FindIdempotencyKey fetches the key stored for the given user
+func (r Repo) FindIdempotencyKey(
+	ctx context.Context,
+	userID string,
+	key string,
+) (*model.IdempotencyKey, errors.IError) {
+	var found *model.IdempotencyKey
+	r.dataStore.Each(ctx, model.NewIdempotencyKey(), func(record sql.IModel) {
+		if stored := record.(*model.IdempotencyKey); stored.UserID == userID && stored.Key == key {
+			found = stored
+		}
+	})
+
+	if found == nil {
+		return nil, memory.RecordNotFoundError()
+	}
+
+	return found, nil
+}
+
+// CreateIdempotencyKey stores a new idempotency key,
+// the keys are unique per user
+func (r Repo) CreateIdempotencyKey(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+) errors.IError {
+	err := r.dataStore.Transaction(ctx, func(ctx context.Context) errors.IError {
+		_, err := r.FindIdempotencyKey(ctx, record.UserID, record.Key)
+		if err == nil {
+			return memory.UniqueViolationError("key")
+		}
+		if !sql.IsRecordNotFoundError(err) {
+			return err
+		}
+
+		return r.dataStore.Create(ctx, record)
+	})
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"IDEMPOTENCY_KEY_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateIdempotencyKey stores the response captured for the key
+func (r Repo) UpdateIdempotencyKey(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+) errors.IError {
+	err := r.dataStore.Update(
+		ctx,
+		record,
+		"response_status",
+		"response_type",
+		"response_body",
+	)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"IDEMPOTENCY_KEY_UPDATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey removes the given key
+func (r Repo) DeleteIdempotencyKey(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+) errors.IError {
+	return r.dataStore.Delete(ctx, record)
+}
+
+// DeleteExpiredIdempotencyKeys removes all the keys expired at the given time
+// and returns the number of keys removed
+func (r Repo) DeleteExpiredIdempotencyKeys(
+	ctx context.Context,
+	now int64,
+) (int64, errors.IError) {
+	return r.dataStore.DeleteWhere(ctx, model.NewIdempotencyKey(), func(record sql.IModel) bool {
+		return record.(*model.IdempotencyKey).ExpiresAt <= now
+	})
+}
@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/danushk97/image-analyzer/internal/idempotency/model/v1"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+// Repo is the interface that is used to talk to storage layer
+// for idempotency keys
+type Repo interface {
+	FindIdempotencyKey(
+		ctx context.Context, userID string, key string) (*model.IdempotencyKey, errors.IError)
+	CreateIdempotencyKey(context.Context, *model.IdempotencyKey) errors.IError
+	UpdateIdempotencyKey(context.Context, *model.IdempotencyKey) errors.IError
+	DeleteIdempotencyKey(context.Context, *model.IdempotencyKey) errors.IError
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now int64) (int64, errors.IError)
+}
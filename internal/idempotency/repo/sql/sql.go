@@ -0,0 +1,116 @@
+package sql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/danushk97/image-analyzer/internal/idempotency/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Repo is used to interact idempotency keys with the storage
+type Repo struct {
+	dataStore *sql.Repo
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(db *sql.Repo) *Repo {
+	return &Repo{
+		dataStore: db,
+	}
+}
+
+// InstanceWithContext returns underlying instance of gorm db
+// with the context attached to it
+func (r Repo) InstanceWithContext(ctx context.Context) *gorm.DB {
+	return r.dataStore.DBInstance(ctx).
+		WithContext(context.WithoutCancel(ctx))
+}
+
+// FindIdempotencyKey fetches the key stored for the given user
+func (r Repo) FindIdempotencyKey(
+	ctx context.Context,
+	userID string,
+	key string,
+) (*model.IdempotencyKey, errors.IError) {
+	record := model.NewIdempotencyKey()
+	q := r.InstanceWithContext(ctx).
+		Where("user_id = ?", userID).
+		// key is a reserved word of mysql, the column is quoted by the dialect
+		Where(clause.Eq{Column: clause.Column{Name: "key"}, Value: key}).
+		First(record)
+
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// CreateIdempotencyKey stores a new idempotency key
+func (r Repo) CreateIdempotencyKey(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+) errors.IError {
+	err := r.dataStore.Create(ctx, record)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"IDEMPOTENCY_KEY_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateIdempotencyKey stores the response captured for the key
+func (r Repo) UpdateIdempotencyKey(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+) errors.IError {
+	err := r.dataStore.Update(
+		ctx,
+		record,
+		"response_status",
+		"response_type",
+		"response_body",
+		"expires_at",
+	)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"IDEMPOTENCY_KEY_UPDATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey removes the given key
+func (r Repo) DeleteIdempotencyKey(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+) errors.IError {
+	return r.dataStore.Delete(ctx, record)
+}
+
+// DeleteExpiredIdempotencyKeys removes all the keys expired at the given time
+// and returns the number of keys removed
+func (r Repo) DeleteExpiredIdempotencyKeys(
+	ctx context.Context,
+	now int64,
+) (int64, errors.IError) {
+	q := r.InstanceWithContext(ctx).
+		Where("expires_at <= ?", now).
+		Delete(model.NewIdempotencyKey())
+
+	if err := sql.GetDBError(q); err != nil {
+		return 0, err
+	}
+
+	return q.RowsAffected, nil
+}
@@ -0,0 +1,52 @@
+package sql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql/sqltest"
+)
+
+func TestFindIdempotencyKey(t *testing.T) {
+	// key is a reserved word of mysql
+	queries := map[string]string{
+		sql.DialectPostgres: `SELECT * FROM "idempotency_keys" WHERE user_id = $1 AND "key" = $2`,
+		sql.DialectMySQL:    "SELECT * FROM `idempotency_keys` WHERE user_id = ? AND `key` = ?",
+	}
+
+	for _, dialect := range sqltest.Dialects {
+		t.Run(dialect, func(t *testing.T) {
+			store, mock := sqltest.NewRepo(t, dialect)
+			mock.ExpectQuery(regexp.QuoteMeta(queries[dialect])).
+				WithArgs("user-1", "key-1", 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "key"}).
+					AddRow("idempotency-1", "user-1", "key-1"))
+
+			record, err := NewRepo(store).FindIdempotencyKey(context.Background(), "user-1", "key-1")
+			if err != nil {
+				t.Fatalf("find idempotency key: %v", err)
+			}
+			if record.GetID() != "idempotency-1" || record.Key != "key-1" {
+				t.Errorf("unexpected record: %+v", record)
+			}
+		})
+	}
+}
+
+func TestFindIdempotencyKeyNotFound(t *testing.T) {
+	for _, dialect := range sqltest.Dialects {
+		t.Run(dialect, func(t *testing.T) {
+			store, mock := sqltest.NewRepo(t, dialect)
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			_, err := NewRepo(store).FindIdempotencyKey(context.Background(), "user-1", "key-1")
+			if !sql.IsRecordNotFoundError(err) {
+				t.Errorf("expected the record not to be found, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+
+	idempotencyMemory "github.com/danushk97/image-analyzer/internal/idempotency/repo/memory"
+	idempotencySql "github.com/danushk97/image-analyzer/internal/idempotency/repo/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Option is an option to idempotency Service to set
+// the dependencies and configurations
+type Option func(*Service)
+
+// WithStorage adds the storage being used for idempotency keys
+func WithStorage(
+	store storage.Store,
+) Option {
+	return func(opts *Service) {
+		switch s := store.(type) {
+		case *sql.Repo:
+			opts.Repo = idempotencySql.NewRepo(s)
+		case *memory.Store:
+			opts.Repo = idempotencyMemory.NewRepo(s)
+		default:
+			panic(fmt.Sprintf("unsupported store: %T", store))
+		}
+	}
+}
+
+// WithConfig sets the configurations of the Service
+func WithConfig(config Config) Option {
+	return func(opts *Service) {
+		opts.config = config
+	}
+}
+
+// NewOptions will create a new builder Service object and
+// apply all the options to that object and returns pointer
+// to the builder Service
+func NewOptions(opts ...Option) *Service {
+	s := &Service{}
+	// Loop through each option
+	for _, op := range opts {
+		op(s)
+	}
+
+	if s.config.KeyTTL <= 0 {
+		s.config.KeyTTL = DefaultKeyTTL
+	}
+	if s.config.InProgressLease <= 0 {
+		s.config.InProgressLease = DefaultInProgressLease
+	}
+	if s.config.PurgeInterval <= 0 {
+		s.config.PurgeInterval = DefaultPurgeInterval
+	}
+
+	return s
+}
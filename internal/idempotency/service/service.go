@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/idempotency/model/v1"
+	"github.com/danushk97/image-analyzer/internal/idempotency/repo"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+const (
+	// DefaultKeyTTL is the default time in seconds a key is retained
+	DefaultKeyTTL = 24 * 60 * 60
+	// DefaultInProgressLease is the default time in seconds a key is held
+	// by a request which has not completed
+	DefaultInProgressLease = 60
+	// DefaultPurgeInterval is the default time in seconds between purges
+	DefaultPurgeInterval = 10 * 60
+)
+
+// Config holds the idempotency configurations
+type Config struct {
+	// KeyTTL is the time in seconds after which a key expires
+	KeyTTL int
+	// InProgressLease is the time in seconds a key is held by a request
+	// which has not completed, e.g. when the process crashed, after which
+	// the key can be reused by a retry
+	InProgressLease int
+	// PurgeInterval is the time in seconds between removals of expired keys
+	PurgeInterval int
+}
+
+// Service manages the idempotency keys of mutating requests
+type Service struct {
+	Repo   repo.Repo
+	config Config
+}
+
+// NewService returns the instance of Service with all options applied
+func NewService(opts ...Option) *Service {
+	svc := NewOptions(opts...)
+	return svc
+}
+
+// Begin reserves the key for the request identified by the fingerprint.
+// If the key was already used, the stored record is returned so that
+// its response can be replayed. A key reused with a different request
+// or a key whose original request is still running is a conflict.
+func (s *Service) Begin(
+	ctx context.Context,
+	userID string,
+	key string,
+	fingerprint string,
+) (_ *model.IdempotencyKey, _ bool, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Begin")
+	defer func() { tracing.End(span, err) }()
+
+	// the replicas may lag behind the keys reserved by the concurrent requests
+	ctx = sql.WithPrimary(ctx)
+
+	now := time.Now().Unix()
+
+	record, err := s.Repo.FindIdempotencyKey(ctx, userID, key)
+	if err != nil && !sql.IsRecordNotFoundError(err) {
+		return nil, false, err
+	}
+
+	if record != nil && record.IsExpired(now) {
+		if err = s.Repo.DeleteIdempotencyKey(ctx, record); err != nil {
+			return nil, false, err
+		}
+		record = nil
+	}
+
+	if record == nil {
+		record = &model.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: fingerprint,
+			ExpiresAt:   now + int64(s.config.InProgressLease),
+		}
+
+		err = s.Repo.CreateIdempotencyKey(ctx, record)
+		if err == nil {
+			return record, false, nil
+		}
+
+		// a concurrent request may have reserved the key in the meantime
+		existing, findErr := s.Repo.FindIdempotencyKey(ctx, userID, key)
+		if findErr != nil {
+			return nil, false, err
+		}
+		record = existing
+	}
+
+	if record.GetRequestHash() != fingerprint {
+		return nil, false, errors.NewConflictError(
+			internalErr.IdempotencyKeyReused)
+	}
+
+	if !record.IsCompleted() {
+		return nil, false, errors.NewConflictError(
+			internalErr.IdempotencyKeyInProgress)
+	}
+
+	return record, true, nil
+}
+
+// Complete stores the response of the request against the key,
+// which is then retained for the key TTL
+func (s *Service) Complete(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+	status int,
+	contentType string,
+	body string,
+) (err errors.IError) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Complete")
+	defer func() { tracing.End(span, err) }()
+
+	record.ResponseStatus = status
+	record.ResponseType = contentType
+	record.ResponseBody = body
+	record.ExpiresAt = time.Now().Unix() + int64(s.config.KeyTTL)
+
+	return s.Repo.UpdateIdempotencyKey(ctx, record)
+}
+
+// Release removes the key so that the request can be retried
+func (s *Service) Release(
+	ctx context.Context,
+	record *model.IdempotencyKey,
+) (err errors.IError) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Release")
+	defer func() { tracing.End(span, err) }()
+
+	return s.Repo.DeleteIdempotencyKey(ctx, record)
+}
+
+// PurgeExpired removes all the expired keys
+func (s *Service) PurgeExpired(ctx context.Context) (err errors.IError) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.PurgeExpired")
+	defer func() { tracing.End(span, err) }()
+
+	count, err := s.Repo.DeleteExpiredIdempotencyKeys(ctx, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	pkgLogger.Ctx(ctx).WithField("count", count).Info("IDEMPOTENCY_KEYS_PURGED")
+
+	return nil
+}
+
+// RunPurger removes the expired keys periodically until the context is done
+func (s *Service) RunPurger(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.config.PurgeInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.PurgeExpired(ctx); err != nil {
+				pkgLogger.Ctx(ctx).WithError(err).Error("IDEMPOTENCY_KEYS_PURGE_ERROR")
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package dtos
 
 import (
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
 	"github.com/danushk97/image-analyzer/pkg/errors"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
@@ -22,7 +23,7 @@ func (c *CreateImageMetadataRequest) Validate() errors.IError {
 	)
 
 	if err != nil {
-		return errors.NewBadRequestError(err.Error())
+		return errors.NewValidationError(internalErr.ValidationFailure, err)
 	}
 
 	return nil
@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	// DefaultListCount is the number of images listed when count is not given
+	DefaultListCount = 20
+	// MaxListCount is the maximum number of images listed at once
+	MaxListCount = 100
+)
+
+// ListImageMetadataRequest defines the filters and the page of the images to be listed
+type ListImageMetadataRequest struct {
+	Count    int    `form:"count" json:"count"`         // Number of images to return
+	Skip     int    `form:"skip" json:"skip"`           // Number of images to skip
+	Status   string `form:"status" json:"status"`       // Only the images in the status
+	FileType string `form:"file_type" json:"file_type"` // Only the images of the file type
+}
+
+func (l *ListImageMetadataRequest) Validate() errors.IError {
+	err := validation.ValidateStruct(
+		l,
+		validation.Field(
+			&l.Count,
+			validation.Min(1),
+			validation.Max(MaxListCount),
+		),
+		validation.Field(
+			&l.Skip,
+			validation.Min(0),
+		),
+		validation.Field(
+			&l.Status,
+			validation.Length(1, 50),
+		),
+		validation.Field(
+			&l.FileType,
+			validation.Length(1, 50),
+		),
+	)
+
+	if err != nil {
+		return errors.NewValidationError(internalErr.ValidationFailure, err)
+	}
+
+	return nil
+}
+
+// GetCount returns the number of images to list, the default if not given
+func (l *ListImageMetadataRequest) GetCount() int {
+	if l.Count <= 0 {
+		return DefaultListCount
+	}
+	return l.Count
+}
@@ -32,3 +32,13 @@ func ImageMetadataResponseFromModel(image *model.ImageMetadata) *ImageMetadataRe
 
 	return r
 }
+
+// ImageMetadataResponsesFromModels converts the images to their responses
+func ImageMetadataResponsesFromModels(images []*model.ImageMetadata) []*ImageMetadataResponse {
+	responses := make([]*ImageMetadataResponse, 0, len(images))
+	for _, image := range images {
+		responses = append(responses, ImageMetadataResponseFromModel(image))
+	}
+
+	return responses
+}
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/internal/outbox/repo"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+const (
+	// Channel is the postgres channel notified of every new outbox event
+	Channel = "outbox_events"
+
+	// ReplayLimit is the maximum number of events replayed on a resume
+	ReplayLimit = 500
+
+	// ReorderWindow is the number of sequences the replays go back by,
+	// the sequences are assigned when the events are recorded so an event
+	// committed late is behind the last one sent
+	ReorderWindow = 64
+
+	// subscriptionBuffer is the number of events buffered per subscriber
+	subscriptionBuffer = 64
+)
+
+// notification is the payload sent on the channel
+type notification struct {
+	Sequence      int64  `json:"sequence"`
+	AggregateType string `json:"aggregate_type"`
+	UserID        string `json:"user_id"`
+}
+
+// Subscription receives the image events of a user. The channel is
+// closed if the subscriber does not keep up, it is expected to
+// resume from the last received event.
+type Subscription struct {
+	C      <-chan *model.OutboxEvent
+	ch     chan *model.OutboxEvent
+	userID string
+}
+
+// Broker fans out the image events recorded in the outbox to the
+// subscribers of their owner. The events are learnt of through postgres
+// notifications so that the subscribers of every server instance get them.
+type Broker struct {
+	Repo     repo.Repo
+	listener *sql.Listener
+
+	mu           sync.Mutex
+	subscribers  map[string]map[*Subscription]struct{}
+	lastSequence atomic.Int64
+}
+
+// NewBroker returns the instance of Broker with all options applied
+func NewBroker(opts ...Option) *Broker {
+	b := NewOptions(opts...)
+	return b
+}
+
+// Run listens to the new events until the context is done
+func (b *Broker) Run(ctx context.Context) {
+	if b.listener == nil {
+		return
+	}
+
+	b.listener.Listen(ctx, b.catchUp, b.notify)
+}
+
+// IsStreaming checks if the new events are pushed to the subscribers,
+// they are learnt of only through the postgres notifications
+func (b *Broker) IsStreaming() bool {
+	return b.listener != nil
+}
+
+// Subscribe registers a new subscriber to the events of the user
+func (b *Broker) Subscribe(userID string) *Subscription {
+	ch := make(chan *model.OutboxEvent, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, userID: userID}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = map[*Subscription]struct{}{}
+	}
+	b.subscribers[userID][sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe removes the subscriber and closes its channel
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+// Replay returns the events of the user recorded after the sequence. It goes
+// back by the reorder window to include the events committed after the one
+// of the sequence, the subscribers drop the ones they already received.
+func (b *Broker) Replay(
+	ctx context.Context,
+	userID string,
+	sequence int64,
+) ([]*model.OutboxEvent, errors.IError) {
+	return b.Repo.ListOutboxEventsAfter(
+		ctx, constants.AggregateImage, userID, max(sequence-ReorderWindow, 0), ReplayLimit)
+}
+
+// notify publishes the event of the notification to its subscribers
+func (b *Broker) notify(ctx context.Context, payload string) {
+	n := &notification{}
+	if err := json.Unmarshal([]byte(payload), n); err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Warn("IMAGE_EVENT_NOTIFICATION_INVALID")
+		return
+	}
+
+	b.advance(n.Sequence)
+
+	if n.AggregateType != constants.AggregateImage || !b.hasSubscribers(n.UserID) {
+		return
+	}
+
+	// the event was just recorded, the replicas may not have it yet
+	event, err := b.Repo.FindOutboxEventBySequence(sql.WithPrimary(ctx), n.Sequence)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error("IMAGE_EVENT_FETCH_ERROR")
+		return
+	}
+
+	b.publish(n.UserID, event)
+}
+
+// catchUp publishes the events recorded while the listener was disconnected,
+// the subscribers drop the ones they already received
+func (b *Broker) catchUp(ctx context.Context) {
+	sequence := b.lastSequence.Load()
+	if sequence == 0 {
+		return
+	}
+	sequence = max(sequence-ReorderWindow, 0)
+
+	events, err := b.Repo.ListOutboxEventsAfter(
+		ctx, constants.AggregateImage, "", sequence, ReplayLimit)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error("IMAGE_EVENT_CATCH_UP_ERROR")
+		return
+	}
+
+	for _, event := range events {
+		b.advance(event.GetSequence())
+
+		owner := &notification{}
+		if err := json.Unmarshal([]byte(event.GetPayload()), owner); err != nil {
+			continue
+		}
+		b.publish(owner.UserID, event)
+	}
+}
+
+// publish sends the event to the subscribers of the user,
+// the subscribers which are not keeping up are dropped
+func (b *Broker) publish(userID string, event *model.OutboxEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[userID] {
+		select {
+		case sub.ch <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+func (b *Broker) hasSubscribers(userID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers[userID]) > 0
+}
+
+// remove must be called with the lock held
+func (b *Broker) remove(sub *Subscription) {
+	subs, ok := b.subscribers[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.ch)
+
+	if len(subs) == 0 {
+		delete(b.subscribers, sub.userID)
+	}
+}
+
+// advance records the last sequence seen by the broker
+func (b *Broker) advance(sequence int64) {
+	for {
+		last := b.lastSequence.Load()
+		if sequence <= last || b.lastSequence.CompareAndSwap(last, sequence) {
+			return
+		}
+	}
+}
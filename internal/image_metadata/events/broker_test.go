@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/internal/outbox/repo"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+// fakeRepo records the sequence the events are listed after, the
+// other methods of the repo are not expected to be called
+type fakeRepo struct {
+	repo.Repo
+	after []int64
+}
+
+func (r *fakeRepo) ListOutboxEventsAfter(
+	_ context.Context, _ string, _ string, sequence int64, _ int) ([]*model.OutboxEvent, errors.IError) {
+	r.after = append(r.after, sequence)
+	return nil, nil
+}
+
+func TestReplayGoesBackByTheReorderWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence int64
+		after    int64
+	}{
+		{name: "behind the last event", sequence: 100, after: 100 - ReorderWindow},
+		{name: "first events", sequence: ReorderWindow - 1, after: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeRepo{}
+			b := NewBroker()
+			b.Repo = r
+
+			if _, err := b.Replay(context.Background(), "user_1", tt.sequence); err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			if len(r.after) != 1 || r.after[0] != tt.after {
+				t.Errorf("expected the events after %d, got %v", tt.after, r.after)
+			}
+		})
+	}
+}
+
+func TestCatchUpGoesBackByTheReorderWindow(t *testing.T) {
+	r := &fakeRepo{}
+	b := NewBroker()
+	b.Repo = r
+
+	// nothing was notified yet
+	b.catchUp(context.Background())
+	if len(r.after) != 0 {
+		t.Fatalf("expected no catch up, got %v", r.after)
+	}
+
+	b.advance(100)
+	b.catchUp(context.Background())
+	if len(r.after) != 1 || r.after[0] != 100-ReorderWindow {
+		t.Errorf("expected the events after %d, got %v", 100-ReorderWindow, r.after)
+	}
+}
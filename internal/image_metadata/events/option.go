@@ -0,0 +1,54 @@
+package events
+
+import (
+	"fmt"
+
+	outboxMemory "github.com/danushk97/image-analyzer/internal/outbox/repo/memory"
+	outboxSql "github.com/danushk97/image-analyzer/internal/outbox/repo/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Option is an option to Broker to set
+// the dependencies and configurations
+type Option func(*Broker)
+
+// WithStorage adds the storage the events are read from
+func WithStorage(
+	store storage.Store,
+) Option {
+	return func(opts *Broker) {
+		switch s := store.(type) {
+		case *sql.Repo:
+			opts.Repo = outboxSql.NewRepo(s)
+		case *memory.Store:
+			opts.Repo = outboxMemory.NewRepo(s)
+		default:
+			panic(fmt.Sprintf("unsupported store: %T", store))
+		}
+	}
+}
+
+// WithListener adds the listener notified of the new events,
+// without it the broker only replays the recorded events
+func WithListener(listener *sql.Listener) Option {
+	return func(opts *Broker) {
+		opts.listener = listener
+	}
+}
+
+// NewOptions will create a new builder Broker object and
+// apply all the options to that object and returns pointer
+// to the builder Broker
+func NewOptions(opts ...Option) *Broker {
+	b := &Broker{
+		subscribers: map[string]map[*Subscription]struct{}{},
+	}
+	// Loop through each option
+	for _, op := range opts {
+		op(b)
+	}
+
+	return b
+}
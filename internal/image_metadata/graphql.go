@@ -0,0 +1,202 @@
+package image_metadata
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/model/v1"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/service"
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// graphQLMaxDepth bounds the nesting of the queries
+const graphQLMaxDepth = 10
+
+//go:embed schema.graphql
+var graphQLSchema string
+
+// ImageMetadataGraphQLServer serves the images over GraphQL with the same
+// ownership rules and authentication as the REST API
+type ImageMetadataGraphQLServer struct {
+	schema *graphql.Schema
+}
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewGraphQLServer creates a new GraphQL server
+func NewGraphQLServer(
+	imageMetaService *service.Service,
+) *ImageMetadataGraphQLServer {
+	return &ImageMetadataGraphQLServer{
+		schema: graphql.MustParseSchema(
+			graphQLSchema,
+			&queryResolver{service: imageMetaService},
+			graphql.MaxDepth(graphQLMaxDepth),
+		),
+	}
+}
+
+func (gs *ImageMetadataGraphQLServer) SetupRoutes(r *gin.Engine) {
+	graphQLApi := r.Group("/v1/graphql")
+	graphQLApi.Use(middlewares.AuthMiddleware())
+
+	graphQLApi.POST("", gs.Query)
+}
+
+func (gs *ImageMetadataGraphQLServer) Query(gc *gin.Context) {
+	logger := pkgLogger.Ctx(gc.Request.Context())
+
+	request := &graphQLRequest{}
+	if bindErr := gc.ShouldBindJSON(request); bindErr != nil {
+		err := errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(bindErr)
+		logger.WithError(err).Error("INVALID_REQUEST")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	response := gs.schema.Exec(
+		gc.Request.Context(),
+		request.Query,
+		request.OperationName,
+		request.Variables,
+	)
+
+	gc.JSON(http.StatusOK, response)
+}
+
+// queryResolver resolves the fields of the Query type
+type queryResolver struct {
+	service *service.Service
+}
+
+type imageArgs struct {
+	ID graphql.ID
+}
+
+func (q *queryResolver) Image(ctx context.Context, args imageArgs) (*imageResolver, error) {
+	image, err := q.service.GetImageMetadata(ctx, string(args.ID))
+	if err != nil {
+		return nil, middlewares.NewGraphQLError(ctx, err)
+	}
+
+	return &imageResolver{image: image}, nil
+}
+
+type imagesArgs struct {
+	Filter *struct {
+		Status   *string
+		FileType *string
+	}
+	First int32
+	Skip  int32
+}
+
+func (q *queryResolver) Images(ctx context.Context, args imagesArgs) ([]*imageResolver, error) {
+	request := &dtos.ListImageMetadataRequest{
+		Count: int(args.First),
+		Skip:  int(args.Skip),
+	}
+	if args.Filter != nil {
+		if args.Filter.Status != nil {
+			request.Status = *args.Filter.Status
+		}
+		if args.Filter.FileType != nil {
+			request.FileType = *args.Filter.FileType
+		}
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, middlewares.NewGraphQLError(ctx, err)
+	}
+
+	images, err := q.service.ListImageMetadata(ctx, request)
+	if err != nil {
+		return nil, middlewares.NewGraphQLError(ctx, err)
+	}
+
+	resolvers := make([]*imageResolver, 0, len(images))
+	for _, image := range images {
+		resolvers = append(resolvers, &imageResolver{image: image})
+	}
+
+	return resolvers, nil
+}
+
+// imageResolver resolves the fields of the Image type
+type imageResolver struct {
+	image *model.ImageMetadata
+}
+
+func (r *imageResolver) ID() graphql.ID {
+	return graphql.ID(r.image.GetPublicID())
+}
+
+func (r *imageResolver) UserID() graphql.ID {
+	return graphql.ID(r.image.GetUserID())
+}
+
+func (r *imageResolver) Filename() string {
+	return r.image.GetFilename()
+}
+
+func (r *imageResolver) FileType() string {
+	return r.image.GetFileType()
+}
+
+func (r *imageResolver) FileSize() float64 {
+	return float64(r.image.GetFileSize())
+}
+
+func (r *imageResolver) Width() int32 {
+	return int32(r.image.Width)
+}
+
+func (r *imageResolver) Height() int32 {
+	return int32(r.image.Height)
+}
+
+func (r *imageResolver) Status() string {
+	return r.image.GetStatus()
+}
+
+func (r *imageResolver) UploadURL() string {
+	return dtos.ImageMetadataResponseFromModel(r.image).UploadURL
+}
+
+func (r *imageResolver) DownloadURL() string {
+	return dtos.ImageMetadataResponseFromModel(r.image).DownloadURL
+}
+
+func (r *imageResolver) Analysis() *analysisResolver {
+	if r.image.GetAnalysisResult() == "" {
+		return nil
+	}
+
+	return &analysisResolver{image: r.image}
+}
+
+// analysisResolver resolves the fields of the Analysis type
+type analysisResolver struct {
+	image *model.ImageMetadata
+}
+
+func (r *analysisResolver) Status() string {
+	return r.image.GetStatus()
+}
+
+func (r *analysisResolver) Result() string {
+	return r.image.GetAnalysisResult()
+}
@@ -0,0 +1,123 @@
+package image_metadata
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/service"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+)
+
+// graphQLResponse is the body of a GraphQL response
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// queryGraphQL runs the query against a server of the service as the user
+func queryGraphQL(t *testing.T, svc *service.Service, userID string, query string) (*httptest.ResponseRecorder, graphQLResponse) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewGraphQLServer(svc).SetupRoutes(r)
+
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/v1/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set(constants.HeaderUserId, userID)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var resp graphQLResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	return rec, resp
+}
+
+func TestGraphQLRequiresUser(t *testing.T) {
+	// the service is not reached without a user
+	rec, _ := queryGraphQL(t, service.NewService(), "", `{ images { id } }`)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a user, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestGraphQLImageNotFound(t *testing.T) {
+	// malformed IDs never reach the repo
+	rec, resp := queryGraphQL(t, service.NewService(), "user_1", `{ image(id: "image_1") { id } }`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	if string(resp.Data["image"]) != "null" {
+		t.Errorf("expected no image, got %s", resp.Data["image"])
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != internaErr.ImageNotFound ||
+		resp.Errors[0].Extensions["code"] != internaErr.NotFound {
+		t.Errorf("expected a not found error, got %+v", resp.Errors)
+	}
+}
+
+func TestGraphQLScopesTheImagesToTheUser(t *testing.T) {
+	svc := service.NewService(service.WithStorage(memory.NewStore()))
+
+	ids := map[string][]string{}
+	for _, userID := range []string{"user_1", "user_1", "user_2"} {
+		ctx := contextkey.SetInContext(context.Background(), contextkey.UserID, userID)
+		image, err := svc.CreateImageMetadata(ctx, &dtos.CreateImageMetadataRequest{FileName: "cat.png"})
+		if err != nil {
+			t.Fatalf("create image: %v", err)
+		}
+		ids[userID] = append(ids[userID], image.GetPublicID())
+	}
+
+	rec, resp := queryGraphQL(t, svc, "user_1", `{ images { id userId } }`)
+	if rec.Code != http.StatusOK || len(resp.Errors) != 0 {
+		t.Fatalf("expected the images, got %d %s", rec.Code, rec.Body)
+	}
+	var images []struct {
+		ID     string `json:"id"`
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(resp.Data["images"], &images); err != nil {
+		t.Fatalf("decode images: %v", err)
+	}
+	if len(images) != 2 {
+		t.Fatalf("expected the 2 images of the user, got %+v", images)
+	}
+	for _, image := range images {
+		if image.UserID != "user_1" {
+			t.Errorf("expected only the images of the user, got the one of %s", image.UserID)
+		}
+	}
+
+	_, resp = queryGraphQL(t, svc, "user_1", `{ image(id: "`+ids["user_1"][0]+`") { id } }`)
+	if len(resp.Errors) != 0 || !strings.Contains(string(resp.Data["image"]), ids["user_1"][0]) {
+		t.Errorf("expected the image of the user, got %s %+v", resp.Data["image"], resp.Errors)
+	}
+
+	// the image of another user is not found
+	_, resp = queryGraphQL(t, svc, "user_1", `{ image(id: "`+ids["user_2"][0]+`") { id } }`)
+	if string(resp.Data["image"]) != "null" || len(resp.Errors) != 1 ||
+		resp.Errors[0].Extensions["code"] != internaErr.NotFound {
+		t.Errorf("expected the image of another user not to be found, got %s %+v", resp.Data["image"], resp.Errors)
+	}
+}
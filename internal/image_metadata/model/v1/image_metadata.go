@@ -99,3 +99,8 @@ func GetOImageMetdataIdWithPrefix(ID string) string {
 	}
 	return fmt.Sprintf("%s%s", ImageMetadaIDPrefix, ID)
 }
+
+// GetImageMetadataIDWithoutPrefix removes the image_ prefix if exists
+func GetImageMetadataIDWithoutPrefix(ID string) string {
+	return strings.TrimPrefix(ID, ImageMetadaIDPrefix)
+}
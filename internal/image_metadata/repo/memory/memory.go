@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danushk97/image-analyzer/internal/image_metadata/model/v1"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/repo"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// Repo is used to interact images with the storage held in memory
+type Repo struct {
+	dataStore *memory.Store
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(store *memory.Store) *Repo {
+	return &Repo{
+		dataStore: store,
+	}
+}
+
+// Transaction performs given function inside the transaction block using
+// storage transaction method
+func (r Repo) Transaction(ctx context.Context,
+	fn func(ctx context.Context) errors.IError, opts ...transaction.Option) errors.IError {
+	return r.dataStore.Transaction(ctx, fn, opts...)
+}
+
+// IsActive checks if transaction is active or not
+func (r Repo) IsActive(ctx context.Context) bool {
+	return r.dataStore.IsTransactionActive(ctx)
+}
+
+// CreateImageMetadata stores a new image
+func (r Repo) CreateImageMetadata(
+	ctx context.Context,
+	image *model.ImageMetadata,
+) errors.IError {
+	return r.dataStore.Create(ctx, image)
+}
+
+// FindImageMetadataByID fetches the image with the given ID
+func (r Repo) FindImageMetadataByID(
+	ctx context.Context,
+	id string,
+) (*model.ImageMetadata, errors.IError) {
+	image := model.NewImageMetadata()
+	if err := r.dataStore.FindByID(ctx, image, id); err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// ListImageMetadata fetches a page of the images matching the filters,
+// the latest images come first
+func (r Repo) ListImageMetadata(
+	ctx context.Context,
+	opts repo.ListOptions,
+) ([]*model.ImageMetadata, errors.IError) {
+	var images []*model.ImageMetadata
+	r.dataStore.Each(ctx, model.NewImageMetadata(), func(record sql.IModel) {
+		image := record.(*model.ImageMetadata)
+		if (opts.UserID == "" || image.UserID == opts.UserID) &&
+			(opts.Status == "" || image.Status == opts.Status) &&
+			(opts.FileType == "" || image.FileType == opts.FileType) {
+			images = append(images, image)
+		}
+	})
+
+	// the records come in the order of their IDs
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].CreatedAt > images[j].CreatedAt
+	})
+
+	return page(images, opts.Limit, opts.Offset), nil
+}
+
+// DeleteImageMetadata removes the image
+func (r Repo) DeleteImageMetadata(
+	ctx context.Context,
+	image *model.ImageMetadata,
+) errors.IError {
+	return r.dataStore.Delete(ctx, image)
+}
+
+// page returns the images of the page, a limit of 0 or less is no limit
+func page(images []*model.ImageMetadata, limit int, offset int) []*model.ImageMetadata {
+	if offset >= len(images) {
+		return nil
+	}
+	images = images[max(offset, 0):]
+
+	if limit > 0 && limit < len(images) {
+		images = images[:limit]
+	}
+
+	return images
+}
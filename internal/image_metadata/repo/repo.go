@@ -6,14 +6,26 @@ import (
 	"github.com/danushk97/image-analyzer/internal/image_metadata/model/v1"
 
 	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
 )
 
+// ListOptions are the filters and the page of the images to be listed,
+// the empty filters match all the images
+type ListOptions struct {
+	UserID   string
+	Status   string
+	FileType string
+	Limit    int
+	Offset   int
+}
+
 // Transactional is the interface for all task related to executing tasks
 // within transactional block
 type Transactional interface {
 	// Transaction is used to execute given function inside transaction block
+	// nested calls run inside a savepoint of the active transaction
 	Transaction(ctx context.Context, fn func(ctx context.Context) errors.
-		IError) errors.IError
+		IError, opts ...transaction.Option) errors.IError
 	// IsActive checks if transaction is active or not
 	IsActive(ctx context.Context) bool
 }
@@ -27,4 +39,7 @@ type Repo interface {
 	Transactional
 
 	CreateImageMetadata(context.Context, *model.ImageMetadata) errors.IError
+	FindImageMetadataByID(ctx context.Context, id string) (*model.ImageMetadata, errors.IError)
+	ListImageMetadata(ctx context.Context, opts ListOptions) ([]*model.ImageMetadata, errors.IError)
+	DeleteImageMetadata(context.Context, *model.ImageMetadata) errors.IError
 }
@@ -7,9 +7,11 @@ import (
 
 	internalErr "github.com/danushk97/image-analyzer/internal/errors"
 	"github.com/danushk97/image-analyzer/internal/image_metadata/model/v1"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/repo"
 	"github.com/danushk97/image-analyzer/pkg/errors"
 	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
 	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
 )
 
 // Repo is used to interact offers with the storage
@@ -33,13 +35,13 @@ func (r Repo) InstanceWithContext(ctx context.Context) *gorm.DB {
 }
 
 // Transaction performs given function inside the transaction block using
-// spine transaction method
+// storage transaction method
 func (r Repo) Transaction(ctx context.Context,
-	fn func(ctx context.Context) errors.IError) errors.IError {
+	fn func(ctx context.Context) errors.IError, opts ...transaction.Option) errors.IError {
 	return r.dataStore.Transaction(ctx, func(ctx context.Context) errors.
 		IError {
 		return fn(ctx)
-	})
+	}, sql.TxOptions(opts...)...)
 }
 
 // IsActive checks if transaction is active or not
@@ -60,6 +62,10 @@ func (r Repo) CreateImageMetadata(
 		logger.WithError(err).Error(
 			"IMAGE_METADATA_CREATE_ERROR",
 		)
+		// domain errors such as constraint violations are returned as is
+		if !err.IsOfType(errors.INTERNAL_SERVER_ERROR) {
+			return err
+		}
 		return errors.NewServerError(
 			internalErr.ServerErrorDBCreateError).
 			Wrap(err)
@@ -67,3 +73,54 @@ func (r Repo) CreateImageMetadata(
 
 	return nil
 }
+
+// FindImageMetadataByID fetches the image with the given ID
+func (r Repo) FindImageMetadataByID(
+	ctx context.Context,
+	id string,
+) (*model.ImageMetadata, errors.IError) {
+	image := model.NewImageMetadata()
+	if err := r.dataStore.FindByID(ctx, image, id); err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// ListImageMetadata fetches a page of the images matching the filters,
+// the latest images come first
+func (r Repo) ListImageMetadata(
+	ctx context.Context,
+	opts repo.ListOptions,
+) ([]*model.ImageMetadata, errors.IError) {
+	var images []*model.ImageMetadata
+	q := r.InstanceWithContext(ctx)
+	if opts.UserID != "" {
+		q = q.Where("user_id = ?", opts.UserID)
+	}
+	if opts.Status != "" {
+		q = q.Where("status = ?", opts.Status)
+	}
+	if opts.FileType != "" {
+		q = q.Where("file_type = ?", opts.FileType)
+	}
+
+	q = q.Order("created_at DESC, id").
+		Limit(opts.Limit).
+		Offset(opts.Offset).
+		Find(&images)
+
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// DeleteImageMetadata removes the image
+func (r Repo) DeleteImageMetadata(
+	ctx context.Context,
+	image *model.ImageMetadata,
+) errors.IError {
+	return r.dataStore.Delete(ctx, image)
+}
@@ -0,0 +1,213 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	"github.com/danushk97/image-analyzer/internal/database/migrations"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/model/v1"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/repo"
+	outboxModel "github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	outboxSql "github.com/danushk97/image-analyzer/internal/outbox/repo/sql"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql/sqltest"
+)
+
+const (
+	testUserID  = "8f0c5ab4-3d4e-4a4f-9b4c-7f6f2f6a9e01"
+	otherUserID = "0b0e9b1c-7c55-4c1e-a4a8-2f3b5f1d6a77"
+)
+
+// newStore returns the storage of an empty database of the dialect
+// migrated to the latest version
+func newStore(t *testing.T, dialect string) *sql.Repo {
+	t.Helper()
+	ctx := context.Background()
+
+	store := sqltest.NewServerRepo(t, dialect)
+
+	conn, err := store.Db.GetInstance(ctx).DB()
+	if err != nil {
+		t.Fatalf("get the connection: %v", err)
+	}
+	if err = migrations.Reset(ctx, conn, dialect); err != nil {
+		t.Fatalf("reset the database: %v", err)
+	}
+	if err = migrations.Up(ctx, conn, dialect); err != nil {
+		t.Fatalf("migrate the database: %v", err)
+	}
+
+	return store
+}
+
+func newImage(userID string, filename string, fileType string) *model.ImageMetadata {
+	return &model.ImageMetadata{
+		UserID:   userID,
+		Filename: filename,
+		FileType: fileType,
+		Status:   constants.StatusInitiated,
+	}
+}
+
+func TestImageMetadata(t *testing.T) {
+	for _, dialect := range sqltest.RepoDialects {
+		t.Run(dialect, func(t *testing.T) {
+			ctx := context.Background()
+			r := NewRepo(newStore(t, dialect))
+
+			images := []*model.ImageMetadata{
+				newImage(testUserID, "cat.png", "PNG"),
+				newImage(testUserID, "dog.jpg", "JPEG"),
+				newImage(otherUserID, "bird.png", "PNG"),
+			}
+			for _, image := range images {
+				if err := r.CreateImageMetadata(ctx, image); err != nil {
+					t.Fatalf("create image: %v", err)
+				}
+				if image.GetID() == "" || image.GetCreatedAt() == 0 {
+					t.Errorf("expected the ID and the timestamps to be set, got %+v", image)
+				}
+			}
+
+			found, err := r.FindImageMetadataByID(ctx, images[0].GetID())
+			if err != nil {
+				t.Fatalf("find image: %v", err)
+			}
+			if found.GetFilename() != "cat.png" || found.GetUserID() != testUserID {
+				t.Errorf("unexpected image: %+v", found)
+			}
+
+			listed, err := r.ListImageMetadata(ctx, repo.ListOptions{UserID: testUserID, Limit: 10})
+			if err != nil {
+				t.Fatalf("list images: %v", err)
+			}
+			if len(listed) != 2 {
+				t.Errorf("expected the 2 images of the user, got %d", len(listed))
+			}
+
+			listed, err = r.ListImageMetadata(ctx, repo.ListOptions{UserID: testUserID, FileType: "PNG", Limit: 10})
+			if err != nil {
+				t.Fatalf("list images: %v", err)
+			}
+			if len(listed) != 1 || listed[0].GetID() != images[0].GetID() {
+				t.Errorf("expected the PNG image of the user, got %+v", listed)
+			}
+
+			listed, err = r.ListImageMetadata(ctx, repo.ListOptions{Limit: 1, Offset: 1})
+			if err != nil {
+				t.Fatalf("list images: %v", err)
+			}
+			if len(listed) != 1 {
+				t.Errorf("expected a page of 1 image, got %d", len(listed))
+			}
+
+			if err = r.DeleteImageMetadata(ctx, images[0]); err != nil {
+				t.Fatalf("delete image: %v", err)
+			}
+			if _, err = r.FindImageMetadataByID(ctx, images[0].GetID()); !sql.IsRecordNotFoundError(err) {
+				t.Errorf("expected the deleted image not to be found, got %v", err)
+			}
+		})
+	}
+}
+
+func TestImageMetadataTransaction(t *testing.T) {
+	for _, dialect := range sqltest.RepoDialects {
+		t.Run(dialect, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t, dialect)
+			r := NewRepo(store)
+			outbox := outboxSql.NewRepo(store)
+
+			// the image and its events are recorded together
+			for _, filename := range []string{"cat.png", "dog.png"} {
+				err := r.Transaction(ctx, func(ctx context.Context) errors.IError {
+					image := newImage(testUserID, filename, "PNG")
+					if err := r.CreateImageMetadata(ctx, image); err != nil {
+						return err
+					}
+
+					return outbox.CreateOutboxEvent(ctx, &outboxModel.OutboxEvent{
+						AggregateType: constants.AggregateImage,
+						AggregateID:   image.GetID(),
+						EventType:     constants.EventImageCreated,
+						Payload:       `{"id":"` + image.GetID() + `","user_id":"` + testUserID + `"}`,
+					})
+				})
+				if err != nil {
+					t.Fatalf("record image: %v", err)
+				}
+			}
+
+			// the sequences are assigned by the database in the order of the inserts
+			events, err := outbox.ListOutboxEventsAfter(ctx, constants.AggregateImage, testUserID, 0, 10)
+			if err != nil {
+				t.Fatalf("list events: %v", err)
+			}
+			if len(events) != 2 || events[0].GetSequence() == 0 || events[1].GetSequence() <= events[0].GetSequence() {
+				t.Fatalf("expected 2 events in sequence, got %+v", events)
+			}
+			event, err := outbox.FindOutboxEventBySequence(ctx, events[1].GetSequence())
+			if err != nil || event.GetID() != events[1].GetID() {
+				t.Errorf("expected the event of the sequence, got %+v %v", event, err)
+			}
+			if others, _ := outbox.ListOutboxEventsAfter(ctx, constants.AggregateImage, otherUserID, 0, 10); len(others) != 0 {
+				t.Errorf("expected no event of the other user, got %d", len(others))
+			}
+
+			// nothing is recorded when the transaction fails
+			err = r.Transaction(ctx, func(ctx context.Context) errors.IError {
+				if err := r.CreateImageMetadata(ctx, newImage(testUserID, "bird.png", "PNG")); err != nil {
+					return err
+				}
+				return errors.NewConflictError("rolled back")
+			})
+			if err == nil {
+				t.Fatal("expected the error of the transaction")
+			}
+			if images, _ := r.ListImageMetadata(ctx, repo.ListOptions{UserID: testUserID, Limit: 10}); len(images) != 2 {
+				t.Errorf("expected the image to be rolled back, got %d images", len(images))
+			}
+
+			// the relay claims the pending events
+			err = outbox.Transaction(ctx, func(ctx context.Context) errors.IError {
+				pending, locked, err := outbox.LockPendingOutboxEvents(ctx, 10)
+				if err != nil {
+					return err
+				}
+				if !locked || len(pending) != 2 {
+					t.Errorf("expected the 2 pending events, got %v %d", locked, len(pending))
+					return nil
+				}
+
+				pending[0].ClaimedUntil = time.Now().Unix() + 60
+				return outbox.UpdateOutboxEvent(ctx, pending[0])
+			})
+			if err != nil {
+				t.Fatalf("claim events: %v", err)
+			}
+			if event, _ := outbox.FindOutboxEventBySequence(ctx, events[0].GetSequence()); event == nil || event.ClaimedUntil == 0 {
+				t.Error("expected the claim to be stored")
+			}
+
+			// the dead lettered events are no longer pending
+			event.DeadLetteredAt = time.Now().Unix()
+			if err = outbox.UpdateOutboxEvent(ctx, event); err != nil {
+				t.Fatalf("dead letter event: %v", err)
+			}
+			err = outbox.Transaction(ctx, func(ctx context.Context) errors.IError {
+				pending, _, err := outbox.LockPendingOutboxEvents(ctx, 10)
+				if err == nil && (len(pending) != 1 || pending[0].GetID() != events[0].GetID()) {
+					t.Errorf("expected only the first event to be pending, got %+v", pending)
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatalf("lock events: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package image_metadata
+
+import (
+	"context"
+
+	"github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/service"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	image_metadatav1 "github.com/danushk97/image-analyzer/rpc/image_metadata/v1"
+)
+
+// ImageMetadataRPCServer implements the RPC API of the image metadata
+// on top of the same service as the HTTP server. It is served over gRPC
+// and Twirp, the errors returned are converted by each transport.
+type ImageMetadataRPCServer struct {
+	image_metadatav1.UnimplementedImageMetadataServiceServer
+
+	service *service.Service
+}
+
+// NewRPCServer creates a new RPC server
+func NewRPCServer(
+	imageMetaService *service.Service,
+) *ImageMetadataRPCServer {
+	return &ImageMetadataRPCServer{
+		service: imageMetaService,
+	}
+}
+
+func (is *ImageMetadataRPCServer) CreateImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.CreateImageMetadataRequest,
+) (*image_metadatav1.CreateImageMetadataResponse, error) {
+	logger := pkgLogger.Ctx(ctx)
+
+	requestBody := &dtos.CreateImageMetadataRequest{
+		FileName: req.GetFileName(),
+	}
+
+	if err := requestBody.Validate(); err != nil {
+		logger.WithError(err).Error("VALIDATION_FAILURE")
+		return nil, err
+	}
+
+	image, err := is.service.CreateImageMetadata(ctx, requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &image_metadatav1.CreateImageMetadataResponse{
+		Image: imageMetadataToProto(dtos.ImageMetadataResponseFromModel(image)),
+	}, nil
+}
+
+func (is *ImageMetadataRPCServer) GetImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.GetImageMetadataRequest,
+) (*image_metadatav1.GetImageMetadataResponse, error) {
+	image, err := is.service.GetImageMetadata(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &image_metadatav1.GetImageMetadataResponse{
+		Image: imageMetadataToProto(dtos.ImageMetadataResponseFromModel(image)),
+	}, nil
+}
+
+func (is *ImageMetadataRPCServer) ListImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.ListImageMetadataRequest,
+) (*image_metadatav1.ListImageMetadataResponse, error) {
+	logger := pkgLogger.Ctx(ctx)
+
+	request := &dtos.ListImageMetadataRequest{
+		Count:    int(req.GetCount()),
+		Skip:     int(req.GetSkip()),
+		Status:   req.GetStatus(),
+		FileType: req.GetFileType(),
+	}
+
+	if err := request.Validate(); err != nil {
+		logger.WithError(err).Error("VALIDATION_FAILURE")
+		return nil, err
+	}
+
+	images, err := is.service.ListImageMetadata(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &image_metadatav1.ListImageMetadataResponse{}
+	for _, image := range dtos.ImageMetadataResponsesFromModels(images) {
+		response.Items = append(response.Items, imageMetadataToProto(image))
+	}
+
+	return response, nil
+}
+
+func (is *ImageMetadataRPCServer) DeleteImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.DeleteImageMetadataRequest,
+) (*image_metadatav1.DeleteImageMetadataResponse, error) {
+	if err := is.service.DeleteImageMetadata(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &image_metadatav1.DeleteImageMetadataResponse{}, nil
+}
+
+// imageMetadataToProto converts the response to its protobuf message
+func imageMetadataToProto(r *dtos.ImageMetadataResponse) *image_metadatav1.ImageMetadata {
+	return &image_metadatav1.ImageMetadata{
+		Id:             r.ID,
+		UserId:         r.UserID,
+		Filename:       r.Filename,
+		FileType:       r.FileType,
+		FileSize:       r.FileSize,
+		Width:          int32(r.Width),
+		Height:         int32(r.Height),
+		Status:         r.Status,
+		AnalysisResult: r.AnalysisResult,
+		UploadUrl:      r.UploadURL,
+		DownloadUrl:    r.DownloadURL,
+	}
+}
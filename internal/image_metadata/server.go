@@ -5,8 +5,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/danushk97/image-analyzer/internal/constants"
 	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	featureFlagService "github.com/danushk97/image-analyzer/internal/featureflag/service"
+	idempotencyService "github.com/danushk97/image-analyzer/internal/idempotency/service"
 	"github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/events"
 	"github.com/danushk97/image-analyzer/internal/image_metadata/model/v1"
 	"github.com/danushk97/image-analyzer/internal/image_metadata/service"
 	"github.com/danushk97/image-analyzer/internal/middlewares"
@@ -16,23 +20,44 @@ import (
 )
 
 type ImageMetadataServer struct {
-	service *service.Service
+	service     *service.Service
+	idempotency *idempotencyService.Service
+	events      *events.Broker
+	flags       *featureFlagService.Service
+	validator   gin.HandlerFunc
 }
 
 // NewServer creates a new server
 func NewServer(
 	imageMetaService *service.Service,
+	idempotency *idempotencyService.Service,
+	eventBroker *events.Broker,
+	flags *featureFlagService.Service,
+	validator gin.HandlerFunc,
 ) *ImageMetadataServer {
 	return &ImageMetadataServer{
-		service: imageMetaService,
+		service:     imageMetaService,
+		idempotency: idempotency,
+		events:      eventBroker,
+		flags:       flags,
+		validator:   validator,
 	}
 }
 
 func (is *ImageMetadataServer) SetupRoutes(r *gin.Engine) {
 	imageApi := r.Group("/v1/images")
 	imageApi.Use(middlewares.AuthMiddleware())
+	// the requests are validated once authenticated
+	if is.validator != nil {
+		imageApi.Use(is.validator)
+	}
+	imageApi.Use(middlewares.IdempotencyMiddleware(is.idempotency))
 
 	imageApi.POST("", is.Create)
+	imageApi.GET("", is.List)
+	imageApi.GET("/events", middlewares.FeatureFlagMiddleware(is.flags, constants.FeatureImageEvents), is.Events)
+	imageApi.GET("/:id", is.Get)
+	imageApi.DELETE("/:id", is.Delete)
 }
 
 func (is *ImageMetadataServer) Create(gc *gin.Context) {
@@ -46,10 +71,10 @@ func (is *ImageMetadataServer) Create(gc *gin.Context) {
 	requestBody := &dtos.CreateImageMetadataRequest{}
 
 	// Bind JSON and assign to 'err'
-	if ierr := gc.BindJSON(&requestBody); err != nil {
-		err = errors.NewServerError(internaErr.ServerError)
+	if bindErr := gc.ShouldBindJSON(requestBody); bindErr != nil {
+		err = errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(bindErr)
 		logger.WithError(err).Error("INVALID_REQUEST")
-		middlewares.ErrorResponse(gc, ierr)
+		middlewares.ErrorResponse(gc, err)
 		return
 	}
 
@@ -76,6 +101,73 @@ func (is *ImageMetadataServer) Create(gc *gin.Context) {
 	gc.JSON(http.StatusOK, response)
 }
 
+func (is *ImageMetadataServer) Get(gc *gin.Context) {
+	var err errors.IError
+	fn := is.trackRequest(gc)
+	defer func() {
+		fn(err)
+	}()
+
+	var image *model.ImageMetadata
+	image, err = is.service.GetImageMetadata(gc.Request.Context(), gc.Param("id"))
+	if err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	gc.JSON(http.StatusOK, dtos.ImageMetadataResponseFromModel(image))
+}
+
+func (is *ImageMetadataServer) List(gc *gin.Context) {
+	var err errors.IError
+	fn := is.trackRequest(gc)
+	defer func() {
+		fn(err)
+	}()
+	logger := pkgLogger.Ctx(gc.Request.Context())
+
+	request := &dtos.ListImageMetadataRequest{}
+
+	if bindErr := gc.ShouldBindQuery(request); bindErr != nil {
+		err = errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(bindErr)
+		logger.WithError(err).Error("INVALID_REQUEST")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	if err = request.Validate(); err != nil {
+		logger.WithError(err).Error("VALIDATION_FAILURE")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	var images []*model.ImageMetadata
+	images, err = is.service.ListImageMetadata(gc.Request.Context(), request)
+	if err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	gc.JSON(http.StatusOK, gin.H{
+		"items": dtos.ImageMetadataResponsesFromModels(images),
+	})
+}
+
+func (is *ImageMetadataServer) Delete(gc *gin.Context) {
+	var err errors.IError
+	fn := is.trackRequest(gc)
+	defer func() {
+		fn(err)
+	}()
+
+	if err = is.service.DeleteImageMetadata(gc.Request.Context(), gc.Param("id")); err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	gc.Status(http.StatusNoContent)
+}
+
 // / that logs the latency and final status (success or failure).
 func (is *ImageMetadataServer) trackRequest(
 	gc *gin.Context,
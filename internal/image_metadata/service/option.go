@@ -1,8 +1,14 @@
 package service
 
 import (
+	"fmt"
+
+	"github.com/danushk97/image-analyzer/internal/image_metadata/repo"
+	imageMemory "github.com/danushk97/image-analyzer/internal/image_metadata/repo/memory"
 	imageSql "github.com/danushk97/image-analyzer/internal/image_metadata/repo/sql"
+	outboxService "github.com/danushk97/image-analyzer/internal/outbox/service"
 	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
 	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
 )
 
@@ -10,7 +16,8 @@ import (
 // the dependencies and configurations
 type Option func(*Service)
 
-// WithStorage adds the storage being used for offer storage
+// WithStorage adds the storage being used for offer storage, it panics
+// on a store without an image repo, see WithRepo to provide one
 func WithStorage(
 	store storage.Store,
 ) Option {
@@ -18,10 +25,32 @@ func WithStorage(
 		switch s := store.(type) {
 		case *sql.Repo:
 			opts.Repo = imageSql.NewRepo(s)
+		case *memory.Store:
+			opts.Repo = imageMemory.NewRepo(s)
+		default:
+			panic(fmt.Sprintf("unsupported store: %T", store))
 		}
 	}
 }
 
+// WithRepo sets the repo the images are stored in, e.g. a fake in the tests
+func WithRepo(
+	r repo.Repo,
+) Option {
+	return func(opts *Service) {
+		opts.Repo = r
+	}
+}
+
+// WithOutbox adds the outbox the image lifecycle events are recorded in
+func WithOutbox(
+	outbox *outboxService.Service,
+) Option {
+	return func(opts *Service) {
+		opts.Outbox = outbox
+	}
+}
+
 // NewOptions will create a new builder Service object and
 // apply all the options to that object and returns pointer
 // to the builder Service
@@ -3,18 +3,26 @@ package service
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	"github.com/danushk97/image-analyzer/internal/constants"
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
 	"github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
 	"github.com/danushk97/image-analyzer/internal/image_metadata/model/v1"
-	"github.com/danushk97/image-analyzer/internal/image_metadata/repo/sql"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/repo"
+	"github.com/danushk97/image-analyzer/internal/metrics"
+	outboxService "github.com/danushk97/image-analyzer/internal/outbox/service"
 
 	"github.com/danushk97/image-analyzer/pkg/contextkey"
 	"github.com/danushk97/image-analyzer/pkg/errors"
+	storageSql "github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
 )
 
 // Service is offer base service, this is used by all child services of offers
 type Service struct {
-	Repo *sql.Repo
+	Repo   repo.Repo
+	Outbox *outboxService.Service
 }
 
 // NewService returns the instance of Service with all options applied
@@ -26,17 +34,130 @@ func NewService(opts ...Option) *Service {
 func (s *Service) CreateImageMetadata(
 	ctx context.Context,
 	req *dtos.CreateImageMetadataRequest,
-) (*model.ImageMetadata, errors.IError) {
+) (_ *model.ImageMetadata, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "ImageMetadataService.CreateImageMetadata")
+	defer func() { tracing.End(span, err) }()
+
 	imageMetadata := &model.ImageMetadata{
 		Filename: req.FileName,
 		UserID:   contextkey.GetFromFromCtx(ctx, contextkey.UserID),
 		Status:   constants.StatusInitiated,
 	}
-	err := s.Repo.CreateImageMetadata(ctx, imageMetadata)
+	// the event is recorded in the same transaction as the image
+	err = s.Repo.Transaction(ctx, func(ctx context.Context) errors.IError {
+		if err := s.Repo.CreateImageMetadata(ctx, imageMetadata); err != nil {
+			return err
+		}
+
+		return s.recordEvent(ctx, constants.EventImageCreated, imageMetadata)
+	})
 
 	if err != nil {
 		return imageMetadata, err
 	}
 
+	metrics.ImagesCreatedTotal.WithLabelValues(imageMetadata.GetStatus()).Inc()
+
 	return imageMetadata, nil
 }
+
+// GetImageMetadata returns the image if it is owned by the user making the request
+func (s *Service) GetImageMetadata(
+	ctx context.Context,
+	id string,
+) (_ *model.ImageMetadata, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "ImageMetadataService.GetImageMetadata")
+	defer func() { tracing.End(span, err) }()
+
+	id = model.GetImageMetadataIDWithoutPrefix(id)
+	// malformed IDs can never match an image
+	if _, parseErr := uuid.Parse(id); parseErr != nil {
+		return nil, errors.NewNotFoundError(internalErr.ImageNotFound)
+	}
+
+	image, err := s.Repo.FindImageMetadataByID(ctx, id)
+	if storageSql.IsRecordNotFoundError(err) {
+		return nil, errors.NewNotFoundError(internalErr.ImageNotFound).Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// images of the other users are not disclosed
+	if image.GetUserID() != contextkey.GetFromFromCtx(ctx, contextkey.UserID) {
+		return nil, errors.NewNotFoundError(internalErr.ImageNotFound)
+	}
+
+	return image, nil
+}
+
+// ListImageMetadata returns a page of the images of the user making the request
+// matching the filters
+func (s *Service) ListImageMetadata(
+	ctx context.Context,
+	req *dtos.ListImageMetadataRequest,
+) (_ []*model.ImageMetadata, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "ImageMetadataService.ListImageMetadata")
+	defer func() { tracing.End(span, err) }()
+
+	// the images of every user would be listed without the filter
+	userID := contextkey.GetFromFromCtx(ctx, contextkey.UserID)
+	if userID == "" {
+		return nil, errors.NewAuthorizationError(internalErr.Unauthorized)
+	}
+
+	return s.Repo.ListImageMetadata(ctx, repo.ListOptions{
+		UserID:   userID,
+		Status:   req.Status,
+		FileType: req.FileType,
+		Limit:    req.GetCount(),
+		Offset:   req.Skip,
+	})
+}
+
+// DeleteImageMetadata removes the image of the user making the request
+func (s *Service) DeleteImageMetadata(ctx context.Context, id string) (err errors.IError) {
+	ctx, span := tracing.Start(ctx, "ImageMetadataService.DeleteImageMetadata")
+	defer func() { tracing.End(span, err) }()
+
+	image, err := s.GetImageMetadata(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// the event is recorded in the same transaction as the deletion
+	err = s.Repo.Transaction(ctx, func(ctx context.Context) errors.IError {
+		if err := s.Repo.DeleteImageMetadata(ctx, image); err != nil {
+			return err
+		}
+
+		return s.recordEvent(ctx, constants.EventImageDeleted, image)
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.ImagesDeletedTotal.Inc()
+
+	return nil
+}
+
+// recordEvent records the lifecycle event of the image in the outbox, the
+// events are not recorded when the service has no outbox, e.g. in the unit tests
+func (s *Service) recordEvent(
+	ctx context.Context,
+	eventType string,
+	image *model.ImageMetadata,
+) errors.IError {
+	if s.Outbox == nil {
+		return nil
+	}
+
+	return s.Outbox.Record(
+		ctx,
+		constants.AggregateImage,
+		image.GetPublicID(),
+		eventType,
+		dtos.ImageMetadataResponseFromModel(image),
+	)
+}
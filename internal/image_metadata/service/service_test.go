@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/repo"
+	imageMemory "github.com/danushk97/image-analyzer/internal/image_metadata/repo/memory"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+)
+
+func userContext(userID string) context.Context {
+	return contextkey.SetInContext(context.Background(), contextkey.UserID, userID)
+}
+
+func TestListImageMetadataOfTheUser(t *testing.T) {
+	s := NewService(WithStorage(memory.NewStore()))
+
+	for _, userID := range []string{"user-1", "user-1", "user-2"} {
+		if _, err := s.CreateImageMetadata(userContext(userID), &dtos.CreateImageMetadataRequest{FileName: "cat.png"}); err != nil {
+			t.Fatalf("create image: %v", err)
+		}
+	}
+
+	images, err := s.ListImageMetadata(userContext("user-1"), &dtos.ListImageMetadataRequest{})
+	if err != nil {
+		t.Fatalf("list images: %v", err)
+	}
+	if len(images) != 2 {
+		t.Fatalf("expected the 2 images of the user, got %d", len(images))
+	}
+	for _, image := range images {
+		if image.GetUserID() != "user-1" {
+			t.Errorf("expected only the images of the user, got the one of %s", image.GetUserID())
+		}
+	}
+}
+
+func TestListImageMetadataWithoutUser(t *testing.T) {
+	// the repo is not reached without a user
+	svc := NewService()
+
+	_, err := svc.ListImageMetadata(context.Background(), &dtos.ListImageMetadataRequest{})
+	if err == nil || !err.IsOfType(errors.AUTHORIZATION_ERROR) {
+		t.Errorf("expected an authorization error, got %v", err)
+	}
+}
+
+func TestGetImageMetadataMalformedID(t *testing.T) {
+	svc := NewService()
+
+	for _, id := range []string{"", "image_1 OR 1=1", "not-a-uuid"} {
+		if _, err := svc.GetImageMetadata(context.Background(), id); err == nil ||
+			!err.IsOfType(errors.NOT_FOUND_ERROR) {
+			t.Errorf("expected %q not to be found, got %v", id, err)
+		}
+	}
+}
+
+// unsupportedStore is a store without an image repo
+type unsupportedStore struct {
+	storage.Store
+}
+
+func TestWithStorageUnsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unsupported store")
+		}
+	}()
+
+	NewService(WithStorage(unsupportedStore{}))
+}
+
+func TestWithRepo(t *testing.T) {
+	r := imageMemory.NewRepo(memory.NewStore())
+	s := NewService(WithRepo(r))
+
+	if _, err := s.CreateImageMetadata(userContext("user-1"), &dtos.CreateImageMetadataRequest{FileName: "cat.png"}); err != nil {
+		t.Fatalf("create image: %v", err)
+	}
+	if images, _ := r.ListImageMetadata(context.Background(), repo.ListOptions{UserID: "user-1"}); len(images) != 1 {
+		t.Errorf("expected the image in the given repo, got %d", len(images))
+	}
+}
@@ -0,0 +1,152 @@
+package image_metadata
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+const (
+	// headerLastEventID is sent by the clients resuming a stream
+	headerLastEventID = "Last-Event-ID"
+
+	// heartbeatInterval keeps the idle streams open through the proxies
+	heartbeatInterval = 15 * time.Second
+
+	// deliveredWindow is the number of sent events remembered per stream
+	// to drop the ones received from both the replay and the broker
+	deliveredWindow = 1024
+)
+
+// deliveredEvents remembers the IDs of the last events sent on a stream.
+// The sequences are assigned when the events are recorded, not when they
+// are committed, so an event may arrive after one with a higher sequence
+// and can not be dropped by comparing it to the last one sent.
+type deliveredEvents struct {
+	ids   map[string]struct{}
+	order []string
+}
+
+func newDeliveredEvents() *deliveredEvents {
+	return &deliveredEvents{
+		ids:   make(map[string]struct{}, deliveredWindow),
+		order: make([]string, 0, deliveredWindow),
+	}
+}
+
+// add records the event ID and reports whether it was not sent yet,
+// the oldest ID is forgotten once the window is full
+func (d *deliveredEvents) add(id string) bool {
+	if _, ok := d.ids[id]; ok {
+		return false
+	}
+
+	if len(d.order) == deliveredWindow {
+		delete(d.ids, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.ids[id] = struct{}{}
+	d.order = append(d.order, id)
+
+	return true
+}
+
+// Events streams the status and analysis changes of the caller's images
+// as server-sent events. The ID of an event is its outbox sequence so a
+// client reconnecting with Last-Event-ID receives the events it missed,
+// along with the ones of the reorder window which it may have received.
+func (is *ImageMetadataServer) Events(gc *gin.Context) {
+	ctx := gc.Request.Context()
+	logger := pkgLogger.Ctx(ctx)
+	userID := contextkey.GetFromFromCtx(ctx, contextkey.UserID)
+
+	// the stream would stay idle forever without the notifications
+	if !is.events.IsStreaming() {
+		middlewares.ErrorResponse(gc, errors.NewNotImplementedError(internaErr.ImageEventsNotStreamed))
+		return
+	}
+
+	var lastSequence int64
+	if lastEventID := gc.GetHeader(headerLastEventID); lastEventID != "" {
+		sequence, parseErr := strconv.ParseInt(lastEventID, 10, 64)
+		if parseErr != nil || sequence < 0 {
+			middlewares.ErrorResponse(gc, errors.NewBadRequestError(
+				internaErr.InvalidLastEventID).Wrap(parseErr))
+			return
+		}
+		lastSequence = sequence
+	}
+
+	// subscribe before the replay so that no event falls in between
+	sub := is.events.Subscribe(userID)
+	defer is.events.Unsubscribe(sub)
+
+	var missed []*model.OutboxEvent
+	if lastSequence > 0 {
+		var err errors.IError
+		if missed, err = is.events.Replay(ctx, userID, lastSequence); err != nil {
+			middlewares.ErrorResponse(gc, err)
+			return
+		}
+	}
+
+	logger.Info("IMAGE_EVENTS_STREAM_STARTED")
+
+	gc.Header("Content-Type", sse.ContentType)
+	gc.Header("Cache-Control", "no-cache")
+	gc.Header("Connection", "keep-alive")
+	gc.Header("X-Accel-Buffering", "no")
+	gc.Status(http.StatusOK)
+
+	delivered := newDeliveredEvents()
+	for _, event := range missed {
+		writeEvent(gc, event, delivered)
+	}
+	gc.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	gc.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			_, _ = io.WriteString(w, ": heartbeat\n\n")
+			return true
+		case event, ok := <-sub.C:
+			if !ok {
+				// the client resumes from the last event it received
+				return false
+			}
+			writeEvent(gc, event, delivered)
+			return true
+		}
+	})
+
+	logger.Info("IMAGE_EVENTS_STREAM_CLOSED")
+}
+
+// writeEvent sends the event unless it was already sent
+func writeEvent(gc *gin.Context, event *model.OutboxEvent, delivered *deliveredEvents) {
+	if !delivered.add(event.GetID()) {
+		return
+	}
+
+	gc.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(event.GetSequence(), 10),
+		Event: event.GetEventType(),
+		Data:  event.GetPayload(),
+	})
+}
@@ -0,0 +1,78 @@
+package image_metadata
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/image_metadata/events"
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+)
+
+func outboxEvent(sequence int64) *model.OutboxEvent {
+	event := &model.OutboxEvent{EventType: "image.created", Payload: "{}"}
+	event.ID = fmt.Sprintf("event_%d", sequence)
+	event.Sequence = sequence
+
+	return event
+}
+
+func TestWriteEventOutOfOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	gc, _ := gin.CreateTestContext(rec)
+
+	delivered := newDeliveredEvents()
+	// 6 is committed after 7 and the replay overlaps the broker
+	for _, sequence := range []int64{5, 7, 6, 7, 5, 8} {
+		writeEvent(gc, outboxEvent(sequence), delivered)
+	}
+
+	var ids []string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if strings.HasPrefix(line, "id:") {
+			ids = append(ids, strings.TrimPrefix(line, "id:"))
+		}
+	}
+	if strings.Join(ids, ",") != "5,7,6,8" {
+		t.Errorf("expected the events 5,7,6,8 once each, got %v", ids)
+	}
+}
+
+func TestDeliveredEventsWindow(t *testing.T) {
+	delivered := newDeliveredEvents()
+	for i := 1; i <= deliveredWindow+1; i++ {
+		if !delivered.add(fmt.Sprintf("event_%d", i)) {
+			t.Fatalf("expected event_%d to be new", i)
+		}
+	}
+
+	if len(delivered.ids) != deliveredWindow {
+		t.Errorf("expected %d IDs, got %d", deliveredWindow, len(delivered.ids))
+	}
+	// the oldest ID is forgotten
+	if !delivered.add("event_1") {
+		t.Error("expected the oldest ID to be forgotten")
+	}
+	if delivered.add(fmt.Sprintf("event_%d", deliveredWindow+1)) {
+		t.Error("expected the last ID to be remembered")
+	}
+}
+
+func TestEventsWithoutListener(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	gc, _ := gin.CreateTestContext(rec)
+	gc.Request = httptest.NewRequest(http.MethodGet, "/v1/images/events", nil)
+
+	is := &ImageMetadataServer{events: events.NewBroker()}
+	is.Events(gc)
+
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without the notifications, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,126 @@
+package image_metadata
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	idempotencyService "github.com/danushk97/image-analyzer/internal/idempotency/service"
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	image_metadatav1 "github.com/danushk97/image-analyzer/rpc/image_metadata/v1"
+)
+
+// mutatingProcedures are the procedures changing the images
+var mutatingProcedures = map[string]bool{
+	"CreateImageMetadata": true,
+	"DeleteImageMetadata": true,
+}
+
+// ImageMetadataTwirpServer serves the RPC API over Twirp, both JSON and
+// protobuf, on the same router as the REST API
+type ImageMetadataTwirpServer struct {
+	handler     image_metadatav1.TwirpServer
+	idempotency *idempotencyService.Service
+}
+
+// NewTwirpServer creates a new Twirp server
+func NewTwirpServer(
+	rpcServer *ImageMetadataRPCServer,
+	idempotency *idempotencyService.Service,
+) *ImageMetadataTwirpServer {
+	return &ImageMetadataTwirpServer{
+		handler: image_metadatav1.NewImageMetadataServiceServer(
+			&twirpService{rpc: rpcServer},
+			nil,
+		),
+		idempotency: idempotency,
+	}
+}
+
+// SetupRoutes mounts the Twirp handler behind the same middlewares as the
+// REST API of the images, their errors are answered as twirp errors
+func (ts *ImageMetadataTwirpServer) SetupRoutes(r *gin.Engine) {
+	twirpApi := r.Group(strings.TrimSuffix(ts.handler.PathPrefix(), "/"))
+	twirpApi.Use(
+		middlewares.TwirpErrorMiddleware(),
+		middlewares.AuthMiddleware(),
+	)
+
+	twirpApi.POST("/:method", ts.idempotent(), gin.WrapH(ts.handler))
+}
+
+// idempotent honours the Idempotency-Key header on the procedures changing
+// the images, every procedure is called with POST
+func (ts *ImageMetadataTwirpServer) idempotent() gin.HandlerFunc {
+	idempotency := middlewares.IdempotencyMiddleware(ts.idempotency)
+
+	return func(gc *gin.Context) {
+		if !mutatingProcedures[gc.Param("method")] {
+			gc.Next()
+			return
+		}
+
+		idempotency(gc)
+	}
+}
+
+// twirpService converts the errors of the RPC server to twirp errors
+type twirpService struct {
+	rpc *ImageMetadataRPCServer
+}
+
+func (ts *twirpService) CreateImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.CreateImageMetadataRequest,
+) (*image_metadatav1.CreateImageMetadataResponse, error) {
+	resp, err := ts.rpc.CreateImageMetadata(ctx, req)
+	if err != nil {
+		return nil, toTwirpError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+func (ts *twirpService) GetImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.GetImageMetadataRequest,
+) (*image_metadatav1.GetImageMetadataResponse, error) {
+	resp, err := ts.rpc.GetImageMetadata(ctx, req)
+	if err != nil {
+		return nil, toTwirpError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+func (ts *twirpService) ListImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.ListImageMetadataRequest,
+) (*image_metadatav1.ListImageMetadataResponse, error) {
+	resp, err := ts.rpc.ListImageMetadata(ctx, req)
+	if err != nil {
+		return nil, toTwirpError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+func (ts *twirpService) DeleteImageMetadata(
+	ctx context.Context,
+	req *image_metadatav1.DeleteImageMetadataRequest,
+) (*image_metadatav1.DeleteImageMetadataResponse, error) {
+	resp, err := ts.rpc.DeleteImageMetadata(ctx, req)
+	if err != nil {
+		return nil, toTwirpError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// toTwirpError logs the error and converts it to a twirp error
+func toTwirpError(ctx context.Context, err error) error {
+	pkgLogger.Ctx(ctx).WithError(err).Error(err.Error())
+	return middlewares.NewTwirpError(ctx, err)
+}
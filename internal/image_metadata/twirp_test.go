@@ -0,0 +1,83 @@
+package image_metadata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/image_metadata/service"
+)
+
+// twirpErrorBody is the JSON body of a twirp error
+type twirpErrorBody struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta"`
+}
+
+// newTwirpRouter serves the twirp API without storage and idempotency
+// service, the requests reaching them are not expected
+func newTwirpRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewTwirpServer(NewRPCServer(service.NewService()), nil).SetupRoutes(r)
+
+	return r
+}
+
+func callTwirp(r *gin.Engine, method string, body string, headers map[string]string) (*httptest.ResponseRecorder, twirpErrorBody) {
+	req := httptest.NewRequest(http.MethodPost,
+		"/twirp/image_metadata.v1.ImageMetadataService/"+method, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var twErr twirpErrorBody
+	_ = json.Unmarshal(rec.Body.Bytes(), &twErr)
+
+	return rec, twErr
+}
+
+func TestTwirpAuthError(t *testing.T) {
+	rec, twErr := callTwirp(newTwirpRouter(), "GetImageMetadata", `{"id":"image_1"}`, nil)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected a twirp error, got the content type %q", ct)
+	}
+	if twErr.Code != "unauthenticated" || twErr.Meta["code"] != internaErr.Unauthorized {
+		t.Errorf("expected an unauthenticated twirp error, got %+v", twErr)
+	}
+}
+
+func TestTwirpIdempotencyOfMutatingProcedures(t *testing.T) {
+	r := newTwirpRouter()
+	headers := map[string]string{
+		constants.HeaderUserId:         "user_1",
+		constants.HeaderIdempotencyKey: strings.Repeat("k", 256),
+	}
+
+	// the key is checked on the procedures changing the images
+	rec, twErr := callTwirp(r, "CreateImageMetadata", `{"file_name":"a.png"}`, headers)
+	if rec.Code != http.StatusBadRequest || twErr.Msg != internaErr.InvalidIdempotencyKey {
+		t.Errorf("expected the key to be rejected, got %d %s", rec.Code, rec.Body)
+	}
+
+	// and ignored on the others
+	rec, twErr = callTwirp(r, "GetImageMetadata", `{"id":"image_1"}`, headers)
+	if rec.Code != http.StatusNotFound || twErr.Code != "not_found" {
+		t.Errorf("expected the image not to be found, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "image_analyzer"
+
+// Registry holds the collectors exposed on the metrics endpoint
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestsTotal counts the requests by method, route template and status
+	HTTPRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	// HTTPRequestDuration observes the latency of the requests by method, route template and status
+	HTTPRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	// ImagesCreatedTotal counts the images created by their initial status
+	ImagesCreatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "images",
+			Name:      "created_total",
+			Help:      "Number of images created by status.",
+		},
+		[]string{"status"},
+	)
+
+	// ImagesDeletedTotal counts the images deleted
+	ImagesDeletedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "images",
+			Name:      "deleted_total",
+			Help:      "Number of images deleted.",
+		},
+	)
+
+	// WebhookDeliveryAttemptsTotal counts the attempts of the webhook deliveries by outcome
+	WebhookDeliveryAttemptsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "webhooks",
+			Name:      "delivery_attempts_total",
+			Help:      "Number of webhook delivery attempts by outcome.",
+		},
+		[]string{"outcome"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		ImagesCreatedTotal,
+		ImagesDeletedTotal,
+		WebhookDeliveryAttemptsTotal,
+	)
+}
+
+// Handler serves the collectors of the registry
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+const (
+	// Path the metrics are served at
+	Path = "/metrics"
+	// DefaultShutdownTimeout is the default time in seconds allowed to shutdown the listener
+	DefaultShutdownTimeout = 5
+)
+
+// Config holds the metrics configurations
+type Config struct {
+	// Address of a separate listener of the API server metrics,
+	// they are served by the HTTP server when empty
+	Address string
+	// RelayAddress is the listener of the outbox relay metrics,
+	// they are not served when empty
+	RelayAddress string
+}
+
+// MetricsServer serves the metrics on the HTTP server or on its own listener
+type MetricsServer struct {
+	address string
+	server  *http.Server
+}
+
+// NewServer creates a new server of the metrics on the given address
+func NewServer(address string) *MetricsServer {
+	return &MetricsServer{address: address}
+}
+
+// SetupRoutes serves the metrics on the HTTP server when there
+// is no separate listener
+func (ms *MetricsServer) SetupRoutes(r *gin.Engine) {
+	if ms.address != "" {
+		return
+	}
+
+	r.GET(Path, gin.WrapH(Handler()))
+}
+
+// Run serves the metrics on the separate listener until the context is done
+func (ms *MetricsServer) Run(ctx context.Context) error {
+	if ms.address == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	ms.server = &http.Server{
+		Addr:    ms.address,
+		Handler: mux,
+	}
+
+	pkgLogger.Ctx(ctx).Info(
+		fmt.Sprintf("registered server address metrics_server: %v", ms.address),
+	)
+
+	go func() {
+		if err := ms.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			pkgLogger.Ctx(ctx).WithError(err).Error("METRICS_SERVER_ERROR")
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(
+		context.WithoutCancel(ctx), DefaultShutdownTimeout*time.Second)
+	defer cancel()
+
+	return ms.server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,153 @@
+package middlewares
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// redactedValue replaces the values of the sensitive headers in the logs
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders are always redacted from the access logs
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// AccessLogConfig holds the access log configurations
+type AccessLogConfig struct {
+	// SampleRate is the ratio of the requests logged, between 0 and 1.
+	// The server errors and the slow requests are always logged.
+	SampleRate float64
+	// SlowThreshold is the latency in milliseconds above which the
+	// requests are logged as warnings, disabled when 0
+	SlowThreshold int
+	// RedactHeaders are redacted along with the default sensitive headers
+	RedactHeaders []string
+}
+
+// Validate checks the sample rate and the slow threshold
+func (c AccessLogConfig) Validate() error {
+	return validation.ValidateStruct(
+		&c,
+		validation.Field(&c.SampleRate, validation.Min(0.0), validation.Max(1.0)),
+		validation.Field(&c.SlowThreshold, validation.Min(0)),
+	)
+}
+
+// accessLogSettings are the settings of the middleware derived from its config
+type accessLogSettings struct {
+	sampleRate    float64
+	slowThreshold time.Duration
+	redacted      map[string]bool
+}
+
+// accessLog holds the current settings, they are replaced when the config is reloaded
+var accessLog atomic.Pointer[accessLogSettings]
+
+// SetAccessLogConfig changes the config of the access logs, it
+// applies to the requests served from then on
+func SetAccessLogConfig(config AccessLogConfig) {
+	accessLog.Store(&accessLogSettings{
+		sampleRate:    config.SampleRate,
+		slowThreshold: time.Duration(config.SlowThreshold) * time.Millisecond,
+		redacted:      redactedHeaders(config.RedactHeaders),
+	})
+}
+
+// AccessLogMiddleware logs every request once it is served with its
+// method, route template, status, latency, size and caller
+func AccessLogMiddleware(config AccessLogConfig) gin.HandlerFunc {
+	SetAccessLogConfig(config)
+
+	return func(gc *gin.Context) {
+		startTime := time.Now()
+
+		gc.Next()
+
+		settings := accessLog.Load()
+		latency := time.Since(startTime)
+		status := gc.Writer.Status()
+		slow := settings.slowThreshold > 0 && latency > settings.slowThreshold
+
+		if !sampled(status, slow, settings.sampleRate) {
+			return
+		}
+
+		route := gc.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		ctx := gc.Request.Context()
+		logger := pkgLogger.Ctx(ctx).WithFields(map[string]interface{}{
+			"method":     gc.Request.Method,
+			"route":      route,
+			"status":     status,
+			"latency_ms": latency.Milliseconds(),
+			"bytes":      max(gc.Writer.Size(), 0),
+			"user_id":    contextkey.GetFromFromCtx(ctx, contextkey.UserID),
+			"client_ip":  gc.ClientIP(),
+			"headers":    redactHeaders(gc.Request.Header, settings.redacted),
+		})
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("HTTP_REQUEST")
+		case slow:
+			logger.Warn("HTTP_REQUEST_SLOW")
+		default:
+			logger.Info("HTTP_REQUEST")
+		}
+	}
+}
+
+// sampled returns true if the request is logged, the server errors
+// and the slow requests are logged whatever the sample rate
+func sampled(status int, slow bool, sampleRate float64) bool {
+	if status >= http.StatusInternalServerError || slow {
+		return true
+	}
+
+	return rand.Float64() < sampleRate
+}
+
+// redactedHeaders returns the canonical names of the default
+// sensitive headers along with the configured ones
+func redactedHeaders(headers []string) map[string]bool {
+	redacted := make(map[string]bool, len(sensitiveHeaders)+len(headers))
+	for _, header := range sensitiveHeaders {
+		redacted[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, header := range headers {
+		redacted[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return redacted
+}
+
+// redactHeaders flattens the headers replacing the values of the redacted ones
+func redactHeaders(header http.Header, redacted map[string]bool) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		if redacted[name] {
+			headers[name] = redactedValue
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	return headers
+}
@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSampled(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		slow       bool
+		sampleRate float64
+		want       bool
+	}{
+		{name: "all sampled", status: http.StatusOK, sampleRate: 1, want: true},
+		{name: "none sampled", status: http.StatusOK, sampleRate: 0},
+		{name: "client error not sampled", status: http.StatusNotFound, sampleRate: 0},
+		{name: "server error", status: http.StatusServiceUnavailable, sampleRate: 0, want: true},
+		{name: "slow request", status: http.StatusOK, slow: true, sampleRate: 0, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the decision is the same on every request at the bounds of the rate
+			for i := 0; i < 100; i++ {
+				if got := sampled(tt.status, tt.slow, tt.sampleRate); got != tt.want {
+					t.Fatalf("expected sampled to be %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer token")
+	header.Set("Cookie", "session=1")
+	header.Set("X-Tenant-Secret", "secret")
+	header.Set("Accept", "application/json")
+	header.Add("X-Forwarded-For", "10.0.0.1")
+	header.Add("X-Forwarded-For", "10.0.0.2")
+
+	got := redactHeaders(header, redactedHeaders([]string{"x-tenant-secret"}))
+
+	want := map[string]string{
+		"Authorization":   redactedValue,
+		"Cookie":          redactedValue,
+		"X-Tenant-Secret": redactedValue,
+		"Accept":          "application/json",
+		"X-Forwarded-For": "10.0.0.1, 10.0.0.2",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d headers, got %v", len(want), got)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("expected %s to be %q, got %q", name, value, got[name])
+		}
+	}
+}
+
+func TestSetAccessLogConfig(t *testing.T) {
+	AccessLogMiddleware(AccessLogConfig{SampleRate: 1, SlowThreshold: 1000})
+	defer SetAccessLogConfig(AccessLogConfig{SampleRate: 1, SlowThreshold: 1000})
+
+	// the middleware reads the settings reloaded after its creation
+	SetAccessLogConfig(AccessLogConfig{SampleRate: 0.5, RedactHeaders: []string{"x-tenant-secret"}})
+
+	settings := accessLog.Load()
+	if settings.sampleRate != 0.5 || settings.slowThreshold != 0 {
+		t.Errorf("expected the reloaded settings, got %+v", settings)
+	}
+	if !settings.redacted["X-Tenant-Secret"] || !settings.redacted["Authorization"] {
+		t.Errorf("expected the configured and the sensitive headers redacted, got %v", settings.redacted)
+	}
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// debugLogTokenSeparator separates the expiry of the token from its signature
+const debugLogTokenSeparator = "."
+
+// NewDebugLogToken creates a token for the x-debug-log header valid until
+// expiresAt. The token is the unix expiry and its HMAC-SHA256 signature.
+func NewDebugLogToken(secret string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expiry + debugLogTokenSeparator + signDebugLogExpiry(secret, expiry)
+}
+
+// DebugLogMiddleware enables all the logs of the requests carrying a valid
+// token in the x-debug-log header. The header is ignored when there is no secret.
+func DebugLogMiddleware(secret string) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		token := gc.GetHeader(constants.HeaderDebugLog)
+		if secret == "" || token == "" {
+			gc.Next()
+			return
+		}
+
+		ctx := gc.Request.Context()
+		if !verifyDebugLogToken(secret, token, time.Now()) {
+			pkgLogger.Ctx(ctx).Warn("INVALID_DEBUG_LOG_TOKEN")
+			gc.Next()
+			return
+		}
+
+		gc.Request = gc.Request.WithContext(pkgLogger.WithDebug(ctx))
+
+		gc.Next()
+	}
+}
+
+// AdminAuthMiddleware allows only the callers presenting the admin token as a bearer token
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		presented := strings.TrimPrefix(gc.GetHeader("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			pkgLogger.Ctx(gc.Request.Context()).Warn("ADMIN_AUTH_FAILED")
+			ErrorResponse(gc, errors.NewAuthorizationError(internaErr.InvalidAdminToken))
+			gc.Abort()
+			return
+		}
+
+		gc.Next()
+	}
+}
+
+// verifyDebugLogToken checks the signature and the expiry of the token
+func verifyDebugLogToken(secret string, token string, now time.Time) bool {
+	expiry, signature, found := strings.Cut(token, debugLogTokenSeparator)
+	if !found {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return false
+	}
+
+	expected := signDebugLogExpiry(secret, expiry)
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// signDebugLogExpiry signs the expiry of a debug log token
+func signDebugLogExpiry(secret string, expiry string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(constants.HeaderDebugLog + debugLogTokenSeparator + expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVerifyDebugLogToken(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	valid := NewDebugLogToken("secret", now.Add(time.Minute))
+	expiry := strconv.FormatInt(now.Add(time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+		want  bool
+	}{
+		{name: "valid", token: valid, now: now, want: true},
+		{name: "at the expiry", token: valid, now: now.Add(time.Minute), want: true},
+		{name: "expired", token: valid, now: now.Add(time.Minute + time.Second)},
+		{name: "other secret", token: NewDebugLogToken("other", now.Add(time.Minute)), now: now},
+		{name: "extended expiry", token: strconv.FormatInt(now.Add(time.Hour).Unix(), 10) + valid[len(expiry):], now: now},
+		{name: "without signature", token: expiry, now: now},
+		{name: "empty signature", token: expiry + debugLogTokenSeparator, now: now},
+		{name: "malformed expiry", token: "soon" + valid[len(expiry):], now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyDebugLogToken("secret", tt.token, tt.now); got != tt.want {
+				t.Errorf("expected the token to be valid %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		status        int
+	}{
+		{name: "admin token", token: "admin", authorization: "Bearer admin", status: http.StatusOK},
+		{name: "wrong token", token: "admin", authorization: "Bearer user", status: http.StatusUnauthorized},
+		{name: "missing token", token: "admin", status: http.StatusUnauthorized},
+		{name: "admin disabled", authorization: "Bearer ", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(AdminAuthMiddleware(tt.token))
+			r.GET("/admin/log-level", func(gc *gin.Context) { gc.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/log-level", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	featureFlagService "github.com/danushk97/image-analyzer/internal/featureflag/service"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// FeatureFlagMiddleware serves the route only to the users the flag is on for,
+// the others get a not found as if the route did not exist. It must come after
+// the AuthMiddleware for the flags targeting the users.
+func FeatureFlagMiddleware(flags *featureFlagService.Service, name string) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		ctx := gc.Request.Context()
+		if !flags.IsEnabled(ctx, name) {
+			pkgLogger.Ctx(ctx).WithField("feature", name).Info("FEATURE_DISABLED")
+			ErrorResponse(gc, errors.NewNotFoundError(internaErr.FeatureDisabled))
+			gc.Abort()
+			return
+		}
+
+		gc.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+)
+
+// GraphQLError is a resolver error with the same semantics as
+// the problem details of the REST API, the code, request ID and
+// field errors are exposed as the extensions of the error
+type GraphQLError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+// NewGraphQLError converts the error returned by the services
+func NewGraphQLError(ctx context.Context, err error) *GraphQLError {
+	class, detail, fieldErrors := classifyError(err)
+
+	extensions := map[string]interface{}{
+		"code":   class.code,
+		"status": class.status,
+	}
+
+	if requestID := contextkey.GetFromFromCtx(ctx, contextkey.RequestID); requestID != "" {
+		extensions["request_id"] = requestID
+	}
+
+	if len(fieldErrors) > 0 {
+		extensions["errors"] = fieldErrors
+	}
+
+	return &GraphQLError{
+		message:    detail,
+		extensions: extensions,
+	}
+}
+
+func (e *GraphQLError) Error() string {
+	return e.message
+}
+
+// Extensions is used by the executor to fill the extensions of the error
+func (e *GraphQLError) Extensions() map[string]interface{} {
+	return e.extensions
+}
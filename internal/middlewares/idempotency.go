@@ -0,0 +1,150 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	idempotencyService "github.com/danushk97/image-analyzer/internal/idempotency/service"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// maxIdempotencyKeyLength is the maximum accepted length of the key
+const maxIdempotencyKeyLength = 255
+
+// responseCaptureWriter keeps a copy of the response body written by the handlers
+type responseCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware honours the Idempotency-Key header on mutating requests.
+// The first response for a key is stored and replayed for the repeated requests,
+// a key reused with a different request is rejected with a conflict.
+// It must be registered after AuthMiddleware as keys are scoped per user.
+// The responses are stored as they are written, it must not be used on the
+// routes whose responses carry secrets.
+func IdempotencyMiddleware(svc *idempotencyService.Service) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		switch gc.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			gc.Next()
+			return
+		}
+
+		key := gc.GetHeader(constants.HeaderIdempotencyKey)
+		if key == "" {
+			gc.Next()
+			return
+		}
+
+		ctx := gc.Request.Context()
+		logger := pkgLogger.Ctx(ctx)
+
+		if len(key) > maxIdempotencyKeyLength {
+			ErrorResponse(gc, errors.NewBadRequestError(internaErr.InvalidIdempotencyKey))
+			gc.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(gc.Request.Body)
+		if err != nil {
+			ErrorResponse(gc, errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(err))
+			gc.Abort()
+			return
+		}
+		gc.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, replay, iErr := svc.Begin(
+			ctx,
+			contextkey.GetFromFromCtx(ctx, contextkey.UserID),
+			key,
+			requestFingerprint(gc.Request, body),
+		)
+		if iErr != nil {
+			ErrorResponse(gc, iErr)
+			gc.Abort()
+			return
+		}
+
+		if replay {
+			logger.Info("IDEMPOTENT_REQUEST_REPLAYED")
+			gc.Header(constants.HeaderIdempotentReplayed, "true")
+			gc.Data(
+				record.GetResponseStatus(),
+				record.GetResponseType(),
+				[]byte(record.GetResponseBody()),
+			)
+			gc.Abort()
+			return
+		}
+
+		writer := &responseCaptureWriter{
+			ResponseWriter: gc.Writer,
+			body:           &bytes.Buffer{},
+		}
+		gc.Writer = writer
+
+		// the key is released if the handler panics so that the client can
+		// retry, the recovery of the panic is registered before this middleware
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if iErr := svc.Release(ctx, record); iErr != nil {
+				logger.WithError(iErr).Error("IDEMPOTENCY_KEY_STORE_ERROR")
+			}
+		}()
+
+		gc.Next()
+		completed = true
+
+		// server errors are not stored so that the client can retry
+		if writer.Status() >= http.StatusInternalServerError {
+			iErr = svc.Release(ctx, record)
+		} else {
+			iErr = svc.Complete(
+				ctx,
+				record,
+				writer.Status(),
+				writer.Header().Get("Content-Type"),
+				writer.body.String(),
+			)
+		}
+
+		if iErr != nil {
+			logger.WithError(iErr).Error("IDEMPOTENCY_KEY_STORE_ERROR")
+		}
+	}
+}
+
+// requestFingerprint hashes the parts of the request
+// which identify it for the idempotency key
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte(r.URL.Path))
+	hash.Write([]byte{'?'})
+	hash.Write([]byte(r.URL.RawQuery))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
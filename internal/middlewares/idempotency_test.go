@@ -0,0 +1,194 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	"github.com/danushk97/image-analyzer/internal/idempotency/model/v1"
+	idempotencyService "github.com/danushk97/image-analyzer/internal/idempotency/service"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+const testUserID = "8f0c5ab4-3d4e-4a4f-9b4c-7f6f2f6a9e01"
+
+// fakeKeys stores the idempotency keys in a map
+type fakeKeys struct {
+	mu   sync.Mutex
+	keys map[string]model.IdempotencyKey
+}
+
+func (f *fakeKeys) FindIdempotencyKey(_ context.Context, userID string, key string) (*model.IdempotencyKey, errors.IError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	record, ok := f.keys[userID+key]
+	if !ok {
+		return nil, errors.NewBadRequestError("record_not_found").Wrap(gorm.ErrRecordNotFound)
+	}
+	return &record, nil
+}
+
+func (f *fakeKeys) CreateIdempotencyKey(_ context.Context, record *model.IdempotencyKey) errors.IError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.keys[record.UserID+record.Key]; ok {
+		return errors.NewConflictError("unique_violation")
+	}
+	f.keys[record.UserID+record.Key] = *record
+	return nil
+}
+
+func (f *fakeKeys) UpdateIdempotencyKey(_ context.Context, record *model.IdempotencyKey) errors.IError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.keys[record.UserID+record.Key] = *record
+	return nil
+}
+
+func (f *fakeKeys) DeleteIdempotencyKey(_ context.Context, record *model.IdempotencyKey) errors.IError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.keys, record.UserID+record.Key)
+	return nil
+}
+
+func (f *fakeKeys) DeleteExpiredIdempotencyKeys(context.Context, int64) (int64, errors.IError) {
+	return 0, nil
+}
+
+// newIdempotentRouter serves the handler behind the idempotency middleware
+func newIdempotentRouter(keys *fakeKeys, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	svc := idempotencyService.NewService(idempotencyService.WithConfig(idempotencyService.Config{KeyTTL: 3600}))
+	svc.Repo = keys
+
+	r := gin.New()
+	r.Use(gin.Recovery(), AuthMiddleware(), IdempotencyMiddleware(svc))
+	r.POST("/v1/images", handler)
+
+	return r
+}
+
+func post(r *gin.Engine, target string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(constants.HeaderUserId, testUserID)
+	req.Header.Set(constants.HeaderIdempotencyKey, key)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	keys := &fakeKeys{keys: map[string]model.IdempotencyKey{}}
+	calls := 0
+	r := newIdempotentRouter(keys, func(gc *gin.Context) {
+		calls++
+		gc.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	first := post(r, "/v1/images", "key-1", `{"file_name":"cat.png"}`)
+	second := post(r, "/v1/images", "key-1", `{"file_name":"cat.png"}`)
+
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once, got %d", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("expected the stored response, got %d %s", second.Code, second.Body)
+	}
+	if second.Header().Get(constants.HeaderIdempotentReplayed) != "true" {
+		t.Error("expected the replay to be flagged")
+	}
+
+	// the completed key is retained for the TTL
+	if record := keys.keys[testUserID+"key-1"]; record.ExpiresAt < time.Now().Unix()+3000 {
+		t.Errorf("expected the key to be retained for the TTL, expires in %ds", record.ExpiresAt-time.Now().Unix())
+	}
+}
+
+func TestIdempotencyKeyReusedWithAnotherRequest(t *testing.T) {
+	keys := &fakeKeys{keys: map[string]model.IdempotencyKey{}}
+	r := newIdempotentRouter(keys, func(gc *gin.Context) {
+		gc.Status(http.StatusCreated)
+	})
+
+	post(r, "/v1/images?dry_run=false", "key-1", `{}`)
+
+	for name, target := range map[string]string{
+		"other query": "/v1/images?dry_run=true",
+		"no query":    "/v1/images",
+	} {
+		if rec := post(r, target, "key-1", `{}`); rec.Code != http.StatusConflict {
+			t.Errorf("%s: expected a conflict, got %d %s", name, rec.Code, rec.Body)
+		}
+	}
+	if rec := post(r, "/v1/images?dry_run=false", "key-1", `{"file_name":"dog.png"}`); rec.Code != http.StatusConflict {
+		t.Errorf("other body: expected a conflict, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestIdempotencyKeyReleasedOnPanic(t *testing.T) {
+	keys := &fakeKeys{keys: map[string]model.IdempotencyKey{}}
+	calls := 0
+	r := newIdempotentRouter(keys, func(gc *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		gc.Status(http.StatusCreated)
+	})
+
+	if rec := post(r, "/v1/images", "key-1", `{}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the panic to be recovered, got %d", rec.Code)
+	}
+	if _, ok := keys.keys[testUserID+"key-1"]; ok {
+		t.Fatal("expected the key to be released")
+	}
+
+	if rec := post(r, "/v1/images", "key-1", `{}`); rec.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("expected the retry to run, got %d after %d calls", rec.Code, calls)
+	}
+}
+
+func TestIdempotencyInProgressLease(t *testing.T) {
+	keys := &fakeKeys{keys: map[string]model.IdempotencyKey{}}
+	r := newIdempotentRouter(keys, func(gc *gin.Context) {
+		gc.Status(http.StatusCreated)
+	})
+
+	// a key left in progress by a crashed process
+	now := time.Now().Unix()
+	keys.keys[testUserID+"key-1"] = model.IdempotencyKey{
+		UserID:      testUserID,
+		Key:         "key-1",
+		RequestHash: requestFingerprint(httptest.NewRequest(http.MethodPost, "/v1/images", nil), []byte(`{}`)),
+		ExpiresAt:   now + idempotencyService.DefaultInProgressLease,
+	}
+
+	if rec := post(r, "/v1/images", "key-1", `{}`); rec.Code != http.StatusConflict {
+		t.Fatalf("expected a conflict while the request is in progress, got %d", rec.Code)
+	}
+
+	// the lease ran out
+	record := keys.keys[testUserID+"key-1"]
+	record.ExpiresAt = now - 1
+	keys.keys[testUserID+"key-1"] = record
+
+	if rec := post(r, "/v1/images", "key-1", `{}`); rec.Code != http.StatusCreated {
+		t.Errorf("expected the retry to run after the lease, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/metrics"
+)
+
+// unmatchedRoute is the route label of the requests not matching any route,
+// the raw paths are not used to bound the cardinality of the metrics
+const unmatchedRoute = "unmatched"
+
+// otherMethod is the method label of the requests with a non standard method,
+// the clients can send any method and each would create new series
+const otherMethod = "other"
+
+// metricsMethods are the methods used as is in the labels
+var metricsMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// MetricsMiddleware counts the requests and observes their latency
+// by method, route template and status
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		startTime := time.Now()
+
+		gc.Next()
+
+		route := gc.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		method := gc.Request.Method
+		if !metricsMethods[method] {
+			method = otherMethod
+		}
+
+		labels := []string{method, route, strconv.Itoa(gc.Writer.Status())}
+
+		metrics.HTTPRequestsTotal.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).
+			Observe(time.Since(startTime).Seconds())
+	}
+}
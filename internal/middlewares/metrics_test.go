@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/danushk97/image-analyzer/internal/metrics"
+)
+
+func TestMetricsMiddlewareLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(MetricsMiddleware())
+	r.GET("/v1/images/:id", func(gc *gin.Context) { gc.Status(http.StatusOK) })
+	r.Handle("PURGE", "/v1/images/:id", func(gc *gin.Context) { gc.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		labels []string
+	}{
+		{name: "route template", method: http.MethodGet, path: "/v1/images/image_1", labels: []string{"GET", "/v1/images/:id", "200"}},
+		{name: "unmatched route", method: http.MethodGet, path: "/v1/unknown/image_1", labels: []string{"GET", unmatchedRoute, "404"}},
+		{name: "non standard method", method: "PURGE", path: "/v1/images/image_1", labels: []string{otherMethod, "/v1/images/:id", "204"}},
+		{name: "unknown method", method: "X-RANDOM-42", path: "/v1/images/image_1", labels: []string{otherMethod, unmatchedRoute, "404"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(tt.labels...))
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got := testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(tt.labels...)); got != before+1 {
+				t.Errorf("expected the request counted with %v, got %v", tt.labels, got-before)
+			}
+		})
+	}
+}
@@ -1,49 +1,35 @@
 package middlewares
 
 import (
-	"net/http"
-
 	"github.com/danushk97/image-analyzer/internal/constants"
 	internaErr "github.com/danushk97/image-analyzer/internal/errors"
 	"github.com/danushk97/image-analyzer/pkg/contextkey"
 	"github.com/danushk97/image-analyzer/pkg/errors"
 	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"github.com/gin-gonic/gin/render"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrorResponse writes the error as an RFC 7807 problem details response,
+// or as a twirp error on the routes behind TwirpErrorMiddleware
 func ErrorResponse(ctx *gin.Context, err error) {
-	log := pkgLogger.Ctx(ctx)
+	log := pkgLogger.Ctx(ctx.Request.Context())
 	log.WithError(err).Error(err.Error())
 
-	var problemDetail = gin.H{
-		"code":        internaErr.ServerError,
-		"description": "Something went wrong, please try again in some time.",
-	}
-
-	iErr, ok := err.(errors.IError)
-	if !ok {
-		ctx.JSON(http.StatusInternalServerError, problemDetail)
+	if ctx.GetBool(twirpErrorsKey) {
+		_ = twirp.WriteError(ctx.Writer, NewTwirpError(ctx.Request.Context(), err))
 		return
 	}
 
-	if iErr.IsOfType(errors.BAD_REQUEST_ERROR) {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"code":        internaErr.BadRequesterror,
-			"description": iErr.Error(),
-		})
-
-		return
-	} else if iErr.IsOfType(errors.AUTHORIZATION_ERROR) {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"code":        internaErr.Unauthorized,
-			"description": iErr.Error(),
-		})
+	problem := NewProblem(ctx.Request, err)
 
-		return
-	} else {
-		ctx.JSON(http.StatusInternalServerError, problemDetail)
-	}
+	// the content type set here is kept by the JSON renderer
+	ctx.Header("Content-Type", ContentTypeProblemJSON)
+	ctx.Render(problem.Status, render.JSON{Data: problem})
 }
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -61,6 +47,7 @@ func AuthMiddleware() gin.HandlerFunc {
 				errors.NewAuthorizationError(internaErr.Unauthorized),
 			)
 			gc.Abort()
+			return
 		}
 
 		ctx := contextkey.SetInContext(
@@ -76,19 +63,16 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// CtxMiddleware sets the request ID and the path in the context. The ID
+// supplied by the caller in the x-request-id header is honoured when it is
+// valid, else the trace ID of a valid inbound traceparent is used, else a
+// new one is generated. The ID is echoed back in the response headers.
 func CtxMiddleware() gin.HandlerFunc {
 	return func(gc *gin.Context) {
 		// Convert the Gin context to a Go context (using gin.Context's context method)
 		ctx := gc.Request.Context()
 
-		// Retrieve the request ID from gin context keys, if available
-		var requestIDStr string
-		if requestID, ok := gc.Keys[constants.HeaderRequestId].(string); ok && requestID != "" {
-			requestIDStr = requestID
-		} else {
-			// Generate a new UUID if no request ID is found
-			requestIDStr = uuid.NewString()
-		}
+		requestIDStr := inboundRequestID(gc)
 
 		// Set the request ID in the context (using the proper key)
 		ctx = contextkey.SetInContext(ctx, contextkey.RequestID, requestIDStr)
@@ -99,7 +83,38 @@ func CtxMiddleware() gin.HandlerFunc {
 		// Now, reattach the updated context to the Gin context
 		gc.Request = gc.Request.WithContext(ctx)
 
+		// echo the request ID back to the caller
+		gc.Header(constants.HeaderRequestId, requestIDStr)
+
 		// Continue processing the request
 		gc.Next()
 	}
 }
+
+// inboundRequestID returns the request ID supplied by the caller, the IDs
+// which are too long or hold unsafe characters are replaced
+func inboundRequestID(gc *gin.Context) string {
+	requestID := gc.GetHeader(constants.HeaderRequestId)
+	if requestid.IsValid(requestID) {
+		return requestID
+	}
+
+	if requestID != "" {
+		pkgLogger.Ctx(gc.Request.Context()).
+			WithField("length", len(requestID)).
+			Warn("INVALID_REQUEST_ID")
+	}
+
+	// the invalid traceparent headers are ignored by the propagator
+	spanCtx := trace.SpanContextFromContext(
+		propagation.TraceContext{}.Extract(
+			gc.Request.Context(),
+			propagation.HeaderCarrier(gc.Request.Header),
+		),
+	)
+	if spanCtx.IsValid() && spanCtx.IsRemote() {
+		return spanCtx.TraceID().String()
+	}
+
+	return requestid.New()
+}
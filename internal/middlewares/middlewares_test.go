@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
+)
+
+func TestCtxMiddlewareRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CtxMiddleware())
+	var seen string
+	r.GET("/v1/images", func(gc *gin.Context) {
+		seen = requestid.FromContext(gc.Request.Context())
+		gc.Status(http.StatusOK)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name        string
+		requestID   string
+		traceparent string
+		want        string
+	}{
+		{name: "valid request id", requestID: "req_1", traceparent: "00-" + traceID + "-00f067aa0ba902b7-01", want: "req_1"},
+		{name: "trace id of the traceparent", traceparent: "00-" + traceID + "-00f067aa0ba902b7-01", want: traceID},
+		{name: "invalid request id", requestID: "req 1; drop", traceparent: "00-" + traceID + "-00f067aa0ba902b7-01", want: traceID},
+		{name: "too long request id", requestID: strings.Repeat("a", requestid.MaxLength+1)},
+		{name: "malformed traceparent", traceparent: "00-" + traceID + "-zz-01"},
+		{name: "nothing supplied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/images", nil)
+			if tt.requestID != "" {
+				req.Header.Set(constants.HeaderRequestId, tt.requestID)
+			}
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(constants.HeaderRequestId)
+			if echoed != seen {
+				t.Errorf("expected the request ID of the context %q echoed, got %q", seen, echoed)
+			}
+			if tt.want != "" && seen != tt.want {
+				t.Errorf("expected the request ID %q, got %q", tt.want, seen)
+			}
+			if tt.want == "" && (!requestid.IsValid(seen) || seen == tt.requestID || seen == traceID) {
+				t.Errorf("expected a generated request ID, got %q", seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package middlewares
+
+import (
+	"bytes"
+	goerr "errors"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// OpenAPIValidationMiddleware validates the requests and the responses of the
+// routes described by the document. Invalid requests are rejected with the same
+// problems as the handlers, invalid responses are logged as they are already sent.
+// Authentication is left to AuthMiddleware.
+func OpenAPIValidationMiddleware(spec *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+		MultiError:            true,
+	}
+
+	return func(gc *gin.Context) {
+		ctx := gc.Request.Context()
+		logger := pkgLogger.Ctx(ctx)
+
+		route, pathParams, findErr := router.FindRoute(gc.Request)
+		if findErr != nil {
+			// the routes missing in the document are left to the router
+			gc.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    gc.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+
+		if validationErr := openapi3filter.ValidateRequest(ctx, input); validationErr != nil {
+			iErr := openAPIRequestError(validationErr)
+			logger.WithError(iErr).Warn("OPENAPI_REQUEST_INVALID")
+			ErrorResponse(gc, iErr)
+			gc.Abort()
+			return
+		}
+
+		// streams are never complete, so their responses are not validated
+		if isStream(route) {
+			gc.Next()
+			return
+		}
+
+		writer := &responseCaptureWriter{ResponseWriter: gc.Writer, body: &bytes.Buffer{}}
+		gc.Writer = writer
+
+		gc.Next()
+
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Options:                options,
+		}
+		responseInput.SetBodyBytes(writer.body.Bytes())
+
+		if validationErr := openapi3filter.ValidateResponse(ctx, responseInput); validationErr != nil {
+			logger.WithError(validationErr).
+				WithField("status", writer.Status()).
+				Error("OPENAPI_RESPONSE_INVALID")
+		}
+	}, nil
+}
+
+// openAPIRequestError converts the validation error to the error returned
+// by the handlers, malformed bodies are bad requests while the values not
+// matching the document are validation failures with field level details
+func openAPIRequestError(err error) errors.IError {
+	var parseErr *openapi3filter.ParseError
+	if goerr.As(err, &parseErr) {
+		return errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(err)
+	}
+
+	return errors.NewUnprocessableError(internaErr.ValidationFailure).
+		Wrap(err).
+		WithDetails(openAPIFieldErrors(err)...)
+}
+
+// openAPIFieldErrors flattens the validation errors into field level details
+func openAPIFieldErrors(err error) []errors.FieldError {
+	var details []errors.FieldError
+
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			details = append(details, openAPIFieldErrors(inner)...)
+		}
+
+	case *openapi3filter.RequestError:
+		if e.Parameter == nil {
+			return openAPIFieldErrors(e.Err)
+		}
+
+		message := e.Reason
+		var schemaErr *openapi3.SchemaError
+		if goerr.As(e.Err, &schemaErr) {
+			message = schemaErr.Reason
+		}
+
+		details = append(details, errors.FieldError{
+			Field:   e.Parameter.Name,
+			Message: message,
+		})
+
+	case *openapi3.SchemaError:
+		details = append(details, errors.FieldError{
+			Field:   strings.Join(e.JSONPointer(), "."),
+			Message: e.Reason,
+		})
+	}
+
+	return details
+}
+
+// isStream checks if the successful response of the route is an event stream
+func isStream(route *routers.Route) bool {
+	if route.Operation == nil {
+		return false
+	}
+
+	response := route.Operation.Responses.Status(http.StatusOK)
+	return response != nil && response.Value.Content.Get(sse.ContentType) != nil
+}
@@ -0,0 +1,90 @@
+package middlewares
+
+import (
+	"net/http"
+
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+const (
+	// ContentTypeProblemJSON is the media type of RFC 7807 responses
+	ContentTypeProblemJSON = "application/problem+json"
+
+	// problemTypeDefault is used as the problem type as the
+	// title of every problem is the status text of the response
+	problemTypeDefault = "about:blank"
+
+	serverErrorDetail = "Something went wrong, please try again in some time."
+)
+
+// Problem is the RFC 7807 problem details body of an error response
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []errors.FieldError `json:"errors,omitempty"`
+}
+
+// problemClass holds the response status and the stable code of an error type
+type problemClass struct {
+	status int
+	code   string
+}
+
+var problemClasses = map[errors.ErrorType]problemClass{
+	errors.BAD_REQUEST_ERROR:     {http.StatusBadRequest, internaErr.BadRequesterror},
+	errors.AUTHORIZATION_ERROR:   {http.StatusUnauthorized, internaErr.Unauthorized},
+	errors.FORBIDDEN_ERROR:       {http.StatusForbidden, internaErr.Forbidden},
+	errors.NOT_FOUND_ERROR:       {http.StatusNotFound, internaErr.NotFound},
+	errors.CONFLICT_ERROR:        {http.StatusConflict, internaErr.Conflict},
+	errors.UNPROCESSABLE_ERROR:   {http.StatusUnprocessableEntity, internaErr.Unprocessable},
+	errors.RATE_LIMITED_ERROR:    {http.StatusTooManyRequests, internaErr.RateLimited},
+	errors.UNAVAILABLE_ERROR:     {http.StatusServiceUnavailable, internaErr.Unavailable},
+	errors.NOT_IMPLEMENTED_ERROR: {http.StatusNotImplemented, internaErr.NotImplemented},
+	errors.INTERNAL_SERVER_ERROR: {http.StatusInternalServerError, internaErr.ServerError},
+}
+
+// NewProblem builds the problem details for the given error.
+// Details of server errors are never exposed to the caller.
+func NewProblem(r *http.Request, err error) *Problem {
+	class, detail, fieldErrors := classifyError(err)
+
+	return &Problem{
+		Type:     problemTypeDefault,
+		Title:    http.StatusText(class.status),
+		Status:   class.status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     class.code,
+		RequestID: contextkey.GetFromFromCtx(
+			r.Context(), contextkey.RequestID),
+		Errors: fieldErrors,
+	}
+}
+
+// classifyError returns the class, the detail and the field errors
+// of the error as exposed to the caller
+func classifyError(err error) (problemClass, string, []errors.FieldError) {
+	class := problemClasses[errors.INTERNAL_SERVER_ERROR]
+	detail := serverErrorDetail
+	var fieldErrors []errors.FieldError
+
+	if iErr, ok := err.(errors.IError); ok {
+		if c, ok := problemClasses[iErr.GetType()]; ok {
+			class = c
+		}
+
+		if class.status < http.StatusInternalServerError {
+			detail = iErr.Error()
+			fieldErrors = iErr.Details()
+		}
+	}
+
+	return class, detail, fieldErrors
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"encoding/json"
+	goerr "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{errors.NewBadRequestError("bad_input"), http.StatusBadRequest, internaErr.BadRequesterror, "bad_input"},
+		{errors.NewAuthorizationError("no_user"), http.StatusUnauthorized, internaErr.Unauthorized, "no_user"},
+		{errors.NewForbiddenError("not_owner"), http.StatusForbidden, internaErr.Forbidden, "not_owner"},
+		{errors.NewNotFoundError("missing"), http.StatusNotFound, internaErr.NotFound, "missing"},
+		{errors.NewConflictError("duplicate"), http.StatusConflict, internaErr.Conflict, "duplicate"},
+		{errors.NewUnprocessableError("invalid"), http.StatusUnprocessableEntity, internaErr.Unprocessable, "invalid"},
+		{errors.NewRateLimitedError("slow_down"), http.StatusTooManyRequests, internaErr.RateLimited, "slow_down"},
+		{errors.NewUnavailableError("db_down"), http.StatusServiceUnavailable, internaErr.Unavailable, serverErrorDetail},
+		{errors.NewServerError("db_error"), http.StatusInternalServerError, internaErr.ServerError, serverErrorDetail},
+		// the errors outside of the taxonomy are server errors
+		{goerr.New("connection refused"), http.StatusInternalServerError, internaErr.ServerError, serverErrorDetail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			problem := NewProblem(httptest.NewRequest(http.MethodGet, "/v1/images/1", nil), tt.err)
+
+			if problem.Status != tt.status || problem.Code != tt.code || problem.Detail != tt.detail {
+				t.Errorf("unexpected problem: %+v", problem)
+			}
+			if problem.Title != http.StatusText(tt.status) || problem.Type != problemTypeDefault || problem.Instance != "/v1/images/1" {
+				t.Errorf("unexpected problem: %+v", problem)
+			}
+		})
+	}
+}
+
+func TestNewProblemHidesServerErrorDetails(t *testing.T) {
+	err := errors.NewServerError("db_error").
+		WithDetails(errors.FieldError{Field: "password", Message: "leaked"})
+
+	problem := NewProblem(httptest.NewRequest(http.MethodPost, "/v1/images", nil), err)
+	if problem.Detail != serverErrorDetail || len(problem.Errors) != 0 {
+		t.Errorf("expected the details of the server error to be hidden, got %+v", problem)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	gc, _ := gin.CreateTestContext(rec)
+	req := httptest.NewRequest(http.MethodPost, "/v1/images", nil)
+	gc.Request = req.WithContext(contextkey.SetInContext(req.Context(), contextkey.RequestID, "request-1"))
+
+	ErrorResponse(gc, errors.NewValidationError(internaErr.ValidationFailure, nil).
+		WithDetails(errors.FieldError{Field: "file_name", Message: "cannot be blank"}))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != ContentTypeProblemJSON {
+		t.Errorf("expected the problem content type, got %q", contentType)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.RequestID != "request-1" || problem.Code != internaErr.Unprocessable {
+		t.Errorf("unexpected problem: %+v", problem)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "file_name" {
+		t.Errorf("expected the field errors, got %+v", problem.Errors)
+	}
+}
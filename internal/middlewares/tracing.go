@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"github.com/danushk97/image-analyzer/internal/metrics"
+)
+
+// TracingMiddleware starts a span named after the route template for every
+// request, continuing the trace of the W3C trace context headers when present.
+// The scrapes of the metrics are not traced.
+func TracingMiddleware(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(
+		serviceName,
+		otelgin.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != metrics.Path
+		}),
+	)
+}
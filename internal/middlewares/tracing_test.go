@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/danushk97/image-analyzer/internal/metrics"
+)
+
+func TestTracingMiddlewareTraceparent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(TracingMiddleware("image-analyzer"))
+	r.GET("/v1/images/:id", func(gc *gin.Context) { gc.Status(http.StatusOK) })
+	r.GET(metrics.Path, func(gc *gin.Context) { gc.Status(http.StatusOK) })
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		spans       int
+		continued   bool
+	}{
+		{name: "valid traceparent", path: "/v1/images/image_1", traceparent: "00-" + traceID + "-00f067aa0ba902b7-01", spans: 1, continued: true},
+		{name: "malformed traceparent", path: "/v1/images/image_1", traceparent: "00-" + traceID + "-zz-01", spans: 1},
+		{name: "all zero trace id", path: "/v1/images/image_1", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", spans: 1},
+		{name: "without traceparent", path: "/v1/images/image_1", spans: 1},
+		{name: "metrics scrape", path: metrics.Path, traceparent: "00-" + traceID + "-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(recorder.Ended())
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()[before:]
+			if len(spans) != tt.spans {
+				t.Fatalf("expected %d spans, got %d", tt.spans, len(spans))
+			}
+			if tt.spans == 0 {
+				return
+			}
+
+			span := spans[0]
+			if span.Name() != "/v1/images/:id" {
+				t.Errorf("expected the span named after the route, got %q", span.Name())
+			}
+			if !span.SpanContext().TraceID().IsValid() {
+				t.Errorf("expected a valid trace id, got %v", span.SpanContext().TraceID())
+			}
+			if continued := span.SpanContext().TraceID().String() == traceID; continued != tt.continued {
+				t.Errorf("expected the trace continued to be %v, got trace %v", tt.continued, span.SpanContext().TraceID())
+			}
+			if remote := span.Parent().IsRemote(); remote != tt.continued {
+				t.Errorf("expected a remote parent to be %v, got %v", tt.continued, remote)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/twitchtv/twirp"
+
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+const (
+	// TwirpMetaCode is the meta key of the stable error code
+	TwirpMetaCode = "code"
+	// TwirpMetaRequestID is the meta key of the request ID
+	TwirpMetaRequestID = "request_id"
+	// TwirpMetaErrors is the meta key of the JSON encoded field errors
+	TwirpMetaErrors = "errors"
+
+	// twirpErrorsKey marks the requests answered with twirp errors
+	twirpErrorsKey = "twirp_errors"
+)
+
+// twirpCodes maps the response status of the problem details to the twirp
+// error code. Twirp has no equivalent of 422 and 429, callers rely on the
+// stable code in the meta which is the same as in the problem details.
+var twirpCodes = map[int]twirp.ErrorCode{
+	http.StatusBadRequest:          twirp.InvalidArgument,
+	http.StatusUnauthorized:        twirp.Unauthenticated,
+	http.StatusForbidden:           twirp.PermissionDenied,
+	http.StatusNotFound:            twirp.NotFound,
+	http.StatusConflict:            twirp.AlreadyExists,
+	http.StatusUnprocessableEntity: twirp.InvalidArgument,
+	http.StatusTooManyRequests:     twirp.ResourceExhausted,
+	http.StatusServiceUnavailable:  twirp.Unavailable,
+	http.StatusNotImplemented:      twirp.Unimplemented,
+}
+
+// TwirpErrorMiddleware makes ErrorResponse answer with twirp errors instead
+// of the problem details, it must be registered before the middlewares of the
+// twirp routes so that their errors can be decoded by the twirp clients
+func TwirpErrorMiddleware() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		gc.Set(twirpErrorsKey, true)
+		gc.Next()
+	}
+}
+
+// NewTwirpError converts the error to a twirp error with the same
+// semantics as the problem details of the REST API
+func NewTwirpError(ctx context.Context, err error) twirp.Error {
+	class, detail, fieldErrors := classifyError(err)
+
+	code, ok := twirpCodes[class.status]
+	if !ok {
+		code = twirp.Internal
+	}
+
+	// retryable conflicts are reported as aborted so that the client retries
+	if iErr, ok := err.(errors.IError); ok &&
+		iErr.IsOfType(errors.CONFLICT_ERROR) && iErr.IsRetryable() {
+		code = twirp.Aborted
+	}
+
+	twErr := twirp.NewError(code, detail).
+		WithMeta(TwirpMetaCode, class.code)
+
+	if requestID := contextkey.GetFromFromCtx(ctx, contextkey.RequestID); requestID != "" {
+		twErr = twErr.WithMeta(TwirpMetaRequestID, requestID)
+	}
+
+	if len(fieldErrors) > 0 {
+		if encoded, jsonErr := json.Marshal(fieldErrors); jsonErr == nil {
+			twErr = twErr.WithMeta(TwirpMetaErrors, string(encoded))
+		}
+	}
+
+	return twErr
+}
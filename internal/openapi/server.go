@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	"github.com/danushk97/image-analyzer/pkg/env"
+)
+
+// SpecPath is the path the document is served at
+const SpecPath = "/v1/openapi.json"
+
+// Config holds the OpenAPI configurations
+type Config struct {
+	// Validate enables the validation of the requests and the responses
+	// against the document, it is always disabled in production
+	Validate bool
+}
+
+type OpenAPIServer struct {
+	spec      []byte
+	validator gin.HandlerFunc
+}
+
+// NewServer creates a new server of the document along
+// with its validation middleware when enabled
+func NewServer(spec *openapi3.T, config Config, environment string) (*OpenAPIServer, error) {
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &OpenAPIServer{spec: encoded}
+
+	if config.Validate && environment != env.EnvProd {
+		if server.validator, err = middlewares.OpenAPIValidationMiddleware(spec); err != nil {
+			return nil, err
+		}
+	}
+
+	return server, nil
+}
+
+// Validator returns the validation middleware, nil when it is disabled.
+// It is used by the API groups after their AuthMiddleware so that the
+// unauthenticated requests are rejected before their content is inspected.
+func (oas *OpenAPIServer) Validator() gin.HandlerFunc {
+	return oas.validator
+}
+
+func (oas *OpenAPIServer) SetupRoutes(r *gin.Engine) {
+	r.GET(SpecPath, oas.Spec)
+}
+
+func (oas *OpenAPIServer) Spec(gc *gin.Context) {
+	gc.Data(http.StatusOK, "application/json", oas.spec)
+}
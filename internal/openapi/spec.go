@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/gin-contrib/sse"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	imageDtos "github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	webhookDtos "github.com/danushk97/image-analyzer/internal/webhook/dtos"
+)
+
+const (
+	// Title of the API in the document
+	Title = "Image Analyzer API"
+	// Version of the API in the document
+	Version = "1.0.0"
+
+	// securitySchemeUser is the name of the scheme of the x-user-id header
+	securitySchemeUser = "user"
+
+	schemaPrefix = "#/components/schemas/"
+)
+
+// builder holds the document while the paths are added
+type builder struct {
+	doc *openapi3.T
+	err error
+}
+
+// NewSpec builds the OpenAPI document of the REST API. The schemas are
+// generated from the DTOs and the validation rules of the DTOs are
+// mirrored on them so that the document stays in sync with the handlers.
+func NewSpec() (*openapi3.T, error) {
+	b := &builder{
+		doc: &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info: &openapi3.Info{
+				Title:   Title,
+				Version: Version,
+			},
+			Paths: openapi3.NewPaths(),
+			Components: &openapi3.Components{
+				Schemas: openapi3.Schemas{},
+				SecuritySchemes: openapi3.SecuritySchemes{
+					securitySchemeUser: &openapi3.SecuritySchemeRef{
+						Value: openapi3.NewSecurityScheme().
+							WithType("apiKey").
+							WithIn(openapi3.ParameterInHeader).
+							WithName(constants.HeaderUserId),
+					},
+				},
+			},
+		},
+	}
+
+	b.schema("Problem", &middlewares.Problem{})
+	b.schema("CreateImageMetadataRequest", &imageDtos.CreateImageMetadataRequest{})
+	b.schema("ImageMetadata", &imageDtos.ImageMetadataResponse{})
+	b.schema("CreateWebhookRequest", &webhookDtos.CreateWebhookRequest{})
+	b.schema("Webhook", &webhookDtos.WebhookResponse{})
+	b.schema("WebhookDelivery", &webhookDtos.WebhookDeliveryResponse{})
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	b.mirrorValidationRules()
+
+	b.addHealthPaths()
+	b.addImagePaths()
+	b.addWebhookPaths()
+
+	if err := b.doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return b.doc, nil
+}
+
+// schema generates the component schema of the value
+func (b *builder) schema(name string, value interface{}) {
+	if b.err != nil {
+		return
+	}
+
+	ref, err := openapi3gen.NewSchemaRefForValue(value, b.doc.Components.Schemas)
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	b.doc.Components.Schemas[name] = ref
+}
+
+// ref returns a reference to the component schema
+func (b *builder) ref(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef(schemaPrefix+name, b.doc.Components.Schemas[name].Value)
+}
+
+// listOf returns the schema of the list responses of the component schema
+func (b *builder) listOf(name string) *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithPropertyRef("items", &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type:  &openapi3.Types{openapi3.TypeArray},
+				Items: b.ref(name),
+			},
+		}).
+		WithRequired([]string{"items"})
+}
+
+// mirrorValidationRules adds the rules of the Validate methods of the DTOs.
+// The generated schemas of the same type are shared, so they are replaced.
+func (b *builder) mirrorValidationRules() {
+	schemas := b.doc.Components.Schemas
+
+	createImage := schemas["CreateImageMetadataRequest"].Value
+	createImage.Required = []string{"file_name"}
+	createImage.Properties["file_name"] = openapi3.NewStringSchema().
+		WithMinLength(1).WithMaxLength(255).NewRef()
+
+	createWebhook := schemas["CreateWebhookRequest"].Value
+	createWebhook.Required = []string{"url", "events"}
+	createWebhook.Properties["url"] = openapi3.NewStringSchema().
+		WithMinLength(1).WithMaxLength(2048).WithFormat("uri").NewRef()
+	createWebhook.Properties["events"] = openapi3.NewArraySchema().
+		WithMinItems(1).
+		WithItems(openapi3.NewStringSchema().WithEnum(webhookDtos.SubscribableEvents...)).
+		NewRef()
+}
+
+func (b *builder) addHealthPaths() {
+	health := openapi3.NewOperation()
+	health.OperationID = "checkHealth"
+	health.Summary = "Reports the health of the server"
+	health.AddResponse(http.StatusOK, jsonResponse("The server is up",
+		openapi3.NewObjectSchema().WithProperty("status", openapi3.NewStringSchema()).NewRef()))
+	b.doc.AddOperation("/v1/health", http.MethodGet, health)
+
+	spec := openapi3.NewOperation()
+	spec.OperationID = "getOpenAPI"
+	spec.Summary = "Returns this document"
+	spec.AddResponse(http.StatusOK, jsonResponse("The OpenAPI document", openapi3.NewObjectSchema().NewRef()))
+	b.doc.AddOperation(SpecPath, http.MethodGet, spec)
+}
+
+func (b *builder) addImagePaths() {
+	create := b.authenticated("createImageMetadata", "Registers a new image to be uploaded")
+	create.AddParameter(idempotencyKeyParameter())
+	create.RequestBody = &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchemaRef(b.ref("CreateImageMetadataRequest")),
+	}
+	create.AddResponse(http.StatusOK, jsonResponse("The image", b.ref("ImageMetadata")))
+	b.problems(create, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.doc.AddOperation("/v1/images", http.MethodPost, create)
+
+	list := b.authenticated("listImageMetadata", "Lists the images of the caller, latest first")
+	list.AddParameter(openapi3.NewQueryParameter("count").
+		WithDescription("Number of images to return").
+		WithSchema(openapi3.NewIntegerSchema().
+			WithMin(1).WithMax(imageDtos.MaxListCount).
+			WithDefault(imageDtos.DefaultListCount)))
+	list.AddParameter(openapi3.NewQueryParameter("skip").
+		WithDescription("Number of images to skip").
+		WithSchema(openapi3.NewIntegerSchema().WithMin(0)))
+	list.AddParameter(openapi3.NewQueryParameter("status").
+		WithDescription("Only the images in the status").
+		WithSchema(openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(50)))
+	list.AddParameter(openapi3.NewQueryParameter("file_type").
+		WithDescription("Only the images of the file type").
+		WithSchema(openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(50)))
+	list.AddResponse(http.StatusOK, jsonResponse("A page of images", b.listOf("ImageMetadata").NewRef()))
+	b.problems(list, http.StatusBadRequest, http.StatusUnprocessableEntity)
+	b.doc.AddOperation("/v1/images", http.MethodGet, list)
+
+	events := b.authenticated("streamImageEvents", "Streams the status changes of the images of the caller")
+	events.AddParameter(openapi3.NewHeaderParameter("Last-Event-ID").
+		WithDescription("ID of the last event received, the stream resumes after it").
+		WithSchema(openapi3.NewStringSchema()))
+	events.AddResponse(http.StatusOK, openapi3.NewResponse().
+		WithDescription("Server-Sent Events stream").
+		WithContent(openapi3.Content{
+			sse.ContentType: openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema()),
+		}))
+	b.problems(events, http.StatusBadRequest, http.StatusNotImplemented)
+	b.doc.AddOperation("/v1/images/events", http.MethodGet, events)
+
+	get := b.authenticated("getImageMetadata", "Returns an image of the caller")
+	get.AddParameter(idParameter())
+	get.AddResponse(http.StatusOK, jsonResponse("The image", b.ref("ImageMetadata")))
+	b.problems(get, http.StatusNotFound)
+	b.doc.AddOperation("/v1/images/{id}", http.MethodGet, get)
+
+	remove := b.authenticated("deleteImageMetadata", "Removes an image of the caller")
+	remove.AddParameter(idParameter())
+	remove.AddParameter(idempotencyKeyParameter())
+	remove.AddResponse(http.StatusNoContent, openapi3.NewResponse().WithDescription("The image is removed"))
+	b.problems(remove, http.StatusNotFound)
+	b.doc.AddOperation("/v1/images/{id}", http.MethodDelete, remove)
+}
+
+func (b *builder) addWebhookPaths() {
+	create := b.authenticated("createWebhook", "Registers a webhook, the secret is returned only once")
+	create.RequestBody = &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchemaRef(b.ref("CreateWebhookRequest")),
+	}
+	create.AddResponse(http.StatusCreated, jsonResponse("The webhook", b.ref("Webhook")))
+	b.problems(create, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.doc.AddOperation("/v1/webhooks", http.MethodPost, create)
+
+	list := b.authenticated("listWebhooks", "Lists the webhooks of the caller")
+	list.AddResponse(http.StatusOK, jsonResponse("The webhooks", b.listOf("Webhook").NewRef()))
+	b.doc.AddOperation("/v1/webhooks", http.MethodGet, list)
+
+	remove := b.authenticated("deleteWebhook", "Removes a webhook and its delivery log")
+	remove.AddParameter(idParameter())
+	remove.AddParameter(idempotencyKeyParameter())
+	remove.AddResponse(http.StatusNoContent, openapi3.NewResponse().WithDescription("The webhook is removed"))
+	b.problems(remove, http.StatusNotFound)
+	b.doc.AddOperation("/v1/webhooks/{id}", http.MethodDelete, remove)
+
+	test := b.authenticated("testWebhook", "Sends a test event to the webhook right away")
+	test.AddParameter(idParameter())
+	test.AddParameter(idempotencyKeyParameter())
+	test.AddResponse(http.StatusOK, jsonResponse("The delivery of the test event", b.ref("WebhookDelivery")))
+	b.problems(test, http.StatusNotFound)
+	b.doc.AddOperation("/v1/webhooks/{id}/test", http.MethodPost, test)
+
+	deliveries := b.authenticated("listWebhookDeliveries", "Lists the latest deliveries of the webhook")
+	deliveries.AddParameter(idParameter())
+	deliveries.AddResponse(http.StatusOK, jsonResponse("The deliveries", b.listOf("WebhookDelivery").NewRef()))
+	b.problems(deliveries, http.StatusNotFound)
+	b.doc.AddOperation("/v1/webhooks/{id}/deliveries", http.MethodGet, deliveries)
+}
+
+// authenticated creates an operation requiring the x-user-id header,
+// every such operation may fail with the unauthorized and server errors
+func (b *builder) authenticated(id string, summary string) *openapi3.Operation {
+	op := openapi3.NewOperation()
+	op.OperationID = id
+	op.Summary = summary
+	op.Security = openapi3.NewSecurityRequirements().
+		With(openapi3.NewSecurityRequirement().Authenticate(securitySchemeUser))
+
+	b.problems(op, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	return op
+}
+
+// problems adds the problem details responses with the given statuses
+func (b *builder) problems(op *openapi3.Operation, statuses ...int) {
+	for _, status := range statuses {
+		op.AddResponse(status, openapi3.NewResponse().
+			WithDescription(http.StatusText(status)).
+			WithContent(openapi3.Content{
+				middlewares.ContentTypeProblemJSON: openapi3.NewMediaType().
+					WithSchemaRef(b.ref("Problem")),
+			}))
+	}
+}
+
+func jsonResponse(description string, schema *openapi3.SchemaRef) *openapi3.Response {
+	return openapi3.NewResponse().
+		WithDescription(description).
+		WithJSONSchemaRef(schema)
+}
+
+func idParameter() *openapi3.Parameter {
+	return openapi3.NewPathParameter("id").
+		WithSchema(openapi3.NewStringSchema())
+}
+
+func idempotencyKeyParameter() *openapi3.Parameter {
+	return openapi3.NewHeaderParameter(constants.HeaderIdempotencyKey).
+		WithDescription("Key under which the response is stored and replayed for the retries").
+		WithSchema(openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(255))
+}
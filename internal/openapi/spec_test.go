@@ -0,0 +1,91 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	imageDtos "github.com/danushk97/image-analyzer/internal/image_metadata/dtos"
+	webhookDtos "github.com/danushk97/image-analyzer/internal/webhook/dtos"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+// validatable is a request DTO validated by the handlers
+type validatable interface {
+	Validate() errors.IError
+}
+
+// TestSpecMirrorsValidationRules checks that the schemas of the document
+// accept and reject the same bodies as the Validate methods of the DTOs.
+// The formats of the document are advisory, they are left to the DTOs.
+func TestSpecMirrorsValidationRules(t *testing.T) {
+	spec, err := NewSpec()
+	if err != nil {
+		t.Fatalf("build spec: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		schema string
+		body   string
+		dto    func() validatable
+	}{
+		{"image", "CreateImageMetadataRequest", `{"file_name":"cat.png"}`,
+			func() validatable { return &imageDtos.CreateImageMetadataRequest{} }},
+		{"image without name", "CreateImageMetadataRequest", `{}`,
+			func() validatable { return &imageDtos.CreateImageMetadataRequest{} }},
+		{"image with empty name", "CreateImageMetadataRequest", `{"file_name":""}`,
+			func() validatable { return &imageDtos.CreateImageMetadataRequest{} }},
+		{"image with longest name", "CreateImageMetadataRequest", `{"file_name":"` + strings.Repeat("a", 255) + `"}`,
+			func() validatable { return &imageDtos.CreateImageMetadataRequest{} }},
+		{"image with too long name", "CreateImageMetadataRequest", `{"file_name":"` + strings.Repeat("a", 256) + `"}`,
+			func() validatable { return &imageDtos.CreateImageMetadataRequest{} }},
+
+		{"webhook", "CreateWebhookRequest", `{"url":"https://hooks.example.com","events":["` + constants.EventImageCreated + `"]}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }},
+		{"webhook without url", "CreateWebhookRequest", `{"events":["` + constants.EventImageCreated + `"]}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }},
+		{"webhook with too long url", "CreateWebhookRequest", `{"url":"https://hooks.example.com/` + strings.Repeat("a", 2048) + `","events":["*"]}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }},
+		{"webhook without events", "CreateWebhookRequest", `{"url":"https://hooks.example.com"}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }},
+		{"webhook with empty events", "CreateWebhookRequest", `{"url":"https://hooks.example.com","events":[]}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }},
+		{"webhook with unknown event", "CreateWebhookRequest", `{"url":"https://hooks.example.com","events":["image.unknown"]}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }},
+		{"webhook with event never emitted", "CreateWebhookRequest", `{"url":"https://hooks.example.com","events":["image.analysed"]}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }},
+	}
+
+	// every subscribable event is accepted by both
+	for _, event := range webhookDtos.SubscribableEvents {
+		tests = append(tests, struct {
+			name   string
+			schema string
+			body   string
+			dto    func() validatable
+		}{"webhook for " + event.(string), "CreateWebhookRequest", `{"url":"https://hooks.example.com","events":["` + event.(string) + `"]}`,
+			func() validatable { return &webhookDtos.CreateWebhookRequest{} }})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			dto := tt.dto()
+			if err := json.Unmarshal([]byte(tt.body), dto); err != nil {
+				t.Fatalf("decode dto: %v", err)
+			}
+
+			schemaErr := spec.Components.Schemas[tt.schema].Value.VisitJSON(value)
+			dtoErr := dto.Validate()
+
+			if (schemaErr == nil) != (dtoErr == nil) {
+				t.Errorf("the document and the DTO disagree, schema: %v, dto: %v", schemaErr, dtoErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+const (
+	EntityOutboxEvent = "outbox_events"
+)
+
+// OutboxEvent represents the outbox events table
+type OutboxEvent struct {
+	sql.Model             // Unique Event ID
+	Sequence       int64  `gorm:"->" json:"sequence"`                              // Order of the event, assigned by the database
+	AggregateType  string `gorm:"type:varchar(50);not null" json:"aggregate_type"` // Type of the changed entity (e.g., image)
+	AggregateID    string `gorm:"type:varchar(255);not null" json:"aggregate_id"`  // ID of the changed entity
+	EventType      string `gorm:"type:varchar(100);not null" json:"event_type"`    // Type of the event (e.g., image.created)
+	Payload        string `gorm:"type:text;not null" json:"payload"`               // JSON Blob of the event data
+	DispatchedAt   int64  `gorm:"not null" json:"dispatched_at"`                   // Unix time of the delivery, 0 while pending
+	Attempts       int    `gorm:"not null" json:"attempts"`                        // Number of failed deliveries
+	ClaimedUntil   int64  `gorm:"not null" json:"claimed_until"`                   // Unix time the relay dispatching the event holds it until
+	DeadLetteredAt int64  `gorm:"not null" json:"dead_lettered_at"`                // Unix time the delivery was given up after the max attempts, 0 otherwise
+	LastError      string `gorm:"type:text" json:"last_error"`                     // Error of the last failed delivery
+	RequestID      string `gorm:"type:varchar(128);not null" json:"request_id"`    // ID of the request recording the event
+	TraceParent    string `gorm:"type:varchar(55);not null" json:"trace_parent"`   // W3C traceparent of the request recording the event
+}
+
+func NewOutboxEvent() *OutboxEvent {
+	return &OutboxEvent{}
+}
+
+// GetSequence retrieves the order of the event
+func (o *OutboxEvent) GetSequence() int64 {
+	return o.Sequence
+}
+
+// GetAggregateType retrieves the type of the changed entity
+func (o *OutboxEvent) GetAggregateType() string {
+	return o.AggregateType
+}
+
+// GetAggregateID retrieves the ID of the changed entity
+func (o *OutboxEvent) GetAggregateID() string {
+	return o.AggregateID
+}
+
+// GetEventType retrieves the type of the event
+func (o *OutboxEvent) GetEventType() string {
+	return o.EventType
+}
+
+// GetPayload retrieves the event data
+func (o *OutboxEvent) GetPayload() string {
+	return o.Payload
+}
+
+// GetRequestID retrieves the ID of the request recording the event
+func (o *OutboxEvent) GetRequestID() string {
+	return o.RequestID
+}
+
+// GetTraceParent retrieves the trace of the request recording the event
+func (o *OutboxEvent) GetTraceParent() string {
+	return o.TraceParent
+}
+
+// IsDispatched returns true once the event has been delivered
+func (o *OutboxEvent) IsDispatched() bool {
+	return o.DispatchedAt != 0
+}
+
+// IsClaimed returns true while a relay is dispatching the event
+func (o *OutboxEvent) IsClaimed(now int64) bool {
+	return o.ClaimedUntil > now
+}
+
+// IsDeadLettered returns true once the delivery of the event was given up
+func (o *OutboxEvent) IsDeadLettered() bool {
+	return o.DeadLetteredAt != 0
+}
+
+// TableName returns the table name of the entity
+func (o *OutboxEvent) TableName() string {
+	return EntityOutboxEvent
+}
+
+// EntityName returns the entity name
+func (o *OutboxEvent) EntityName() string {
+	return EntityOutboxEvent
+}
+
+// SetDefaults sets the default values of the entity
+func (o *OutboxEvent) SetDefaults() errors.IError {
+	return nil
+}
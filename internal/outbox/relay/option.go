@@ -0,0 +1,72 @@
+package relay
+
+import (
+	"fmt"
+
+	outboxMemory "github.com/danushk97/image-analyzer/internal/outbox/repo/memory"
+	outboxSql "github.com/danushk97/image-analyzer/internal/outbox/repo/sql"
+	"github.com/danushk97/image-analyzer/internal/outbox/sink"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Option is an option to Relay to set
+// the dependencies and configurations
+type Option func(*Relay)
+
+// WithStorage adds the storage the outbox is read from
+func WithStorage(
+	store storage.Store,
+) Option {
+	return func(opts *Relay) {
+		switch s := store.(type) {
+		case *sql.Repo:
+			opts.Repo = outboxSql.NewRepo(s)
+		case *memory.Store:
+			opts.Repo = outboxMemory.NewRepo(s)
+		default:
+			panic(fmt.Sprintf("unsupported store: %T", store))
+		}
+	}
+}
+
+// WithSinks adds the sinks the events are delivered to
+func WithSinks(sinks ...sink.Sink) Option {
+	return func(opts *Relay) {
+		opts.sinks = append(opts.sinks, sinks...)
+	}
+}
+
+// WithConfig sets the configurations of the Relay
+func WithConfig(config Config) Option {
+	return func(opts *Relay) {
+		opts.config = config
+	}
+}
+
+// NewOptions will create a new builder Relay object and
+// apply all the options to that object and returns pointer
+// to the builder Relay
+func NewOptions(opts ...Option) *Relay {
+	r := &Relay{}
+	// Loop through each option
+	for _, op := range opts {
+		op(r)
+	}
+
+	if r.config.PollInterval <= 0 {
+		r.config.PollInterval = DefaultPollInterval
+	}
+	if r.config.BatchSize <= 0 {
+		r.config.BatchSize = DefaultBatchSize
+	}
+	if r.config.ClaimLease <= 0 {
+		r.config.ClaimLease = DefaultClaimLease
+	}
+	if r.config.MaxAttempts <= 0 {
+		r.config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return r
+}
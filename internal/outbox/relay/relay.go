@@ -0,0 +1,211 @@
+package relay
+
+import (
+	"context"
+	"time"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/internal/outbox/repo"
+	"github.com/danushk97/image-analyzer/internal/outbox/sink"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+const (
+	// DefaultPollInterval is the default time in seconds between polls of the outbox
+	DefaultPollInterval = 1
+	// DefaultBatchSize is the default number of events dispatched per poll
+	DefaultBatchSize = 100
+	// DefaultClaimLease is the default time in seconds a batch is held by the relay
+	DefaultClaimLease = 300
+	// DefaultMaxAttempts is the default number of failed deliveries
+	// after which an event is dead lettered
+	DefaultMaxAttempts = 10
+)
+
+// Config holds the relay configurations
+type Config struct {
+	// PollInterval is the time in seconds between polls of the outbox
+	PollInterval int
+	// BatchSize is the maximum number of events dispatched per poll
+	BatchSize int
+	// ClaimLease is the time in seconds the relay holds the batch it dispatches,
+	// the events are dispatched again by another relay once it expires
+	ClaimLease int
+	// MaxAttempts is the number of failed deliveries after which the event
+	// is dead lettered and the following events are dispatched
+	MaxAttempts int
+	// Sinks holds the configurations of the sinks
+	Sinks sink.Config
+}
+
+// Relay delivers the outbox events to the sinks in the order they
+// were recorded. An event is marked as dispatched only after every
+// sink acknowledged it, so the delivery is at-least-once: an event
+// can be delivered again to a sink after a failure of another sink
+// or a crash. A failed event blocks the following ones to keep the order
+// until it reaches the max attempts, then it is dead lettered and skipped.
+// The events are claimed in a short transaction and sent outside of it,
+// so the outbox is not locked while the sinks are called.
+type Relay struct {
+	Repo   repo.Repo
+	sinks  []sink.Sink
+	config Config
+}
+
+// NewRelay returns the instance of Relay with all options applied
+func NewRelay(opts ...Option) *Relay {
+	r := NewOptions(opts...)
+	return r
+}
+
+// Run dispatches the events periodically until the context is done
+func (r *Relay) Run(ctx context.Context) {
+	logger := pkgLogger.Ctx(ctx)
+	ticker := time.NewTicker(time.Duration(r.config.PollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := r.Dispatch(ctx)
+			if err != nil {
+				logger.WithError(err).Error("OUTBOX_DISPATCH_ERROR")
+			} else if count > 0 {
+				logger.WithField("count", count).Info("OUTBOX_EVENTS_DISPATCHED")
+			}
+		}
+	}
+}
+
+// Dispatch delivers one batch of pending events and
+// returns the number of events dispatched
+func (r *Relay) Dispatch(ctx context.Context) (int, errors.IError) {
+	events, err := r.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	dispatched := 0
+	for i, event := range events {
+		// the sinks are called on behalf of the request recording the event
+		eventCtx := requestid.WithContext(
+			tracing.WithTraceParent(ctx, event.GetTraceParent()),
+			event.GetRequestID(),
+		)
+
+		if deliverErr := r.deliver(eventCtx, event); deliverErr != nil {
+			event.Attempts++
+			event.LastError = deliverErr.Error()
+
+			logger := pkgLogger.Ctx(eventCtx).WithError(deliverErr).
+				WithField("event_id", event.GetID()).
+				WithField("attempts", event.Attempts)
+
+			if event.Attempts < r.config.MaxAttempts {
+				logger.Warn("OUTBOX_EVENT_DELIVERY_FAILED")
+				// the event is retried before the following ones on the next poll
+				return dispatched, r.release(ctx, events[i:])
+			}
+
+			// the event is given up so that it does not block the outbox
+			logger.Error("OUTBOX_EVENT_DEAD_LETTERED")
+			event.DeadLetteredAt = time.Now().Unix()
+			event.ClaimedUntil = 0
+			if err = r.Repo.UpdateOutboxEvent(ctx, event); err != nil {
+				return dispatched, err
+			}
+			continue
+		}
+
+		event.DispatchedAt = time.Now().Unix()
+		event.ClaimedUntil = 0
+		event.LastError = ""
+		if err = r.Repo.UpdateOutboxEvent(ctx, event); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+
+	return dispatched, nil
+}
+
+// claim leases the first pending events to the relay in a short transaction.
+// The other relays find the oldest pending event claimed and leave the outbox
+// to this one, the events whose outcome is not recorded, e.g. when the relay
+// stops, are dispatched again once the lease expires.
+func (r *Relay) claim(ctx context.Context) ([]*model.OutboxEvent, errors.IError) {
+	var claimed []*model.OutboxEvent
+
+	err := r.Repo.Transaction(ctx, func(ctx context.Context) errors.IError {
+		events, locked, err := r.Repo.LockPendingOutboxEvents(ctx, r.config.BatchSize)
+		if err != nil || !locked {
+			// another relay is claiming the events
+			return err
+		}
+
+		now := time.Now().Unix()
+		leaseUntil := now + int64(r.config.ClaimLease)
+		for _, event := range events {
+			if event.IsClaimed(now) {
+				// another relay is dispatching the events
+				break
+			}
+
+			event.ClaimedUntil = leaseUntil
+			if err = r.Repo.UpdateOutboxEvent(ctx, event); err != nil {
+				return err
+			}
+			claimed = append(claimed, event)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// release gives up the claim of the events which were not dispatched
+func (r *Relay) release(ctx context.Context, events []*model.OutboxEvent) errors.IError {
+	for _, event := range events {
+		event.ClaimedUntil = 0
+		if err := r.Repo.UpdateOutboxEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close releases the resources held by the sinks
+func (r *Relay) Close() error {
+	var err error
+	for _, s := range r.sinks {
+		if closeErr := s.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// deliver sends the event to every sink
+func (r *Relay) deliver(ctx context.Context, event *model.OutboxEvent) error {
+	for _, s := range r.sinks {
+		if err := s.Send(ctx, event); err != nil {
+			pkgLogger.Ctx(ctx).WithError(err).
+				WithField("sink", s.Name()).
+				Warn("OUTBOX_SINK_SEND_FAILED")
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package relay
+
+import (
+	"context"
+	goerr "errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/internal/outbox/repo"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+type txKey struct{}
+
+// fakeRepo holds the outbox events in memory, the relays sharing
+// it claim the events one at a time like on the database. The methods
+// the relay does not use are not expected to be called.
+type fakeRepo struct {
+	repo.Repo
+	mu     sync.Mutex
+	lock   sync.Mutex
+	events []*model.OutboxEvent
+}
+
+func (r *fakeRepo) Transaction(ctx context.Context,
+	fn func(ctx context.Context) errors.IError, _ ...transaction.Option) errors.IError {
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+func (r *fakeRepo) IsActive(ctx context.Context) bool {
+	active, _ := ctx.Value(txKey{}).(bool)
+	return active
+}
+
+func (r *fakeRepo) CreateOutboxEvent(_ context.Context, event *model.OutboxEvent) errors.IError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.Sequence = int64(len(r.events) + 1)
+	event.ID = fmt.Sprintf("event_%d", event.Sequence)
+	stored := *event
+	r.events = append(r.events, &stored)
+
+	return nil
+}
+
+func (r *fakeRepo) LockPendingOutboxEvents(
+	_ context.Context, limit int) ([]*model.OutboxEvent, bool, errors.IError) {
+	if !r.lock.TryLock() {
+		return nil, false, nil
+	}
+	defer r.lock.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var pending []*model.OutboxEvent
+	for _, e := range r.events {
+		if len(pending) == limit {
+			break
+		}
+		if !e.IsDispatched() && !e.IsDeadLettered() {
+			event := *e
+			pending = append(pending, &event)
+		}
+	}
+
+	return pending, true, nil
+}
+
+func (r *fakeRepo) UpdateOutboxEvent(_ context.Context, event *model.OutboxEvent) errors.IError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *event
+	r.events[event.Sequence-1] = &stored
+
+	return nil
+}
+
+// event returns the stored event with the sequence
+func (r *fakeRepo) event(sequence int64) model.OutboxEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return *r.events[sequence-1]
+}
+
+// fakeSink records the events sent to it, the events of the
+// aggregates in fail are rejected
+type fakeSink struct {
+	mu     sync.Mutex
+	sent   []string
+	fail   map[string]bool
+	onSend func(ctx context.Context)
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Send(ctx context.Context, event *model.OutboxEvent) error {
+	if s.onSend != nil {
+		s.onSend(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail[event.GetAggregateID()] {
+		return goerr.New("sink unavailable")
+	}
+	s.sent = append(s.sent, event.GetAggregateID())
+
+	return nil
+}
+
+func (s *fakeSink) Close() error { return nil }
+
+// newTestRelay returns a relay of a new outbox holding the events of the aggregates
+func newTestRelay(t *testing.T, s *fakeSink, config Config, aggregateIDs ...string) (*Relay, *fakeRepo) {
+	t.Helper()
+
+	outbox := &fakeRepo{}
+	r := NewRelay(WithSinks(s), WithConfig(config))
+	r.Repo = outbox
+	for _, id := range aggregateIDs {
+		event := &model.OutboxEvent{AggregateType: "image", AggregateID: id, EventType: "image.created", Payload: "{}"}
+		if err := r.Repo.CreateOutboxEvent(context.Background(), event); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+
+	return r, outbox
+}
+
+func TestDispatchInOrder(t *testing.T) {
+	s := &fakeSink{}
+	r, outbox := newTestRelay(t, s, Config{}, "image_1", "image_2", "image_3")
+
+	dispatched, err := r.Dispatch(context.Background())
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if dispatched != 3 {
+		t.Fatalf("expected 3 events dispatched, got %d", dispatched)
+	}
+	if len(s.sent) != 3 || s.sent[0] != "image_1" || s.sent[1] != "image_2" || s.sent[2] != "image_3" {
+		t.Errorf("expected the events in order, got %v", s.sent)
+	}
+
+	for sequence := int64(1); sequence <= 3; sequence++ {
+		if e := outbox.event(sequence); !e.IsDispatched() || e.ClaimedUntil != 0 {
+			t.Errorf("expected a dispatched event, got %+v", e)
+		}
+	}
+
+	if dispatched, _ = r.Dispatch(context.Background()); dispatched != 0 {
+		t.Errorf("expected no event dispatched again, got %d", dispatched)
+	}
+}
+
+func TestDispatchFailureBlocksTheFollowingEvents(t *testing.T) {
+	s := &fakeSink{fail: map[string]bool{"image_2": true}}
+	r, outbox := newTestRelay(t, s, Config{}, "image_1", "image_2", "image_3")
+
+	dispatched, err := r.Dispatch(context.Background())
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if dispatched != 1 {
+		t.Fatalf("expected 1 event dispatched, got %d", dispatched)
+	}
+
+	failed := outbox.event(2)
+	if failed.IsDispatched() || failed.Attempts != 1 || failed.LastError == "" || failed.ClaimedUntil != 0 {
+		t.Errorf("expected a released failed event, got %+v", failed)
+	}
+	if following := outbox.event(3); following.IsDispatched() || following.ClaimedUntil != 0 {
+		t.Errorf("expected the following event to be released, got %+v", following)
+	}
+
+	s.fail = nil
+	if dispatched, _ = r.Dispatch(context.Background()); dispatched != 2 {
+		t.Errorf("expected the 2 remaining events dispatched, got %d", dispatched)
+	}
+	if len(s.sent) != 3 || s.sent[1] != "image_2" || s.sent[2] != "image_3" {
+		t.Errorf("expected the events in order, got %v", s.sent)
+	}
+}
+
+func TestDispatchDeadLettersPoisonEvent(t *testing.T) {
+	s := &fakeSink{fail: map[string]bool{"image_2": true}}
+	r, outbox := newTestRelay(t, s, Config{MaxAttempts: 3}, "image_1", "image_2", "image_3")
+
+	for attempt := 1; attempt < 3; attempt++ {
+		if _, err := r.Dispatch(context.Background()); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		if e := outbox.event(2); e.Attempts != attempt || e.IsDeadLettered() {
+			t.Fatalf("expected the event to be retried, got %+v", e)
+		}
+	}
+
+	dispatched, err := r.Dispatch(context.Background())
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if dispatched != 1 {
+		t.Errorf("expected the event following the poison one dispatched, got %d", dispatched)
+	}
+
+	poison := outbox.event(2)
+	if !poison.IsDeadLettered() || poison.IsDispatched() || poison.Attempts != 3 ||
+		poison.LastError == "" || poison.ClaimedUntil != 0 {
+		t.Errorf("expected a dead lettered event, got %+v", poison)
+	}
+	if len(s.sent) != 2 || s.sent[0] != "image_1" || s.sent[1] != "image_3" {
+		t.Errorf("expected the events around the poison one, got %v", s.sent)
+	}
+
+	if dispatched, _ = r.Dispatch(context.Background()); dispatched != 0 {
+		t.Errorf("expected the dead lettered event not to be pending, %d dispatched", dispatched)
+	}
+}
+
+func TestDispatchSendsOutsideOfTheClaim(t *testing.T) {
+	s := &fakeSink{}
+	r, _ := newTestRelay(t, s, Config{}, "image_1")
+	// another relay of the same outbox
+	other := &Relay{Repo: r.Repo, sinks: nil, config: r.config}
+
+	s.onSend = func(ctx context.Context) {
+		if r.Repo.IsActive(ctx) {
+			t.Error("expected the event to be sent outside of a transaction")
+		}
+
+		done := make(chan int, 1)
+		go func() {
+			dispatched, _ := other.Dispatch(context.Background())
+			done <- dispatched
+		}()
+
+		select {
+		case dispatched := <-done:
+			if dispatched != 0 {
+				t.Errorf("expected the claimed event to be left to the relay, %d dispatched", dispatched)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("the other relay waits for the event to be sent")
+		}
+	}
+
+	if dispatched, err := r.Dispatch(context.Background()); err != nil || dispatched != 1 {
+		t.Fatalf("expected 1 event dispatched, got %d, %v", dispatched, err)
+	}
+}
+
+func TestDispatchExpiredClaim(t *testing.T) {
+	s := &fakeSink{}
+	r, outbox := newTestRelay(t, s, Config{}, "image_1")
+
+	e := outbox.event(1)
+	e.ClaimedUntil = time.Now().Unix() + 60
+	if err := r.Repo.UpdateOutboxEvent(context.Background(), &e); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	if dispatched, _ := r.Dispatch(context.Background()); dispatched != 0 {
+		t.Fatalf("expected the event claimed by another relay to be skipped, %d dispatched", dispatched)
+	}
+
+	// the relay holding the claim stopped
+	e.ClaimedUntil = time.Now().Unix() - 1
+	if err := r.Repo.UpdateOutboxEvent(context.Background(), &e); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	if dispatched, _ := r.Dispatch(context.Background()); dispatched != 1 {
+		t.Errorf("expected the event of the expired claim to be dispatched, %d dispatched", dispatched)
+	}
+}
+
+func TestDispatchOnBehalfOfTheRequest(t *testing.T) {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	s := &fakeSink{}
+	r, outbox := newTestRelay(t, s, Config{}, "image_1")
+
+	e := outbox.event(1)
+	e.RequestID = "req_1"
+	e.TraceParent = traceParent
+	if err := r.Repo.UpdateOutboxEvent(context.Background(), &e); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+
+	s.onSend = func(ctx context.Context) {
+		if got := requestid.FromContext(ctx); got != "req_1" {
+			t.Errorf("expected the request ID of the event, got %q", got)
+		}
+		if got := tracing.TraceParent(ctx); got != traceParent {
+			t.Errorf("expected the traceparent of the event, got %q", got)
+		}
+	}
+
+	if dispatched, err := r.Dispatch(context.Background()); err != nil || dispatched != 1 {
+		t.Fatalf("expected 1 event dispatched, got %d, %v", dispatched, err)
+	}
+}
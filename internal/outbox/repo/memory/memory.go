@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// MaxEvents is the number of events kept in memory, the oldest ones are
+// dropped beyond it. The dispatched events are kept as in the sql store,
+// the cap bounds the memory of the process.
+const MaxEvents = 10000
+
+// Repo is used to interact outbox events with the storage held in memory
+type Repo struct {
+	dataStore *memory.Store
+	maxEvents int
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(store *memory.Store) *Repo {
+	return &Repo{
+		dataStore: store,
+		maxEvents: MaxEvents,
+	}
+}
+
+// Transaction performs given function inside the transaction block using
+// storage transaction method
+func (r Repo) Transaction(ctx context.Context,
+	fn func(ctx context.Context) errors.IError, opts ...transaction.Option) errors.IError {
+	return r.dataStore.Transaction(ctx, fn, opts...)
+}
+
+// IsActive checks if transaction is active or not
+func (r Repo) IsActive(ctx context.Context) bool {
+	return r.dataStore.IsTransactionActive(ctx)
+}
+
+// CreateOutboxEvent stores a new event in the outbox, the sequence
+// follows the one of the last recorded event. The events older than
+// the last MaxEvents are dropped.
+func (r Repo) CreateOutboxEvent(
+	ctx context.Context,
+	event *model.OutboxEvent,
+) errors.IError {
+	err := r.Transaction(ctx, func(ctx context.Context) errors.IError {
+		var sequence int64
+		events := r.events(ctx)
+		for _, recorded := range events {
+			sequence = max(sequence, recorded.Sequence)
+		}
+
+		event.Sequence = sequence + 1
+		if err := r.dataStore.Create(ctx, event); err != nil {
+			return err
+		}
+		if len(events) < r.maxEvents {
+			return nil
+		}
+
+		_, err := r.dataStore.DeleteWhere(ctx, event, func(record sql.IModel) bool {
+			return record.(*model.OutboxEvent).Sequence <= event.Sequence-int64(r.maxEvents)
+		})
+		return err
+	})
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"OUTBOX_EVENT_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// LockPendingOutboxEvents returns the pending events in the order they were
+// recorded, the outbox is always locked as the transactions are serialized
+func (r Repo) LockPendingOutboxEvents(
+	ctx context.Context,
+	limit int,
+) ([]*model.OutboxEvent, bool, errors.IError) {
+	var events []*model.OutboxEvent
+	for _, event := range r.events(ctx) {
+		if !event.IsDispatched() {
+			events = append(events, event)
+		}
+	}
+
+	return head(events, limit), true, nil
+}
+
+// UpdateOutboxEvent stores the delivery state of the event
+func (r Repo) UpdateOutboxEvent(
+	ctx context.Context,
+	event *model.OutboxEvent,
+) errors.IError {
+	return r.dataStore.Update(
+		ctx,
+		event,
+		"dispatched_at",
+		"attempts",
+		"last_error",
+	)
+}
+
+// FindOutboxEventBySequence fetches the event with the given sequence
+func (r Repo) FindOutboxEventBySequence(
+	ctx context.Context,
+	sequence int64,
+) (*model.OutboxEvent, errors.IError) {
+	for _, event := range r.events(ctx) {
+		if event.Sequence == sequence {
+			return event, nil
+		}
+	}
+
+	return nil, memory.RecordNotFoundError()
+}
+
+// ListOutboxEventsAfter fetches the events recorded after the sequence in order,
+// the owner of the aggregate is read from the user_id of the payload
+func (r Repo) ListOutboxEventsAfter(
+	ctx context.Context,
+	aggregateType string,
+	userID string,
+	sequence int64,
+	limit int,
+) ([]*model.OutboxEvent, errors.IError) {
+	var events []*model.OutboxEvent
+	for _, event := range r.events(ctx) {
+		if event.AggregateType != aggregateType || event.Sequence <= sequence {
+			continue
+		}
+		if userID != "" && payloadUserID(event) != userID {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return head(events, limit), nil
+}
+
+// events returns all the events in the order they were recorded
+func (r Repo) events(ctx context.Context) []*model.OutboxEvent {
+	var events []*model.OutboxEvent
+	r.dataStore.Each(ctx, model.NewOutboxEvent(), func(record sql.IModel) {
+		events = append(events, record.(*model.OutboxEvent))
+	})
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Sequence < events[j].Sequence
+	})
+
+	return events
+}
+
+// payloadUserID reads the user_id of the payload, empty if it has none
+func payloadUserID(event *model.OutboxEvent) string {
+	var payload struct {
+		UserID string `json:"user_id"`
+	}
+	_ = json.Unmarshal([]byte(event.Payload), &payload)
+
+	return payload.UserID
+}
+
+// head returns the first events, a limit of 0 or less is no limit
+func head(events []*model.OutboxEvent, limit int) []*model.OutboxEvent {
+	if limit > 0 && limit < len(events) {
+		return events[:limit]
+	}
+
+	return events
+}
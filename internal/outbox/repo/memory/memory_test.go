@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+)
+
+func TestCreateOutboxEventDropsTheOldestEvents(t *testing.T) {
+	ctx := context.Background()
+	r := NewRepo(memory.NewStore())
+	r.maxEvents = 3
+
+	for i := 0; i < 5; i++ {
+		err := r.CreateOutboxEvent(ctx, &model.OutboxEvent{
+			AggregateType: "image",
+			AggregateID:   "image_1",
+			EventType:     "image.created",
+			Payload:       "{}",
+		})
+		if err != nil {
+			t.Fatalf("create event %d: %v", i, err)
+		}
+	}
+
+	events, err := r.ListOutboxEventsAfter(ctx, "image", "", 0, 0)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if len(events) != 3 || events[0].Sequence != 3 || events[2].Sequence != 5 {
+		t.Errorf("expected the last 3 events, got %+v", events)
+	}
+	if _, err = r.FindOutboxEventBySequence(ctx, 2); err == nil {
+		t.Error("expected the oldest events to be dropped")
+	}
+}
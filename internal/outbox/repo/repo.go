@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// Transactional is the interface for all task related to executing tasks
+// within transactional block
+type Transactional interface {
+	// Transaction is used to execute given function inside transaction block
+	Transaction(ctx context.Context, fn func(ctx context.Context) errors.
+		IError, opts ...transaction.Option) errors.IError
+	// IsActive checks if transaction is active or not
+	IsActive(ctx context.Context) bool
+}
+
+// Repo is the interface that is used to talk to storage layer
+// for outbox events
+type Repo interface {
+	Transactional
+
+	CreateOutboxEvent(context.Context, *model.OutboxEvent) errors.IError
+	// LockPendingOutboxEvents must be called inside a transaction, it returns
+	// false if another relay holds the outbox until the transaction ends
+	LockPendingOutboxEvents(
+		ctx context.Context, limit int) ([]*model.OutboxEvent, bool, errors.IError)
+	UpdateOutboxEvent(context.Context, *model.OutboxEvent) errors.IError
+	FindOutboxEventBySequence(
+		ctx context.Context, sequence int64) (*model.OutboxEvent, errors.IError)
+	// ListOutboxEventsAfter returns the events of the aggregate type recorded
+	// after the sequence, the events are filtered by owner if userID is set
+	ListOutboxEventsAfter(
+		ctx context.Context,
+		aggregateType string,
+		userID string,
+		sequence int64,
+		limit int,
+	) ([]*model.OutboxEvent, errors.IError)
+}
@@ -0,0 +1,187 @@
+package sql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// outboxLockKey identifies the advisory lock held by the relay
+// which is dispatching the events
+const outboxLockKey = 7305200001
+
+// Repo is used to interact outbox events with the storage
+type Repo struct {
+	dataStore *sql.Repo
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(db *sql.Repo) *Repo {
+	return &Repo{
+		dataStore: db,
+	}
+}
+
+// InstanceWithContext returns underlying instance of gorm db
+// with the context attached to it
+func (r Repo) InstanceWithContext(ctx context.Context) *gorm.DB {
+	return r.dataStore.DBInstance(ctx).
+		WithContext(context.WithoutCancel(ctx))
+}
+
+// Transaction performs given function inside the transaction block using
+// storage transaction method
+func (r Repo) Transaction(ctx context.Context,
+	fn func(ctx context.Context) errors.IError, opts ...transaction.Option) errors.IError {
+	return r.dataStore.Transaction(ctx, fn, sql.TxOptions(opts...)...)
+}
+
+// IsActive checks if transaction is active or not
+func (r Repo) IsActive(ctx context.Context) bool {
+	return r.dataStore.IsTransactionActive(ctx)
+}
+
+// CreateOutboxEvent stores a new event in the outbox
+func (r Repo) CreateOutboxEvent(
+	ctx context.Context,
+	event *model.OutboxEvent,
+) errors.IError {
+	err := r.dataStore.Create(ctx, event)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"OUTBOX_EVENT_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// LockPendingOutboxEvents takes the transaction scoped lock of the outbox and
+// returns the pending events in the order they were recorded, the dead lettered
+// events are not pending. The lock is an advisory lock on postgres, the rows of
+// the pending events on the other dialects.
+func (r Repo) LockPendingOutboxEvents(
+	ctx context.Context,
+	limit int,
+) ([]*model.OutboxEvent, bool, errors.IError) {
+	if r.dataStore.Db.GetDialect() != sql.DialectPostgres {
+		return r.lockPendingOutboxEventRows(ctx, limit)
+	}
+
+	var locked bool
+	q := r.InstanceWithContext(ctx).
+		Raw("SELECT pg_try_advisory_xact_lock(?)", outboxLockKey).
+		Scan(&locked)
+	if err := sql.GetDBError(q); err != nil {
+		return nil, false, err
+	}
+
+	if !locked {
+		return nil, false, nil
+	}
+
+	var events []*model.OutboxEvent
+	q = r.InstanceWithContext(ctx).
+		Where("dispatched_at = 0 AND dead_lettered_at = 0").
+		Order("sequence").
+		Limit(limit).
+		Find(&events)
+	if err := sql.GetDBError(q); err != nil {
+		return nil, true, err
+	}
+
+	return events, true, nil
+}
+
+// lockPendingOutboxEventRows locks the first pending events without waiting,
+// the other relays find them locked until the claim transaction ends. SQLite
+// has no row locks, its transactions are serialized by its single connection.
+func (r Repo) lockPendingOutboxEventRows(
+	ctx context.Context,
+	limit int,
+) ([]*model.OutboxEvent, bool, errors.IError) {
+	var events []*model.OutboxEvent
+	q := r.InstanceWithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+		Where("dispatched_at = 0 AND dead_lettered_at = 0").
+		Order("sequence").
+		Limit(limit).
+		Find(&events)
+
+	err := sql.GetDBError(q)
+	if sql.IsLockNotAvailableError(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return events, true, nil
+}
+
+// UpdateOutboxEvent stores the delivery state of the event
+func (r Repo) UpdateOutboxEvent(
+	ctx context.Context,
+	event *model.OutboxEvent,
+) errors.IError {
+	return r.dataStore.Update(
+		ctx,
+		event,
+		"dispatched_at",
+		"attempts",
+		"claimed_until",
+		"dead_lettered_at",
+		"last_error",
+	)
+}
+
+// FindOutboxEventBySequence fetches the event with the given sequence
+func (r Repo) FindOutboxEventBySequence(
+	ctx context.Context,
+	sequence int64,
+) (*model.OutboxEvent, errors.IError) {
+	event := model.NewOutboxEvent()
+	q := r.InstanceWithContext(ctx).
+		Where("sequence = ?", sequence).
+		First(event)
+
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ListOutboxEventsAfter fetches the events recorded after the sequence in order,
+// the owner of the aggregate is read from the user_id of the payload
+func (r Repo) ListOutboxEventsAfter(
+	ctx context.Context,
+	aggregateType string,
+	userID string,
+	sequence int64,
+	limit int,
+) ([]*model.OutboxEvent, errors.IError) {
+	var events []*model.OutboxEvent
+	q := r.InstanceWithContext(ctx).
+		Where("aggregate_type = ? AND sequence > ?", aggregateType, sequence)
+	if userID != "" {
+		q = q.Where(r.dataStore.Db.JSONField("payload", "user_id")+" = ?", userID)
+	}
+
+	q = q.Order("sequence").
+		Limit(limit).
+		Find(&events)
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
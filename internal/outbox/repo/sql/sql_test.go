@@ -0,0 +1,123 @@
+package sql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql/sqltest"
+)
+
+// lockQueries are the queries locking the first pending events without waiting
+var lockQueries = map[string]string{
+	sql.DialectPostgres: `SELECT * FROM "outbox_events" WHERE dispatched_at = 0 AND dead_lettered_at = 0 ORDER BY sequence LIMIT $1 FOR UPDATE NOWAIT`,
+	sql.DialectMySQL:    "SELECT * FROM `outbox_events` WHERE dispatched_at = 0 AND dead_lettered_at = 0 ORDER BY sequence LIMIT ? FOR UPDATE NOWAIT",
+}
+
+// lockNotAvailableErrors are the errors of the locks held by another transaction
+var lockNotAvailableErrors = map[string]error{
+	sql.DialectPostgres: &pgconn.PgError{Code: "55P03"},
+	sql.DialectMySQL:    &mysql.MySQLError{Number: 3572},
+}
+
+func TestLockPendingOutboxEventRows(t *testing.T) {
+	for _, dialect := range sqltest.Dialects {
+		t.Run(dialect, func(t *testing.T) {
+			store, mock := sqltest.NewRepo(t, dialect)
+			mock.ExpectQuery(regexp.QuoteMeta(lockQueries[dialect])).
+				WithArgs(10).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "sequence", "event_type"}).
+					AddRow("event-1", 1, "image.created").
+					AddRow("event-2", 2, "image.deleted"))
+
+			events, locked, err := NewRepo(store).lockPendingOutboxEventRows(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("lock events: %v", err)
+			}
+			if !locked || len(events) != 2 || events[0].GetSequence() != 1 || events[1].GetEventType() != "image.deleted" {
+				t.Errorf("unexpected events: %v %+v", locked, events)
+			}
+		})
+	}
+}
+
+func TestLockPendingOutboxEventRowsHeldByAnotherRelay(t *testing.T) {
+	for _, dialect := range sqltest.Dialects {
+		t.Run(dialect, func(t *testing.T) {
+			store, mock := sqltest.NewRepo(t, dialect)
+			mock.ExpectQuery(regexp.QuoteMeta(lockQueries[dialect])).
+				WillReturnError(lockNotAvailableErrors[dialect])
+
+			events, locked, err := NewRepo(store).lockPendingOutboxEventRows(context.Background(), 10)
+			if err != nil || locked || len(events) != 0 {
+				t.Errorf("expected the outbox to be held by another relay, got %v %v %+v", err, locked, events)
+			}
+		})
+	}
+}
+
+func TestLockPendingOutboxEvents(t *testing.T) {
+	t.Run(sql.DialectPostgres, func(t *testing.T) {
+		store, mock := sqltest.NewRepo(t, sql.DialectPostgres)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1)`)).
+			WithArgs(outboxLockKey).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox_events" WHERE dispatched_at = 0 AND dead_lettered_at = 0 ORDER BY sequence LIMIT $1`)).
+			WithArgs(10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "sequence"}).AddRow("event-1", 1))
+
+		events, locked, err := NewRepo(store).LockPendingOutboxEvents(context.Background(), 10)
+		if err != nil || !locked || len(events) != 1 {
+			t.Errorf("unexpected events: %v %v %+v", err, locked, events)
+		}
+	})
+
+	t.Run(sql.DialectPostgres+" held by another relay", func(t *testing.T) {
+		store, mock := sqltest.NewRepo(t, sql.DialectPostgres)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+
+		events, locked, err := NewRepo(store).LockPendingOutboxEvents(context.Background(), 10)
+		if err != nil || locked || len(events) != 0 {
+			t.Errorf("expected the outbox to be held by another relay, got %v %v %+v", err, locked, events)
+		}
+	})
+
+	// the rows are locked on the other dialects
+	t.Run(sql.DialectMySQL, func(t *testing.T) {
+		store, mock := sqltest.NewRepo(t, sql.DialectMySQL)
+		mock.ExpectQuery(regexp.QuoteMeta(lockQueries[sql.DialectMySQL])).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "sequence"}).AddRow("event-1", 1))
+
+		events, locked, err := NewRepo(store).LockPendingOutboxEvents(context.Background(), 10)
+		if err != nil || !locked || len(events) != 1 {
+			t.Errorf("unexpected events: %v %v %+v", err, locked, events)
+		}
+	})
+}
+
+func TestListOutboxEventsAfterOfTheUser(t *testing.T) {
+	queries := map[string]string{
+		sql.DialectPostgres: `SELECT * FROM "outbox_events" WHERE (aggregate_type = $1 AND sequence > $2) AND payload::jsonb ->> 'user_id' = $3 ORDER BY sequence LIMIT $4`,
+		sql.DialectMySQL:    "SELECT * FROM `outbox_events` WHERE (aggregate_type = ? AND sequence > ?) AND JSON_UNQUOTE(JSON_EXTRACT(payload, '$.user_id')) = ? ORDER BY sequence LIMIT ?",
+	}
+
+	for _, dialect := range sqltest.Dialects {
+		t.Run(dialect, func(t *testing.T) {
+			store, mock := sqltest.NewRepo(t, dialect)
+			mock.ExpectQuery(regexp.QuoteMeta(queries[dialect])).
+				WithArgs("image", 5, "user-1", 50).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "sequence"}).AddRow("event-6", 6))
+
+			events, err := NewRepo(store).ListOutboxEventsAfter(context.Background(), "image", "user-1", 5, 50)
+			if err != nil || len(events) != 1 || events[0].GetSequence() != 6 {
+				t.Errorf("unexpected events: %v %+v", err, events)
+			}
+		})
+	}
+}
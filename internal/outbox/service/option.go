@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+
+	outboxMemory "github.com/danushk97/image-analyzer/internal/outbox/repo/memory"
+	outboxSql "github.com/danushk97/image-analyzer/internal/outbox/repo/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Option is an option to outbox Service to set
+// the dependencies and configurations
+type Option func(*Service)
+
+// WithStorage adds the storage being used for outbox events
+func WithStorage(
+	store storage.Store,
+) Option {
+	return func(opts *Service) {
+		switch s := store.(type) {
+		case *sql.Repo:
+			opts.Repo = outboxSql.NewRepo(s)
+		case *memory.Store:
+			opts.Repo = outboxMemory.NewRepo(s)
+		default:
+			panic(fmt.Sprintf("unsupported store: %T", store))
+		}
+	}
+}
+
+// NewOptions will create a new builder Service object and
+// apply all the options to that object and returns pointer
+// to the builder Service
+func NewOptions(opts ...Option) *Service {
+	s := &Service{}
+	// Loop through each option
+	for _, op := range opts {
+		op(s)
+	}
+
+	return s
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/internal/outbox/repo"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+// Service records the domain events in the outbox
+type Service struct {
+	Repo repo.Repo
+}
+
+// NewService returns the instance of Service with all options applied
+func NewService(opts ...Option) *Service {
+	svc := NewOptions(opts...)
+	return svc
+}
+
+// Record stores the event in the outbox. It must be called inside the
+// transaction which changes the aggregate so that the event is stored
+// if and only if the change is committed.
+func (s *Service) Record(
+	ctx context.Context,
+	aggregateType string,
+	aggregateID string,
+	eventType string,
+	payload interface{},
+) (err errors.IError) {
+	ctx, span := tracing.Start(ctx, "OutboxService.Record")
+	defer func() { tracing.End(span, err) }()
+
+	if !s.Repo.IsActive(ctx) {
+		return errors.NewServerError(internalErr.OutboxTransactionRequired)
+	}
+
+	data, jsonErr := json.Marshal(payload)
+	if jsonErr != nil {
+		return errors.NewServerError(internalErr.OutboxPayloadInvalid).Wrap(jsonErr)
+	}
+
+	return s.Repo.CreateOutboxEvent(ctx, &model.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       string(data),
+		RequestID:     requestid.FromContext(ctx),
+		TraceParent:   tracing.TraceParent(ctx),
+	})
+}
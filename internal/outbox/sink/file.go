@@ -0,0 +1,59 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+)
+
+// FileConfig holds the configurations of the file sink
+type FileConfig struct {
+	// Path of the file the events are appended to
+	Path string
+}
+
+// FileSink appends the events to a file as JSON lines
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens the file of the sink, creating it if needed
+func NewFileSink(config FileConfig) (*FileSink, error) {
+	file, err := os.OpenFile(config.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Name identifies the sink in the logs
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Send appends the event to the file and flushes it to the disk
+func (s *FileSink) Send(ctx context.Context, event *model.OutboxEvent) error {
+	line, err := json.Marshal(NewMessage(event))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err = s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,207 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+)
+
+const (
+	// DefaultNATSSubject is the default prefix of the subjects events are published on
+	DefaultNATSSubject = "image-analyzer.events"
+	// DefaultNATSTimeout is the default time in seconds to wait for the server
+	DefaultNATSTimeout = 5
+
+	natsClientName = "image-analyzer-relay"
+)
+
+// NATSConfig holds the configurations of the NATS sink
+type NATSConfig struct {
+	// Address of the server as host:port
+	Address string
+	// Subject is the prefix of the subject, the event type is appended to it
+	Subject string
+	// Token is the optional authentication token of the server
+	Token string
+	// Timeout is the time in seconds to wait for the server
+	Timeout int
+}
+
+// NATSSink publishes the events to any server speaking the NATS client
+// protocol. Every publish is followed by a PING so that the event is
+// acknowledged only once the server processed it.
+type NATSSink struct {
+	config NATSConfig
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// natsConnectOptions is the payload of the CONNECT message
+type natsConnectOptions struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// NewNATSSink creates a new NATS sink, the connection
+// is established on the first event
+func NewNATSSink(config NATSConfig) *NATSSink {
+	if config.Subject == "" {
+		config.Subject = DefaultNATSSubject
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultNATSTimeout
+	}
+
+	return &NATSSink{config: config}
+}
+
+// Name identifies the sink in the logs
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+// Send publishes the event on <subject>.<event type>
+func (s *NATSSink) Send(ctx context.Context, event *model.OutboxEvent) error {
+	payload, err := json.Marshal(NewMessage(event))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.publish(ctx, s.config.Subject+"."+event.GetEventType(), payload); err != nil {
+		// the connection is re-established for the next event
+		s.closeConn()
+		return err
+	}
+
+	return nil
+}
+
+// Close closes the connection to the server
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closeConn()
+}
+
+func (s *NATSSink) publish(ctx context.Context, subject string, payload []byte) error {
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := s.conn.SetDeadline(s.deadline(ctx)); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	return s.waitForPong()
+}
+
+func (s *NATSSink) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: time.Duration(s.config.Timeout) * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", s.config.Address)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+
+	if err = s.conn.SetDeadline(s.deadline(ctx)); err != nil {
+		return err
+	}
+
+	// the server greets every client with its INFO
+	line, err := s.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		return fmt.Errorf("unexpected nats greeting: %q", line)
+	}
+
+	options, err := json.Marshal(natsConnectOptions{
+		Name:      natsClientName,
+		AuthToken: s.config.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.conn.Write([]byte(fmt.Sprintf("CONNECT %s\r\nPING\r\n", options))); err != nil {
+		return err
+	}
+
+	return s.waitForPong()
+}
+
+// waitForPong reads the server messages until the PONG of the last PING
+func (s *NATSSink) waitForPong() error {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err = s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (s *NATSSink) readLine() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (s *NATSSink) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(time.Duration(s.config.Timeout) * time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+
+	return deadline
+}
+
+func (s *NATSSink) closeConn() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	s.reader = nil
+
+	return err
+}
@@ -0,0 +1,173 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+)
+
+// natsPublish is a message received by the fake server
+type natsPublish struct {
+	subject string
+	payload []byte
+}
+
+// fakeNATSServer speaks enough of the NATS client protocol to accept
+// the connections and the publishes of the sink
+type fakeNATSServer struct {
+	listener  net.Listener
+	connects  chan natsConnectOptions
+	publishes chan natsPublish
+	// reject answers the publishes with an error
+	reject string
+}
+
+func newFakeNATSServer(t *testing.T) *fakeNATSServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &fakeNATSServer{
+		listener:  listener,
+		connects:  make(chan natsConnectOptions, 10),
+		publishes: make(chan natsPublish, 10),
+	}
+	go s.serve()
+
+	return s
+}
+
+func (s *fakeNATSServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeNATSServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	if _, err := conn.Write([]byte(`INFO {"server_id":"fake"}` + "\r\n")); err != nil {
+		return
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			var options natsConnectOptions
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &options)
+			s.connects <- options
+
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			if s.reject != "" {
+				_, _ = fmt.Fprintf(conn, "-ERR '%s'\r\n", s.reject)
+				continue
+			}
+			s.publishes <- natsPublish{subject: fields[1], payload: payload[:size]}
+
+		case line == "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		}
+	}
+}
+
+func TestNATSSinkSend(t *testing.T) {
+	server := newFakeNATSServer(t)
+	sink := NewNATSSink(NATSConfig{Address: server.listener.Addr().String(), Token: "secret"})
+	defer sink.Close()
+
+	event := &model.OutboxEvent{
+		AggregateType: "image",
+		AggregateID:   "image_1",
+		EventType:     "image.created",
+		Payload:       `{"id":"image_1"}`,
+	}
+	event.ID = "event-1"
+	event.Sequence = 7
+
+	for i := 0; i < 2; i++ {
+		if err := sink.Send(context.Background(), event); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	// the connection is reused by the following events
+	if options := <-server.connects; options.AuthToken != "secret" || options.Name != natsClientName {
+		t.Errorf("unexpected connect options: %+v", options)
+	}
+	if len(server.connects) != 0 {
+		t.Errorf("expected a single connection, got %d more", len(server.connects))
+	}
+
+	publish := <-server.publishes
+	if publish.subject != DefaultNATSSubject+".image.created" {
+		t.Errorf("unexpected subject %q", publish.subject)
+	}
+
+	var message Message
+	if err := json.Unmarshal(publish.payload, &message); err != nil {
+		t.Fatalf("decode message: %v", err)
+	}
+	if message.ID != "event-1" || message.Sequence != 7 || string(message.Data) != `{"id":"image_1"}` {
+		t.Errorf("unexpected message: %+v", message)
+	}
+}
+
+func TestNATSSinkSendServerError(t *testing.T) {
+	server := newFakeNATSServer(t)
+	server.reject = "Permissions Violation"
+	sink := NewNATSSink(NATSConfig{Address: server.listener.Addr().String()})
+	defer sink.Close()
+
+	err := sink.Send(context.Background(), &model.OutboxEvent{EventType: "image.deleted", Payload: "{}"})
+	if err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Fatalf("expected the server error, got %v", err)
+	}
+
+	// the failed connection is dropped to be re-established
+	if sink.conn != nil {
+		t.Error("expected the connection to be closed")
+	}
+}
+
+func TestNATSSinkSendUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	sink := NewNATSSink(NATSConfig{Address: address, Timeout: 1})
+	if err := sink.Send(context.Background(), &model.OutboxEvent{Payload: "{}"}); err == nil {
+		t.Fatal("expected an error for an unreachable server")
+	}
+}
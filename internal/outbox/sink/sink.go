@@ -0,0 +1,75 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+)
+
+// Sink delivers the outbox events to a downstream system
+type Sink interface {
+	// Name identifies the sink in the logs
+	Name() string
+	// Send delivers the event, it returns once the sink acknowledged it
+	Send(ctx context.Context, event *model.OutboxEvent) error
+	// Close releases the resources held by the sink
+	Close() error
+}
+
+// Config holds the configurations of all the sinks,
+// a sink is enabled when its destination is set
+type Config struct {
+	Webhook WebhookConfig
+	NATS    NATSConfig
+	File    FileConfig
+}
+
+// Message is the envelope of the event delivered to the sinks
+type Message struct {
+	ID            string          `json:"id"`
+	Sequence      int64           `json:"sequence"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	CreatedAt     int64           `json:"created_at"`
+	RequestID     string          `json:"request_id,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewMessage creates the envelope of the given event
+func NewMessage(event *model.OutboxEvent) *Message {
+	return &Message{
+		ID:            event.GetID(),
+		Sequence:      event.GetSequence(),
+		EventType:     event.GetEventType(),
+		AggregateType: event.GetAggregateType(),
+		AggregateID:   event.GetAggregateID(),
+		CreatedAt:     event.GetCreatedAt(),
+		RequestID:     event.GetRequestID(),
+		Data:          json.RawMessage(event.GetPayload()),
+	}
+}
+
+// New creates the sinks enabled in the config
+func New(config Config) ([]Sink, error) {
+	var sinks []Sink
+
+	if config.Webhook.URL != "" {
+		sinks = append(sinks, NewWebhookSink(config.Webhook))
+	}
+
+	if config.NATS.Address != "" {
+		sinks = append(sinks, NewNATSSink(config.NATS))
+	}
+
+	if config.File.Path != "" {
+		s, err := NewFileSink(config.File)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+
+	return sinks, nil
+}
@@ -0,0 +1,97 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
+)
+
+const (
+	// DefaultWebhookTimeout is the default time in seconds to wait for the receiver
+	DefaultWebhookTimeout = 10
+
+	headerEventID   = "X-Event-Id"
+	headerEventType = "X-Event-Type"
+)
+
+// WebhookConfig holds the configurations of the HTTP webhook sink
+type WebhookConfig struct {
+	// URL receives the events as JSON POST requests
+	URL string
+	// Timeout is the time in seconds to wait for the receiver
+	Timeout int
+}
+
+// WebhookSink posts the events to an HTTP endpoint,
+// any 2xx response acknowledges the event
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a new HTTP webhook sink
+func NewWebhookSink(config WebhookConfig) *WebhookSink {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+
+	return &WebhookSink{
+		url: config.URL,
+		client: &http.Client{
+			Timeout: time.Duration(config.Timeout) * time.Second,
+		},
+	}
+}
+
+// Name identifies the sink in the logs
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Send posts the event to the endpoint
+func (s *WebhookSink) Send(ctx context.Context, event *model.OutboxEvent) error {
+	body, err := json.Marshal(NewMessage(event))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerEventID, event.GetID())
+	req.Header.Set(headerEventType, event.GetEventType())
+	if requestID := requestid.FromContext(ctx); requestID != "" {
+		req.Header.Set(constants.HeaderRequestId, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Close releases the idle connections of the client
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
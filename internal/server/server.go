@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/danushk97/image-analyzer/internal/admin"
 	healthServer "github.com/danushk97/image-analyzer/internal/health"
 	"github.com/danushk97/image-analyzer/internal/image_metadata"
+	"github.com/danushk97/image-analyzer/internal/metrics"
 	"github.com/danushk97/image-analyzer/internal/middlewares"
+	"github.com/danushk97/image-analyzer/internal/openapi"
+	"github.com/danushk97/image-analyzer/internal/webhook"
 	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
@@ -15,14 +20,21 @@ import (
 const (
 	// DefaultHTTPAddress for HTTP server
 	DefaultHTTPAddress = "0.0.0.0:8081"
-	// DefaultShutdownTimeout is the default time allowed to shutdown server
+	// DefaultShutdownTimeout is the default time in seconds allowed to shutdown server
 	DefaultShutdownTimeout = 20
+	// DefaultServiceName is the name of the server in the traces
+	DefaultServiceName = "image-analyzer"
 )
 
 // Config holds the server configurations
 type Config struct {
+	// ShutdownTimeout is the time in seconds allowed to serve the ongoing requests on shutdown
 	ShutdownTimeout int
 	ServerAddress   string
+	ServiceName     string
+	AccessLog       middlewares.AccessLogConfig
+	// DebugLogSecret verifies the tokens enabling the debug logs of a request
+	DebugLogSecret string
 }
 
 type ServerOption func(s *Server) error
@@ -44,9 +56,19 @@ func New(ctx context.Context, config *Config) *Server {
 	if config.ShutdownTimeout <= 0 {
 		config.ShutdownTimeout = DefaultShutdownTimeout
 	}
+	if config.ServiceName == "" {
+		config.ServiceName = DefaultServiceName
+	}
 
-	router := gin.Default()
-	router.Use(middlewares.CtxMiddleware())
+	router := gin.New()
+	router.Use(
+		gin.Recovery(),
+		middlewares.TracingMiddleware(config.ServiceName),
+		middlewares.CtxMiddleware(),
+		middlewares.DebugLogMiddleware(config.DebugLogSecret),
+		middlewares.AccessLogMiddleware(config.AccessLog),
+		middlewares.MetricsMiddleware(),
+	)
 
 	logger.Info(
 		fmt.Sprintf(
@@ -79,9 +101,11 @@ func (s *Server) Run(ctx context.Context) error {
 	return s.Shutdown(ctx)
 }
 
-// Shutdown gracefully shuts down the server
+// Shutdown gracefully shuts down the server, the ongoing requests are
+// served until the shutdown timeout even when the context is done
 func (s *Server) Shutdown(ctx context.Context) error {
-	shutdownCtx, cancel := context.WithTimeout(ctx, DefaultShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(
+		context.WithoutCancel(ctx), time.Duration(s.config.ShutdownTimeout)*time.Second)
 	defer cancel()
 	return s.server.Shutdown(shutdownCtx)
 }
@@ -97,6 +121,28 @@ func (s *Server) WithOptions(opts ...ServerOption) error {
 	return nil
 }
 
+// WithOpenAPIServer serves the OpenAPI document
+func (s *Server) WithOpenAPIServer(oas *openapi.OpenAPIServer) ServerOption {
+	return func(s *Server) error {
+		oas.SetupRoutes(s.router)
+		return nil
+	}
+}
+
+func (s *Server) WithAdminServer(as *admin.AdminServer) ServerOption {
+	return func(s *Server) error {
+		as.SetupRoutes(s.router)
+		return nil
+	}
+}
+
+func (s *Server) WithMetricsServer(ms *metrics.MetricsServer) ServerOption {
+	return func(s *Server) error {
+		ms.SetupRoutes(s.router)
+		return nil
+	}
+}
+
 func (s *Server) WithHealthServer(hs *healthServer.HealthServer) ServerOption {
 	return func(s *Server) error {
 		hs.SetupRoutes(s.router)
@@ -110,3 +156,24 @@ func (s *Server) WithImageMetadataServer(is *image_metadata.ImageMetadataServer)
 		return nil
 	}
 }
+
+func (s *Server) WithImageMetadataTwirpServer(ts *image_metadata.ImageMetadataTwirpServer) ServerOption {
+	return func(s *Server) error {
+		ts.SetupRoutes(s.router)
+		return nil
+	}
+}
+
+func (s *Server) WithImageMetadataGraphQLServer(gs *image_metadata.ImageMetadataGraphQLServer) ServerOption {
+	return func(s *Server) error {
+		gs.SetupRoutes(s.router)
+		return nil
+	}
+}
+
+func (s *Server) WithWebhookServer(ws *webhook.WebhookServer) ServerOption {
+	return func(s *Server) error {
+		ws.SetupRoutes(s.router)
+		return nil
+	}
+}
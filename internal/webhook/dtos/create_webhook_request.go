@@ -0,0 +1,47 @@
+package dtos
+
+import (
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/webhook/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// SubscribableEvents are the event types a webhook can subscribe to
+var SubscribableEvents = []interface{}{
+	model.EventAll,
+	constants.EventImageCreated,
+	constants.EventImageDeleted,
+}
+
+// CreateWebhookRequest defines the structure of the create request for webhooks
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`    // Endpoint receiving the deliveries
+	Events []string `json:"events"` // Event types the webhook receives
+}
+
+func (c *CreateWebhookRequest) Validate() errors.IError {
+
+	err := validation.ValidateStruct(
+		c,
+		validation.Field(
+			&c.URL,
+			validation.Required,
+			validation.Length(1, 2048),
+			is.RequestURL,
+		),
+		validation.Field(
+			&c.Events,
+			validation.Required,
+			validation.Each(validation.In(SubscribableEvents...)),
+		),
+	)
+
+	if err != nil {
+		return errors.NewValidationError(internalErr.ValidationFailure, err)
+	}
+
+	return nil
+}
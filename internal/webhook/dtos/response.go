@@ -0,0 +1,89 @@
+package dtos
+
+import "github.com/danushk97/image-analyzer/internal/webhook/model/v1"
+
+// WebhookResponse represents the response of a webhook
+type WebhookResponse struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Active    bool     `json:"active"`
+	Secret    string   `json:"secret,omitempty"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+// WebhookResponseFromModel populates the WebhookResponse from a Webhook instance
+// the secret is only exposed when the webhook is created
+func WebhookResponseFromModel(webhook *model.Webhook, withSecret bool) *WebhookResponse {
+	r := &WebhookResponse{}
+	r.ID = webhook.GetPublicID()
+	r.URL = webhook.GetURL()
+	r.Events = webhook.GetEvents()
+	r.Active = webhook.IsActive()
+	r.CreatedAt = webhook.GetCreatedAt()
+
+	if withSecret {
+		r.Secret = webhook.GetSecret()
+	}
+
+	return r
+}
+
+// WebhookDeliveryResponse represents the response of a webhook delivery
+type WebhookDeliveryResponse struct {
+	ID            string `json:"id"`
+	WebhookID     string `json:"webhook_id"`
+	EventID       string `json:"event_id"`
+	EventType     string `json:"event_type"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	ResponseCode  int    `json:"response_code"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt int64  `json:"next_attempt_at,omitempty"`
+	DeliveredAt   int64  `json:"delivered_at,omitempty"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
+// WebhookDeliveryResponseFromModel populates the WebhookDeliveryResponse
+// from a WebhookDelivery instance
+func WebhookDeliveryResponseFromModel(delivery *model.WebhookDelivery) *WebhookDeliveryResponse {
+	r := &WebhookDeliveryResponse{}
+	r.ID = delivery.GetPublicID()
+	r.WebhookID = model.GetWebhookIDWithPrefix(delivery.GetWebhookID())
+	r.EventID = delivery.GetEventID()
+	r.EventType = delivery.GetEventType()
+	r.Status = delivery.GetStatus()
+	r.Attempts = delivery.GetAttempts()
+	r.ResponseCode = delivery.GetResponseCode()
+	r.LastError = delivery.GetLastError()
+	r.DeliveredAt = delivery.GetDeliveredAt()
+	r.CreatedAt = delivery.GetCreatedAt()
+
+	if delivery.GetStatus() == model.DeliveryStatusPending {
+		r.NextAttemptAt = delivery.GetNextAttemptAt()
+	}
+
+	return r
+}
+
+// WebhookDeliveriesResponseFromModels populates the responses of the deliveries
+func WebhookDeliveriesResponseFromModels(
+	deliveries []*model.WebhookDelivery,
+) []*WebhookDeliveryResponse {
+	r := make([]*WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		r = append(r, WebhookDeliveryResponseFromModel(delivery))
+	}
+
+	return r
+}
+
+// WebhooksResponseFromModels populates the responses of the webhooks
+func WebhooksResponseFromModels(webhooks []*model.Webhook) []*WebhookResponse {
+	r := make([]*WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		r = append(r, WebhookResponseFromModel(webhook, false))
+	}
+
+	return r
+}
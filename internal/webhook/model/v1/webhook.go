@@ -0,0 +1,111 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+const (
+	// WebhookIDPrefix ...
+	WebhookIDPrefix = "webhook_"
+
+	EntityWebhook = "webhooks"
+
+	// EventAll subscribes the webhook to all the events
+	EventAll = "*"
+
+	eventSeparator = ","
+)
+
+// Webhook represents the webhooks table
+type Webhook struct {
+	sql.Model        // Unique Webhook ID
+	UserID    string `gorm:"type:uuid;not null" json:"user_id"`      // User ID (Owner)
+	URL       string `gorm:"type:varchar(2048);not null" json:"url"` // Endpoint receiving the deliveries
+	Secret    string `gorm:"type:varchar(128);not null" json:"-"`    // Key used to sign the deliveries
+	Events    string `gorm:"type:text;not null" json:"events"`       // Comma separated event types the webhook receives
+	Active    bool   `gorm:"not null" json:"active"`                 // Deliveries are made only to active webhooks
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{}
+}
+
+// GetUserID retrieves the User ID
+func (w *Webhook) GetUserID() string {
+	return w.UserID
+}
+
+// GetURL retrieves the endpoint of the webhook
+func (w *Webhook) GetURL() string {
+	return w.URL
+}
+
+// GetSecret retrieves the signing key of the webhook
+func (w *Webhook) GetSecret() string {
+	return w.Secret
+}
+
+// GetEvents retrieves the event types the webhook receives
+func (w *Webhook) GetEvents() []string {
+	if w.Events == "" {
+		return nil
+	}
+	return strings.Split(w.Events, eventSeparator)
+}
+
+// SetEvents sets the event types the webhook receives
+func (w *Webhook) SetEvents(events []string) {
+	w.Events = strings.Join(events, eventSeparator)
+}
+
+// IsActive returns true if deliveries are made to the webhook
+func (w *Webhook) IsActive() bool {
+	return w.Active
+}
+
+// Accepts returns true if the webhook is subscribed to the event type
+func (w *Webhook) Accepts(eventType string) bool {
+	for _, event := range w.GetEvents() {
+		if event == EventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName returns the table name of the entity
+func (w *Webhook) TableName() string {
+	return EntityWebhook
+}
+
+// GetPublicID returns public id of the webhook
+func (w *Webhook) GetPublicID() string {
+	return GetWebhookIDWithPrefix(w.ID)
+}
+
+// EntityName returns the entity name
+func (w *Webhook) EntityName() string {
+	return EntityWebhook
+}
+
+// SetDefaults sets the default values of the entity
+func (w *Webhook) SetDefaults() errors.IError {
+	return nil
+}
+
+// GetWebhookIDWithPrefix adds the webhook_id prefix if does not exist
+func GetWebhookIDWithPrefix(ID string) string {
+	if strings.HasPrefix(ID, WebhookIDPrefix) {
+		return ID
+	}
+	return fmt.Sprintf("%s%s", WebhookIDPrefix, ID)
+}
+
+// GetWebhookIDWithoutPrefix removes the webhook_id prefix if exists
+func GetWebhookIDWithoutPrefix(ID string) string {
+	return strings.TrimPrefix(ID, WebhookIDPrefix)
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+const (
+	// WebhookDeliveryIDPrefix ...
+	WebhookDeliveryIDPrefix = "delivery_"
+
+	EntityWebhookDelivery = "webhook_deliveries"
+
+	DeliveryStatusPending   = "PENDING"
+	DeliveryStatusSucceeded = "SUCCEEDED"
+	DeliveryStatusFailed    = "FAILED"
+)
+
+// WebhookDelivery represents the webhook deliveries table
+type WebhookDelivery struct {
+	sql.Model            // Unique Delivery ID
+	WebhookID     string `gorm:"type:uuid;not null" json:"webhook_id"`         // Webhook the event is delivered to
+	EventID       string `gorm:"type:varchar(255);not null" json:"event_id"`   // ID of the delivered event
+	EventType     string `gorm:"type:varchar(100);not null" json:"event_type"` // Type of the delivered event
+	Payload       string `gorm:"type:text;not null" json:"payload"`            // Body posted to the webhook
+	Status        string `gorm:"type:varchar(50);not null" json:"status"`      // State (e.g., PENDING, SUCCEEDED)
+	Attempts      int    `gorm:"not null" json:"attempts"`                     // Number of attempts made
+	ResponseCode  int    `gorm:"not null" json:"response_code"`                // HTTP status of the last attempt, 0 if none received
+	LastError     string `gorm:"type:text" json:"last_error"`                  // Error of the last failed attempt
+	NextAttemptAt int64  `gorm:"not null" json:"next_attempt_at"`              // Unix time of the next attempt
+	DeliveredAt   int64  `gorm:"not null" json:"delivered_at"`                 // Unix time of the successful attempt
+	RequestID     string `gorm:"type:varchar(128);not null" json:"-"`          // ID of the request the event was recorded by
+	TraceParent   string `gorm:"type:varchar(55);not null" json:"-"`           // W3C traceparent of the request the event was recorded by
+}
+
+func NewWebhookDelivery() *WebhookDelivery {
+	return &WebhookDelivery{}
+}
+
+// GetWebhookID retrieves the ID of the webhook
+func (d *WebhookDelivery) GetWebhookID() string {
+	return d.WebhookID
+}
+
+// GetEventID retrieves the ID of the delivered event
+func (d *WebhookDelivery) GetEventID() string {
+	return d.EventID
+}
+
+// GetEventType retrieves the type of the delivered event
+func (d *WebhookDelivery) GetEventType() string {
+	return d.EventType
+}
+
+// GetPayload retrieves the body posted to the webhook
+func (d *WebhookDelivery) GetPayload() string {
+	return d.Payload
+}
+
+// GetRequestID retrieves the ID of the request the event was recorded by
+func (d *WebhookDelivery) GetRequestID() string {
+	return d.RequestID
+}
+
+// GetTraceParent retrieves the trace of the request the event was recorded by
+func (d *WebhookDelivery) GetTraceParent() string {
+	return d.TraceParent
+}
+
+// GetStatus retrieves the state of the delivery
+func (d *WebhookDelivery) GetStatus() string {
+	return d.Status
+}
+
+// GetAttempts retrieves the number of attempts made
+func (d *WebhookDelivery) GetAttempts() int {
+	return d.Attempts
+}
+
+// GetResponseCode retrieves the HTTP status of the last attempt
+func (d *WebhookDelivery) GetResponseCode() int {
+	return d.ResponseCode
+}
+
+// GetLastError retrieves the error of the last failed attempt
+func (d *WebhookDelivery) GetLastError() string {
+	return d.LastError
+}
+
+// GetNextAttemptAt retrieves the time of the next attempt
+func (d *WebhookDelivery) GetNextAttemptAt() int64 {
+	return d.NextAttemptAt
+}
+
+// GetDeliveredAt retrieves the time of the successful attempt
+func (d *WebhookDelivery) GetDeliveredAt() int64 {
+	return d.DeliveredAt
+}
+
+// TableName returns the table name of the entity
+func (d *WebhookDelivery) TableName() string {
+	return EntityWebhookDelivery
+}
+
+// GetPublicID returns public id of the delivery
+func (d *WebhookDelivery) GetPublicID() string {
+	if strings.HasPrefix(d.ID, WebhookDeliveryIDPrefix) {
+		return d.ID
+	}
+	return fmt.Sprintf("%s%s", WebhookDeliveryIDPrefix, d.ID)
+}
+
+// EntityName returns the entity name
+func (d *WebhookDelivery) EntityName() string {
+	return EntityWebhookDelivery
+}
+
+// SetDefaults sets the default values of the entity
+func (d *WebhookDelivery) SetDefaults() errors.IError {
+	if d.Status == "" {
+		d.Status = DeliveryStatusPending
+	}
+	return nil
+}
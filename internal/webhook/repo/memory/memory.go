@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danushk97/image-analyzer/internal/webhook/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// Repo is used to interact webhooks with the storage held in memory
+type Repo struct {
+	dataStore *memory.Store
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(store *memory.Store) *Repo {
+	return &Repo{
+		dataStore: store,
+	}
+}
+
+// Transaction performs given function inside the transaction block using
+// storage transaction method
+func (r Repo) Transaction(ctx context.Context,
+	fn func(ctx context.Context) errors.IError, opts ...transaction.Option) errors.IError {
+	return r.dataStore.Transaction(ctx, fn, opts...)
+}
+
+// IsActive checks if transaction is active or not
+func (r Repo) IsActive(ctx context.Context) bool {
+	return r.dataStore.IsTransactionActive(ctx)
+}
+
+// CreateWebhook stores a new webhook
+func (r Repo) CreateWebhook(
+	ctx context.Context,
+	webhook *model.Webhook,
+) errors.IError {
+	err := r.dataStore.Create(ctx, webhook)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"WEBHOOK_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindWebhookByID fetches the webhook with the given ID
+func (r Repo) FindWebhookByID(
+	ctx context.Context,
+	id string,
+) (*model.Webhook, errors.IError) {
+	webhook := model.NewWebhook()
+	if err := r.dataStore.FindByID(ctx, webhook, id); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooksByUserID fetches the webhooks owned by the user
+func (r Repo) ListWebhooksByUserID(
+	ctx context.Context,
+	userID string,
+) ([]*model.Webhook, errors.IError) {
+	var webhooks []*model.Webhook
+	r.dataStore.Each(ctx, model.NewWebhook(), func(record sql.IModel) {
+		if webhook := record.(*model.Webhook); webhook.UserID == userID {
+			webhooks = append(webhooks, webhook)
+		}
+	})
+
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt < webhooks[j].CreatedAt
+	})
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes the webhook along with its deliveries
+func (r Repo) DeleteWebhook(
+	ctx context.Context,
+	webhook *model.Webhook,
+) errors.IError {
+	return r.Transaction(ctx, func(ctx context.Context) errors.IError {
+		_, err := r.dataStore.DeleteWhere(ctx, model.NewWebhookDelivery(), func(record sql.IModel) bool {
+			return record.(*model.WebhookDelivery).WebhookID == webhook.GetID()
+		})
+		if err != nil {
+			return err
+		}
+
+		return r.dataStore.Delete(ctx, webhook)
+	})
+}
+
+// CreateWebhookDelivery stores a new delivery,
+// an event is delivered once to a webhook
+func (r Repo) CreateWebhookDelivery(
+	ctx context.Context,
+	delivery *model.WebhookDelivery,
+) errors.IError {
+	err := r.Transaction(ctx, func(ctx context.Context) errors.IError {
+		duplicate := false
+		r.dataStore.Each(ctx, model.NewWebhookDelivery(), func(record sql.IModel) {
+			stored := record.(*model.WebhookDelivery)
+			duplicate = duplicate ||
+				(stored.WebhookID == delivery.WebhookID && stored.EventID == delivery.EventID)
+		})
+		if duplicate {
+			return memory.UniqueViolationError("event_id")
+		}
+
+		return r.dataStore.Create(ctx, delivery)
+	})
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"WEBHOOK_DELIVERY_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateWebhookDelivery stores the result of the delivery attempt
+func (r Repo) UpdateWebhookDelivery(
+	ctx context.Context,
+	delivery *model.WebhookDelivery,
+) errors.IError {
+	return r.dataStore.Update(
+		ctx,
+		delivery,
+		"status",
+		"attempts",
+		"response_code",
+		"last_error",
+		"next_attempt_at",
+		"delivered_at",
+	)
+}
+
+// ListWebhookDeliveries fetches the latest deliveries of the webhook
+func (r Repo) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+) ([]*model.WebhookDelivery, errors.IError) {
+	deliveries := r.deliveries(ctx, func(delivery *model.WebhookDelivery) bool {
+		return delivery.WebhookID == webhookID
+	})
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt > deliveries[j].CreatedAt
+	})
+
+	return head(deliveries, limit), nil
+}
+
+// LockDueWebhookDeliveries returns the pending deliveries due at the given
+// time, nothing is skipped as the transactions are serialized
+func (r Repo) LockDueWebhookDeliveries(
+	ctx context.Context,
+	now int64,
+	limit int,
+) ([]*model.WebhookDelivery, errors.IError) {
+	deliveries := r.deliveries(ctx, func(delivery *model.WebhookDelivery) bool {
+		return delivery.Status == model.DeliveryStatusPending && delivery.NextAttemptAt <= now
+	})
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt < deliveries[j].NextAttemptAt
+	})
+
+	return head(deliveries, limit), nil
+}
+
+// deliveries returns the deliveries matching the function
+func (r Repo) deliveries(
+	ctx context.Context,
+	match func(delivery *model.WebhookDelivery) bool,
+) []*model.WebhookDelivery {
+	var deliveries []*model.WebhookDelivery
+	r.dataStore.Each(ctx, model.NewWebhookDelivery(), func(record sql.IModel) {
+		if delivery := record.(*model.WebhookDelivery); match(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	})
+
+	return deliveries
+}
+
+// head returns the first deliveries, a limit of 0 or less is no limit
+func head(deliveries []*model.WebhookDelivery, limit int) []*model.WebhookDelivery {
+	if limit > 0 && limit < len(deliveries) {
+		return deliveries[:limit]
+	}
+
+	return deliveries
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/danushk97/image-analyzer/internal/webhook/model/v1"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// Transactional is the interface for all task related to executing tasks
+// within transactional block
+type Transactional interface {
+	// Transaction is used to execute given function inside transaction block
+	Transaction(ctx context.Context, fn func(ctx context.Context) errors.
+		IError, opts ...transaction.Option) errors.IError
+	// IsActive checks if transaction is active or not
+	IsActive(ctx context.Context) bool
+}
+
+// Repo is the interface that is used to talk to storage layer
+// for webhooks and their deliveries
+type Repo interface {
+	Transactional
+
+	CreateWebhook(context.Context, *model.Webhook) errors.IError
+	FindWebhookByID(ctx context.Context, id string) (*model.Webhook, errors.IError)
+	ListWebhooksByUserID(ctx context.Context, userID string) ([]*model.Webhook, errors.IError)
+	DeleteWebhook(context.Context, *model.Webhook) errors.IError
+
+	CreateWebhookDelivery(context.Context, *model.WebhookDelivery) errors.IError
+	UpdateWebhookDelivery(context.Context, *model.WebhookDelivery) errors.IError
+	ListWebhookDeliveries(
+		ctx context.Context, webhookID string, limit int) ([]*model.WebhookDelivery, errors.IError)
+	// LockDueWebhookDeliveries must be called inside a transaction, the
+	// deliveries locked by other transactions are skipped
+	LockDueWebhookDeliveries(
+		ctx context.Context, now int64, limit int) ([]*model.WebhookDelivery, errors.IError)
+}
@@ -0,0 +1,183 @@
+package sql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/danushk97/image-analyzer/internal/webhook/model/v1"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// Repo is used to interact webhooks with the storage
+type Repo struct {
+	dataStore *sql.Repo
+}
+
+// NewRepo creates a new repo for interacting with storage
+func NewRepo(db *sql.Repo) *Repo {
+	return &Repo{
+		dataStore: db,
+	}
+}
+
+// InstanceWithContext returns underlying instance of gorm db
+// with the context attached to it
+func (r Repo) InstanceWithContext(ctx context.Context) *gorm.DB {
+	return r.dataStore.DBInstance(ctx).
+		WithContext(context.WithoutCancel(ctx))
+}
+
+// Transaction performs given function inside the transaction block using
+// storage transaction method
+func (r Repo) Transaction(ctx context.Context,
+	fn func(ctx context.Context) errors.IError, opts ...transaction.Option) errors.IError {
+	return r.dataStore.Transaction(ctx, fn, sql.TxOptions(opts...)...)
+}
+
+// IsActive checks if transaction is active or not
+func (r Repo) IsActive(ctx context.Context) bool {
+	return r.dataStore.IsTransactionActive(ctx)
+}
+
+// CreateWebhook stores a new webhook
+func (r Repo) CreateWebhook(
+	ctx context.Context,
+	webhook *model.Webhook,
+) errors.IError {
+	err := r.dataStore.Create(ctx, webhook)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"WEBHOOK_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindWebhookByID fetches the webhook with the given ID
+func (r Repo) FindWebhookByID(
+	ctx context.Context,
+	id string,
+) (*model.Webhook, errors.IError) {
+	webhook := model.NewWebhook()
+	if err := r.dataStore.FindByID(ctx, webhook, id); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooksByUserID fetches the webhooks owned by the user
+func (r Repo) ListWebhooksByUserID(
+	ctx context.Context,
+	userID string,
+) ([]*model.Webhook, errors.IError) {
+	var webhooks []*model.Webhook
+	q := r.InstanceWithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&webhooks)
+
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes the webhook along with its deliveries
+func (r Repo) DeleteWebhook(
+	ctx context.Context,
+	webhook *model.Webhook,
+) errors.IError {
+	return r.Transaction(ctx, func(ctx context.Context) errors.IError {
+		q := r.InstanceWithContext(ctx).
+			Where("webhook_id = ?", webhook.GetID()).
+			Delete(model.NewWebhookDelivery())
+		if err := sql.GetDBError(q); err != nil {
+			return err
+		}
+
+		return r.dataStore.Delete(ctx, webhook)
+	})
+}
+
+// CreateWebhookDelivery stores a new delivery
+func (r Repo) CreateWebhookDelivery(
+	ctx context.Context,
+	delivery *model.WebhookDelivery,
+) errors.IError {
+	err := r.dataStore.Create(ctx, delivery)
+	if err != nil {
+		pkgLogger.Ctx(ctx).WithError(err).Error(
+			"WEBHOOK_DELIVERY_CREATE_ERROR",
+		)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateWebhookDelivery stores the result of the delivery attempt
+func (r Repo) UpdateWebhookDelivery(
+	ctx context.Context,
+	delivery *model.WebhookDelivery,
+) errors.IError {
+	return r.dataStore.Update(
+		ctx,
+		delivery,
+		"status",
+		"attempts",
+		"response_code",
+		"last_error",
+		"next_attempt_at",
+		"delivered_at",
+	)
+}
+
+// ListWebhookDeliveries fetches the latest deliveries of the webhook
+func (r Repo) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+) ([]*model.WebhookDelivery, errors.IError) {
+	var deliveries []*model.WebhookDelivery
+	q := r.InstanceWithContext(ctx).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deliveries)
+
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// LockDueWebhookDeliveries locks the pending deliveries due at the given time
+func (r Repo) LockDueWebhookDeliveries(
+	ctx context.Context,
+	now int64,
+	limit int,
+) ([]*model.WebhookDelivery, errors.IError) {
+	var deliveries []*model.WebhookDelivery
+	q := r.InstanceWithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryStatusPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deliveries)
+
+	if err := sql.GetDBError(q); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
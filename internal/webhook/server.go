@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	internaErr "github.com/danushk97/image-analyzer/internal/errors"
+	idempotencyService "github.com/danushk97/image-analyzer/internal/idempotency/service"
+	"github.com/danushk97/image-analyzer/internal/middlewares"
+	"github.com/danushk97/image-analyzer/internal/webhook/dtos"
+	"github.com/danushk97/image-analyzer/internal/webhook/service"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookServer struct {
+	service     *service.Service
+	idempotency *idempotencyService.Service
+	validator   gin.HandlerFunc
+}
+
+// NewServer creates a new server
+func NewServer(
+	webhookService *service.Service,
+	idempotency *idempotencyService.Service,
+	validator gin.HandlerFunc,
+) *WebhookServer {
+	return &WebhookServer{
+		service:     webhookService,
+		idempotency: idempotency,
+		validator:   validator,
+	}
+}
+
+func (ws *WebhookServer) SetupRoutes(r *gin.Engine) {
+	webhookApi := r.Group("/v1/webhooks")
+	webhookApi.Use(middlewares.AuthMiddleware())
+	// the requests are validated once authenticated
+	if ws.validator != nil {
+		webhookApi.Use(ws.validator)
+	}
+	idempotent := middlewares.IdempotencyMiddleware(ws.idempotency)
+
+	// the created webhook carries its secret, which must not be stored
+	// with the idempotent responses
+	webhookApi.POST("", ws.Create)
+	webhookApi.GET("", ws.List)
+	webhookApi.DELETE("/:id", idempotent, ws.Delete)
+	webhookApi.POST("/:id/test", idempotent, ws.Test)
+	webhookApi.GET("/:id/deliveries", ws.ListDeliveries)
+}
+
+func (ws *WebhookServer) Create(gc *gin.Context) {
+	var err errors.IError // This will be captured by the defer function
+	fn := ws.trackRequest(gc)
+	defer func() {
+		fn(err) // The deferred function uses 'err'
+	}()
+	logger := pkgLogger.Ctx(gc.Request.Context())
+
+	requestBody := &dtos.CreateWebhookRequest{}
+
+	if bindErr := gc.ShouldBindJSON(requestBody); bindErr != nil {
+		err = errors.NewBadRequestError(internaErr.BadRequesterror).Wrap(bindErr)
+		logger.WithError(err).Error("INVALID_REQUEST")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	if err = requestBody.Validate(); err != nil {
+		logger.WithError(err).Error("VALIDATION_FAILURE")
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	webhook, err := ws.service.CreateWebhook(gc.Request.Context(), requestBody)
+	if err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	// the secret is returned only once, on creation
+	gc.JSON(http.StatusCreated, dtos.WebhookResponseFromModel(webhook, true))
+}
+
+func (ws *WebhookServer) List(gc *gin.Context) {
+	var err errors.IError
+	fn := ws.trackRequest(gc)
+	defer func() {
+		fn(err)
+	}()
+
+	webhooks, err := ws.service.ListWebhooks(gc.Request.Context())
+	if err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	gc.JSON(http.StatusOK, gin.H{
+		"items": dtos.WebhooksResponseFromModels(webhooks),
+	})
+}
+
+func (ws *WebhookServer) Delete(gc *gin.Context) {
+	var err errors.IError
+	fn := ws.trackRequest(gc)
+	defer func() {
+		fn(err)
+	}()
+
+	if err = ws.service.DeleteWebhook(gc.Request.Context(), gc.Param("id")); err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	gc.Status(http.StatusNoContent)
+}
+
+func (ws *WebhookServer) Test(gc *gin.Context) {
+	var err errors.IError
+	fn := ws.trackRequest(gc)
+	defer func() {
+		fn(err)
+	}()
+
+	delivery, err := ws.service.TestWebhook(gc.Request.Context(), gc.Param("id"))
+	if err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	gc.JSON(http.StatusOK, dtos.WebhookDeliveryResponseFromModel(delivery))
+}
+
+func (ws *WebhookServer) ListDeliveries(gc *gin.Context) {
+	var err errors.IError
+	fn := ws.trackRequest(gc)
+	defer func() {
+		fn(err)
+	}()
+
+	deliveries, err := ws.service.ListDeliveries(gc.Request.Context(), gc.Param("id"))
+	if err != nil {
+		middlewares.ErrorResponse(gc, err)
+		return
+	}
+
+	gc.JSON(http.StatusOK, gin.H{
+		"items": dtos.WebhookDeliveriesResponseFromModels(deliveries),
+	})
+}
+
+// trackRequest returns a function to be deferred by the handlers
+// that logs the latency and final status (success or failure).
+func (ws *WebhookServer) trackRequest(
+	gc *gin.Context,
+) func(err errors.IError) {
+	logger := pkgLogger.Ctx(gc.Request.Context())
+
+	// Capture the start time
+	startTime := time.Now()
+
+	// Log when action starts
+	logger.Info("ACTION_STARTED")
+
+	// Return a defer function to log final status and latency
+	return func(err errors.IError) {
+		latency := time.Since(startTime) // Calculate latency
+		if err != nil {
+			logger.WithError(err).Error(
+				fmt.Sprintf("ACTION_FAILED | latency: %v", latency),
+			)
+		} else {
+			logger.Info(
+				fmt.Sprintf("ACTION_SUCCESS | latency: %v", latency),
+			)
+		}
+	}
+}
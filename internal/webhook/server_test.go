@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	idempotencyService "github.com/danushk97/image-analyzer/internal/idempotency/service"
+	"github.com/danushk97/image-analyzer/internal/webhook/dtos"
+	"github.com/danushk97/image-analyzer/internal/webhook/service"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+)
+
+const testUserID = "8f0c5ab4-3d4e-4a4f-9b4c-7f6f2f6a9e01"
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	ws := NewServer(
+		service.NewService(
+			service.WithStorage(store),
+			// the receivers of the tests listen on the loopback
+			service.WithConfig(service.Config{AllowPrivateDestinations: true}),
+		),
+		idempotencyService.NewService(idempotencyService.WithStorage(store)),
+		nil,
+	)
+
+	r := gin.New()
+	ws.SetupRoutes(r)
+
+	return r
+}
+
+func serve(r *gin.Engine, method string, path string, body interface{}, userID string) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set(constants.HeaderUserId, userID)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func createWebhook(t *testing.T, r *gin.Engine, url string) *dtos.WebhookResponse {
+	t.Helper()
+
+	rec := serve(r, http.MethodPost, "/v1/webhooks", dtos.CreateWebhookRequest{
+		URL:    url,
+		Events: []string{constants.EventImageCreated},
+	}, testUserID)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create webhook: %d %s", rec.Code, rec.Body)
+	}
+
+	var webhook dtos.WebhookResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &webhook); err != nil {
+		t.Fatalf("decode webhook: %v", err)
+	}
+
+	return &webhook
+}
+
+func TestTestWebhook(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	deliveries := make(chan received, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(req.Body)
+		deliveries <- received{header: req.Header, body: body.Bytes()}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	r := newTestRouter()
+	webhook := createWebhook(t, r, receiver.URL)
+
+	rec := serve(r, http.MethodPost, "/v1/webhooks/"+webhook.ID+"/test", nil, testUserID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("test webhook: %d %s", rec.Code, rec.Body)
+	}
+
+	var delivery dtos.WebhookDeliveryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &delivery); err != nil {
+		t.Fatalf("decode delivery: %v", err)
+	}
+	if delivery.Status != "SUCCEEDED" || delivery.ResponseCode != http.StatusAccepted || delivery.Attempts != 1 {
+		t.Errorf("unexpected delivery: %+v", delivery)
+	}
+	if delivery.EventType != service.EventWebhookTest || delivery.WebhookID != webhook.ID {
+		t.Errorf("unexpected delivery: %+v", delivery)
+	}
+
+	got := <-deliveries
+	timestamp, _ := strconv.ParseInt(got.header.Get(service.HeaderTimestamp), 10, 64)
+	if !service.Verify(webhook.Secret, timestamp, got.body, got.header.Get(service.HeaderSignature), time.Minute, time.Now()) {
+		t.Error("expected the test delivery to be signed with the secret of the webhook")
+	}
+
+	// the test delivery is logged
+	rec = serve(r, http.MethodGet, "/v1/webhooks/"+webhook.ID+"/deliveries", nil, testUserID)
+	var log struct {
+		Items []dtos.WebhookDeliveryResponse `json:"items"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &log)
+	if rec.Code != http.StatusOK || len(log.Items) != 1 || log.Items[0].ID != delivery.ID {
+		t.Errorf("expected the delivery in the log, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestTestWebhookFailingReceiver(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	r := newTestRouter()
+	webhook := createWebhook(t, r, receiver.URL)
+
+	rec := serve(r, http.MethodPost, "/v1/webhooks/"+webhook.ID+"/test", nil, testUserID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("test webhook: %d %s", rec.Code, rec.Body)
+	}
+
+	var delivery dtos.WebhookDeliveryResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &delivery)
+	// the test deliveries are never retried
+	if delivery.Status != "FAILED" || delivery.ResponseCode != http.StatusServiceUnavailable || delivery.LastError == "" {
+		t.Errorf("unexpected delivery: %+v", delivery)
+	}
+}
+
+func TestTestWebhookOfAnotherUser(t *testing.T) {
+	r := newTestRouter()
+	webhook := createWebhook(t, r, "https://hooks.example.com/image")
+
+	rec := serve(r, http.MethodPost, "/v1/webhooks/"+webhook.ID+"/test", nil, "0b0e9b1c-7c55-4c1e-a4a8-2f3b5f1d6a77")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for the webhook of another user, got %d %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(r, http.MethodPost, "/v1/webhooks/"+webhook.ID+"/test", nil, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a user, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	goerr "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace is the range of the carrier-grade NAT, RFC 6598
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ErrForbiddenDestination is returned when a webhook targets an address
+// which is not publicly routable, e.g. the cloud metadata or internal hosts
+var ErrForbiddenDestination = goerr.New("webhook destination is not a public address")
+
+// isPublicIP checks if the address is publicly routable
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// isPublicURL checks the host of the webhook URL when it is known without
+// resolving it, i.e. an IP or localhost. The resolved addresses are
+// checked when the deliveries are made.
+func isPublicURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return isPublicIP(ip)
+	}
+
+	return true
+}
+
+// guardDestination rejects the connections to the addresses which are not
+// public, it runs once the host is resolved so that a public name can not
+// point the delivery to an internal address
+func guardDestination(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, host)
+	}
+
+	return nil
+}
+
+// newHTTPClient creates the client making the deliveries, the connections
+// to the private networks are refused unless allowed
+func newHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = guardDestination
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// a proxy would make the connections on behalf of the client
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: timeout,
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	webhookMemory "github.com/danushk97/image-analyzer/internal/webhook/repo/memory"
+	webhookSql "github.com/danushk97/image-analyzer/internal/webhook/repo/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Option is an option to webhook Service to set
+// the dependencies and configurations
+type Option func(*Service)
+
+// WithStorage adds the storage being used for webhooks
+func WithStorage(
+	store storage.Store,
+) Option {
+	return func(opts *Service) {
+		switch s := store.(type) {
+		case *sql.Repo:
+			opts.Repo = webhookSql.NewRepo(s)
+		case *memory.Store:
+			opts.Repo = webhookMemory.NewRepo(s)
+		default:
+			panic(fmt.Sprintf("unsupported store: %T", store))
+		}
+	}
+}
+
+// WithConfig sets the configurations of the Service
+func WithConfig(config Config) Option {
+	return func(opts *Service) {
+		opts.config = config
+	}
+}
+
+// WithHTTPClient sets the client used to make the deliveries
+func WithHTTPClient(client *http.Client) Option {
+	return func(opts *Service) {
+		opts.client = client
+	}
+}
+
+// NewOptions will create a new builder Service object and
+// apply all the options to that object and returns pointer
+// to the builder Service
+func NewOptions(opts ...Option) *Service {
+	s := &Service{}
+	// Loop through each option
+	for _, op := range opts {
+		op(s)
+	}
+
+	if s.config.Timeout <= 0 {
+		s.config.Timeout = DefaultTimeout
+	}
+	if s.config.MaxAttempts <= 0 {
+		s.config.MaxAttempts = DefaultMaxAttempts
+	}
+	if s.config.RetryBaseDelay <= 0 {
+		s.config.RetryBaseDelay = DefaultRetryBaseDelay
+	}
+	if s.config.RetryMaxDelay < s.config.RetryBaseDelay {
+		s.config.RetryMaxDelay = DefaultRetryMaxDelay
+	}
+	if s.config.PollInterval <= 0 {
+		s.config.PollInterval = DefaultPollInterval
+	}
+	if s.config.BatchSize <= 0 {
+		s.config.BatchSize = DefaultBatchSize
+	}
+	if s.client == nil {
+		s.client = newHTTPClient(
+			time.Duration(s.config.Timeout)*time.Second,
+			s.config.AllowPrivateDestinations,
+		)
+	}
+
+	return s
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	internalErr "github.com/danushk97/image-analyzer/internal/errors"
+	"github.com/danushk97/image-analyzer/internal/metrics"
+	"github.com/danushk97/image-analyzer/internal/webhook/dtos"
+	"github.com/danushk97/image-analyzer/internal/webhook/model/v1"
+	"github.com/danushk97/image-analyzer/internal/webhook/repo"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+	"github.com/danushk97/image-analyzer/pkg/requestid"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+const (
+	// DefaultTimeout is the default time in seconds to wait for the receiver
+	DefaultTimeout = 10
+	// DefaultMaxAttempts is the default number of attempts of a delivery
+	DefaultMaxAttempts = 8
+	// DefaultRetryBaseDelay is the default time in seconds before the first retry
+	DefaultRetryBaseDelay = 30
+	// DefaultRetryMaxDelay is the default upper bound in seconds of the delay between retries
+	DefaultRetryMaxDelay = 6 * 60 * 60
+	// DefaultPollInterval is the default time in seconds between polls of the due deliveries
+	DefaultPollInterval = 5
+	// DefaultBatchSize is the default number of deliveries attempted per poll
+	DefaultBatchSize = 50
+
+	// EventWebhookTest is the type of the event sent by the test endpoint
+	EventWebhookTest = "webhook.test"
+
+	// outcomes of the delivery attempts in the metrics
+	outcomeSucceeded = "succeeded"
+	outcomeRetrying  = "retrying"
+	outcomeFailed    = "failed"
+
+	// deliveriesLimit is the number of deliveries listed in the delivery log
+	deliveriesLimit = 100
+	// secretLength is the number of random bytes of a webhook secret
+	secretLength = 32
+	// responseErrorLimit is the number of bytes of a failed response kept in the log
+	responseErrorLimit = 512
+	// claimLeaseMargin is the time in seconds a claim outlasts the posts of its deliveries
+	claimLeaseMargin = 60
+)
+
+// Config holds the webhook configurations
+type Config struct {
+	// Timeout is the time in seconds to wait for the receiver
+	Timeout int
+	// MaxAttempts is the number of attempts after which a delivery fails
+	MaxAttempts int
+	// RetryBaseDelay is the time in seconds before the first retry,
+	// it doubles with every attempt
+	RetryBaseDelay int
+	// RetryMaxDelay is the upper bound in seconds of the delay between retries
+	RetryMaxDelay int
+	// PollInterval is the time in seconds between polls of the due deliveries
+	PollInterval int
+	// BatchSize is the maximum number of deliveries attempted per poll
+	BatchSize int
+	// AllowPrivateDestinations lets the webhooks target the loopback, private
+	// and link-local addresses, e.g. for the local development
+	AllowPrivateDestinations bool
+}
+
+// Service manages the webhooks of the users and delivers the events to them
+type Service struct {
+	Repo   repo.Repo
+	config Config
+	client *http.Client
+}
+
+// NewService returns the instance of Service with all options applied
+func NewService(opts ...Option) *Service {
+	svc := NewOptions(opts...)
+	return svc
+}
+
+// CreateWebhook registers a new webhook for the user making the request
+func (s *Service) CreateWebhook(
+	ctx context.Context,
+	req *dtos.CreateWebhookRequest,
+) (_ *model.Webhook, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.CreateWebhook")
+	defer func() { tracing.End(span, err) }()
+
+	if !s.config.AllowPrivateDestinations && !isPublicURL(req.URL) {
+		return nil, errors.NewUnprocessableError(internalErr.WebhookDestinationForbidden).
+			WithDetails(errors.FieldError{Field: "url", Message: "must be a public address"})
+	}
+
+	secret, secretErr := newSecret()
+	if secretErr != nil {
+		return nil, errors.NewServerError(internalErr.WebhookSecretError).Wrap(secretErr)
+	}
+
+	webhook := &model.Webhook{
+		UserID: contextkey.GetFromFromCtx(ctx, contextkey.UserID),
+		URL:    req.URL,
+		Secret: secret,
+		Active: true,
+	}
+	webhook.SetEvents(req.Events)
+
+	if iErr := s.Repo.CreateWebhook(ctx, webhook); iErr != nil {
+		return nil, iErr
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks returns the webhooks of the user making the request
+func (s *Service) ListWebhooks(ctx context.Context) (_ []*model.Webhook, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListWebhooks")
+	defer func() { tracing.End(span, err) }()
+
+	return s.Repo.ListWebhooksByUserID(
+		ctx,
+		contextkey.GetFromFromCtx(ctx, contextkey.UserID),
+	)
+}
+
+// GetWebhook returns the webhook if it is owned by the user making the request
+func (s *Service) GetWebhook(ctx context.Context, id string) (_ *model.Webhook, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetWebhook")
+	defer func() { tracing.End(span, err) }()
+
+	id = model.GetWebhookIDWithoutPrefix(id)
+	// malformed IDs can never match a webhook
+	if _, parseErr := uuid.Parse(id); parseErr != nil {
+		return nil, errors.NewNotFoundError(internalErr.WebhookNotFound)
+	}
+
+	webhook, err := s.Repo.FindWebhookByID(ctx, id)
+	if sql.IsRecordNotFoundError(err) {
+		return nil, errors.NewNotFoundError(internalErr.WebhookNotFound).Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// webhooks of the other users are not disclosed
+	if webhook.GetUserID() != contextkey.GetFromFromCtx(ctx, contextkey.UserID) {
+		return nil, errors.NewNotFoundError(internalErr.WebhookNotFound)
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook removes the webhook and its delivery log
+func (s *Service) DeleteWebhook(ctx context.Context, id string) (err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteWebhook")
+	defer func() { tracing.End(span, err) }()
+
+	webhook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.Repo.DeleteWebhook(ctx, webhook)
+}
+
+// ListDeliveries returns the latest deliveries of the webhook
+func (s *Service) ListDeliveries(
+	ctx context.Context,
+	id string,
+) (_ []*model.WebhookDelivery, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListDeliveries")
+	defer func() { tracing.End(span, err) }()
+
+	webhook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repo.ListWebhookDeliveries(ctx, webhook.GetID(), deliveriesLimit)
+}
+
+// TestWebhook sends a test event to the webhook right away,
+// the delivery is logged but never retried
+func (s *Service) TestWebhook(
+	ctx context.Context,
+	id string,
+) (_ *model.WebhookDelivery, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.TestWebhook")
+	defer func() { tracing.End(span, err) }()
+
+	webhook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	eventID := uuid.NewString()
+	payload, jsonErr := json.Marshal(map[string]interface{}{
+		"id":         eventID,
+		"event_type": EventWebhookTest,
+		"created_at": time.Now().Unix(),
+		"data": map[string]string{
+			"webhook_id": webhook.GetPublicID(),
+		},
+	})
+	if jsonErr != nil {
+		return nil, errors.NewServerError(internalErr.WebhookDeliveryInvalid).Wrap(jsonErr)
+	}
+
+	delivery := &model.WebhookDelivery{
+		WebhookID:     webhook.GetID(),
+		EventID:       eventID,
+		EventType:     EventWebhookTest,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now().Unix(),
+		RequestID:     requestid.FromContext(ctx),
+		TraceParent:   tracing.TraceParent(ctx),
+	}
+	if err = s.Repo.CreateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.attempt(ctx, webhook, delivery, false)
+
+	if err = s.Repo.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Enqueue schedules the delivery of the event to the webhooks of the user
+// subscribed to its type. Enqueueing the same event twice is a no-op.
+func (s *Service) Enqueue(
+	ctx context.Context,
+	userID string,
+	eventID string,
+	eventType string,
+	payload []byte,
+) (err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Enqueue")
+	defer func() { tracing.End(span, err) }()
+
+	webhooks, err := s.Repo.ListWebhooksByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, webhook := range webhooks {
+		if !webhook.IsActive() || !webhook.Accepts(eventType) {
+			continue
+		}
+
+		delivery := &model.WebhookDelivery{
+			WebhookID:     webhook.GetID(),
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+			RequestID:     requestid.FromContext(ctx),
+			TraceParent:   tracing.TraceParent(ctx),
+		}
+		// a savepoint keeps the caller's transaction usable on a conflict
+		err = s.Repo.Transaction(ctx, func(ctx context.Context) errors.IError {
+			return s.Repo.CreateWebhookDelivery(ctx, delivery)
+		})
+		// the event was already enqueued for the webhook
+		if err != nil && err.IsOfType(errors.CONFLICT_ERROR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverDue attempts the deliveries which are due and
+// returns the number of deliveries attempted
+func (s *Service) DeliverDue(ctx context.Context) (_ int, err errors.IError) {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeliverDue")
+	defer func() { tracing.End(span, err) }()
+
+	deliveries, err := s.claimDue(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// the deliveries are posted outside of the transaction claiming them
+	for attempted, delivery := range deliveries {
+		if err = s.deliver(ctx, delivery); err != nil {
+			return attempted, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// claimDue leases the due deliveries to the dispatcher in a short transaction:
+// their next attempt is pushed past the time needed to post them so that the
+// other dispatchers skip them. The deliveries whose outcome is not recorded,
+// e.g. when the dispatcher stops, are attempted again once the lease expires.
+func (s *Service) claimDue(ctx context.Context) ([]*model.WebhookDelivery, errors.IError) {
+	var claimed []*model.WebhookDelivery
+
+	err := s.Repo.Transaction(ctx, func(ctx context.Context) errors.IError {
+		now := time.Now().Unix()
+		deliveries, err := s.Repo.LockDueWebhookDeliveries(ctx, now, s.config.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		// the deliveries are posted one after the other
+		leaseUntil := now + int64(len(deliveries)*s.config.Timeout) + claimLeaseMargin
+		for _, delivery := range deliveries {
+			delivery.NextAttemptAt = leaseUntil
+			if err = s.Repo.UpdateWebhookDelivery(ctx, delivery); err != nil {
+				return err
+			}
+		}
+
+		claimed = deliveries
+		return nil
+	})
+
+	return claimed, err
+}
+
+// deliver attempts the claimed delivery and records its outcome
+func (s *Service) deliver(ctx context.Context, delivery *model.WebhookDelivery) errors.IError {
+	webhook, err := s.Repo.FindWebhookByID(ctx, delivery.GetWebhookID())
+	if err != nil && !sql.IsRecordNotFoundError(err) {
+		return err
+	}
+
+	if webhook == nil || !webhook.IsActive() {
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.LastError = "webhook is not active"
+	} else {
+		// the delivery is made on behalf of the request recording the event
+		deliveryCtx := requestid.WithContext(
+			tracing.WithTraceParent(ctx, delivery.GetTraceParent()),
+			delivery.GetRequestID(),
+		)
+		s.attempt(deliveryCtx, webhook, delivery, true)
+	}
+
+	err = s.Repo.UpdateWebhookDelivery(ctx, delivery)
+	// the delivery is deleted along with its webhook while it is posted,
+	// the other claimed deliveries are still attempted
+	if sql.IsNoRowAffectedError(err) || sql.IsRecordNotFoundError(err) {
+		pkgLogger.Ctx(ctx).WithField("delivery_id", delivery.GetPublicID()).Info("WEBHOOK_DELIVERY_DROPPED")
+		return nil
+	}
+
+	return err
+}
+
+// RunDispatcher attempts the due deliveries periodically until the context is done
+func (s *Service) RunDispatcher(ctx context.Context) {
+	logger := pkgLogger.Ctx(ctx)
+	ticker := time.NewTicker(time.Duration(s.config.PollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.DeliverDue(ctx)
+			if err != nil {
+				logger.WithError(err).Error("WEBHOOK_DISPATCH_ERROR")
+			} else if count > 0 {
+				logger.WithField("count", count).Info("WEBHOOK_DELIVERIES_ATTEMPTED")
+			}
+		}
+	}
+}
+
+// attempt posts the delivery to the webhook and records the outcome on it.
+// Failed deliveries are rescheduled with an exponential backoff if retry is set.
+func (s *Service) attempt(
+	ctx context.Context,
+	webhook *model.Webhook,
+	delivery *model.WebhookDelivery,
+	retry bool,
+) {
+	now := time.Now()
+	delivery.Attempts++
+
+	code, err := s.post(ctx, webhook, delivery, now.Unix())
+	delivery.ResponseCode = code
+
+	if err == nil {
+		delivery.Status = model.DeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = now.Unix()
+		metrics.WebhookDeliveryAttemptsTotal.WithLabelValues(outcomeSucceeded).Inc()
+		return
+	}
+
+	pkgLogger.Ctx(ctx).WithError(err).
+		WithField("delivery_id", delivery.GetPublicID()).
+		Warn("WEBHOOK_DELIVERY_FAILED")
+
+	delivery.LastError = err.Error()
+	if !retry || delivery.Attempts >= s.config.MaxAttempts {
+		delivery.Status = model.DeliveryStatusFailed
+		metrics.WebhookDeliveryAttemptsTotal.WithLabelValues(outcomeFailed).Inc()
+		return
+	}
+
+	metrics.WebhookDeliveryAttemptsTotal.WithLabelValues(outcomeRetrying).Inc()
+	delivery.NextAttemptAt = now.Add(s.backoff(delivery.Attempts)).Unix()
+}
+
+// post sends the signed delivery and returns the response status
+func (s *Service) post(
+	ctx context.Context,
+	webhook *model.Webhook,
+	delivery *model.WebhookDelivery,
+	timestamp int64,
+) (_ int, err error) {
+	ctx, span := tracing.Start(
+		ctx,
+		"WebhookService.post",
+		semconv.HTTPRequestMethodKey.String(http.MethodPost),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	body := []byte(delivery.GetPayload())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.GetURL(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	span.SetAttributes(semconv.ServerAddress(req.URL.Hostname()))
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, delivery.GetPublicID())
+	req.Header.Set(HeaderEventType, delivery.GetEventType())
+	req.Header.Set(HeaderTimestamp, fmt.Sprintf("%d", timestamp))
+	req.Header.Set(HeaderSignature, Sign(webhook.GetSecret(), timestamp, body))
+	if requestID := requestid.FromContext(ctx); requestID != "" {
+		req.Header.Set(constants.HeaderRequestId, requestID)
+	}
+	// the receivers can continue the trace of the delivery
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, responseErrorLimit))
+		return resp.StatusCode, fmt.Errorf(
+			"webhook responded with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt, doubling
+// with every attempt up to the configured maximum
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := time.Duration(s.config.RetryBaseDelay) * time.Second << (attempts - 1)
+	maxDelay := time.Duration(s.config.RetryMaxDelay) * time.Second
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
+
+// newSecret generates a random signing key
+func newSecret() (string, error) {
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	goerr "errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/danushk97/image-analyzer/internal/constants"
+	"github.com/danushk97/image-analyzer/internal/webhook/dtos"
+	"github.com/danushk97/image-analyzer/internal/webhook/model/v1"
+	"github.com/danushk97/image-analyzer/internal/webhook/repo"
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
+)
+
+const testUserID = "8f0c5ab4-3d4e-4a4f-9b4c-7f6f2f6a9e01"
+
+// receiver records the deliveries posted to it and answers with its status
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	t.Helper()
+
+	r := &receiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		status := r.status
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func newTestService(config Config) *Service {
+	// the receivers of the tests listen on the loopback
+	config.AllowPrivateDestinations = true
+
+	return NewService(
+		WithStorage(memory.NewStore()),
+		WithConfig(config),
+	)
+}
+
+func userContext() context.Context {
+	return contextkey.SetInContext(context.Background(), contextkey.UserID, testUserID)
+}
+
+// enqueue registers a webhook to the receiver and enqueues an event for it
+func enqueue(t *testing.T, s *Service, url string) *model.Webhook {
+	t.Helper()
+
+	ctx := userContext()
+	webhook, err := s.CreateWebhook(ctx, &dtos.CreateWebhookRequest{
+		URL:    url,
+		Events: []string{constants.EventImageCreated},
+	})
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	if err = s.Enqueue(ctx, testUserID, "event-1", constants.EventImageCreated, []byte(`{"id":"image_1"}`)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	return webhook
+}
+
+// delivery returns the single delivery of the webhook
+func delivery(t *testing.T, s *Service, webhook *model.Webhook) *model.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := s.ListDeliveries(userContext(), webhook.GetID())
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(deliveries))
+	}
+
+	return deliveries[0]
+}
+
+func TestBackoff(t *testing.T) {
+	s := newTestService(Config{RetryBaseDelay: 30, RetryMaxDelay: 300})
+
+	want := []time.Duration{
+		30 * time.Second,
+		60 * time.Second,
+		120 * time.Second,
+		240 * time.Second,
+		300 * time.Second,
+		300 * time.Second,
+	}
+	for i, delay := range want {
+		if got := s.backoff(i + 1); got != delay {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, delay)
+		}
+	}
+
+	// the shift overflows after many attempts
+	if got := s.backoff(100); got != 300*time.Second {
+		t.Errorf("backoff(100) = %v, want the maximum delay", got)
+	}
+}
+
+func TestDeliverDueSucceeds(t *testing.T) {
+	r := newReceiver(t, http.StatusNoContent)
+	s := newTestService(Config{})
+	webhook := enqueue(t, s, r.URL)
+
+	attempted, err := s.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("deliver due: %v", err)
+	}
+	if attempted != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempted)
+	}
+
+	d := delivery(t, s, webhook)
+	if d.GetStatus() != model.DeliveryStatusSucceeded || d.ResponseCode != http.StatusNoContent || d.DeliveredAt == 0 {
+		t.Errorf("unexpected delivery: %+v", d)
+	}
+
+	req := r.requests[0]
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if !Verify(webhook.GetSecret(), timestamp, r.bodies[0], req.Header.Get(HeaderSignature), time.Minute, time.Now()) {
+		t.Error("expected the delivery to be signed with the secret of the webhook")
+	}
+	if req.Header.Get(HeaderEventType) != constants.EventImageCreated || req.Header.Get(HeaderDeliveryID) != d.GetPublicID() {
+		t.Errorf("unexpected headers: %v", req.Header)
+	}
+
+	// the succeeded deliveries are not attempted again
+	if attempted, _ = s.DeliverDue(context.Background()); attempted != 0 {
+		t.Errorf("expected no attempt, got %d", attempted)
+	}
+}
+
+func TestDeliverDueFailsAfterMaxAttempts(t *testing.T) {
+	r := newReceiver(t, http.StatusInternalServerError)
+	s := newTestService(Config{MaxAttempts: 2, RetryBaseDelay: 60, RetryMaxDelay: 600})
+	webhook := enqueue(t, s, r.URL)
+
+	before := time.Now().Unix()
+	if _, err := s.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("deliver due: %v", err)
+	}
+
+	d := delivery(t, s, webhook)
+	if d.GetStatus() != model.DeliveryStatusPending || d.Attempts != 1 || d.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("expected a pending delivery after the first failure, got %+v", d)
+	}
+	if d.NextAttemptAt < before+60 || d.NextAttemptAt > time.Now().Unix()+60 {
+		t.Errorf("expected the retry in 60s, got %d", d.NextAttemptAt-before)
+	}
+
+	// the retry is not due yet
+	if attempted, _ := s.DeliverDue(context.Background()); attempted != 0 {
+		t.Fatalf("expected no attempt before the retry is due, got %d", attempted)
+	}
+
+	d.NextAttemptAt = 0
+	if err := s.Repo.UpdateWebhookDelivery(context.Background(), d); err != nil {
+		t.Fatalf("update delivery: %v", err)
+	}
+	if _, err := s.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("deliver due: %v", err)
+	}
+
+	d = delivery(t, s, webhook)
+	if d.GetStatus() != model.DeliveryStatusFailed || d.Attempts != 2 || d.LastError == "" {
+		t.Errorf("expected a failed delivery after the max attempts, got %+v", d)
+	}
+	if len(r.requests) != 2 {
+		t.Errorf("expected 2 posts, got %d", len(r.requests))
+	}
+}
+
+func TestDeliverDuePostsOutsideOfTheClaim(t *testing.T) {
+	s := newTestService(Config{Timeout: 5})
+
+	claims := make(chan int, 1)
+	r := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// another dispatcher claims while the delivery is posted, it would
+		// wait for the transaction of the first claim if it was still open
+		go func() {
+			deliveries, _ := s.claimDue(context.Background())
+			claims <- len(deliveries)
+		}()
+
+		select {
+		case claimed := <-claims:
+			if claimed != 0 {
+				t.Errorf("expected the delivery to be leased, %d claimed again", claimed)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("the claim waits for the post of the delivery")
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer r.Close()
+
+	webhook := enqueue(t, s, r.URL)
+
+	if _, err := s.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("deliver due: %v", err)
+	}
+	if d := delivery(t, s, webhook); d.GetStatus() != model.DeliveryStatusSucceeded {
+		t.Errorf("expected a succeeded delivery, got %+v", d)
+	}
+}
+
+func TestDeliverDueDropsTheDeliveriesOfDeletedWebhooks(t *testing.T) {
+	s := newTestService(Config{})
+
+	var deleted *model.Webhook
+	dropped := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the webhook is deleted while its delivery is posted
+		if err := s.DeleteWebhook(userContext(), deleted.GetPublicID()); err != nil {
+			t.Errorf("delete webhook: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer dropped.Close()
+	kept := newReceiver(t, http.StatusOK)
+
+	ctx := userContext()
+	var err errors.IError
+	deleted, err = s.CreateWebhook(ctx, &dtos.CreateWebhookRequest{URL: dropped.URL, Events: []string{constants.EventImageCreated}})
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	webhook, err := s.CreateWebhook(ctx, &dtos.CreateWebhookRequest{URL: kept.URL, Events: []string{constants.EventImageCreated}})
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	if err = s.Enqueue(ctx, testUserID, "event-1", constants.EventImageCreated, []byte(`{"id":"image_1"}`)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	attempted, err := s.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("expected the dropped delivery to be skipped, got %v", err)
+	}
+	if attempted != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempted)
+	}
+	if d := delivery(t, s, webhook); d.GetStatus() != model.DeliveryStatusSucceeded {
+		t.Errorf("expected the delivery of the other webhook to be recorded, got %+v", d)
+	}
+}
+
+func TestCreateWebhookRejectsPrivateDestinations(t *testing.T) {
+	s := NewService(WithStorage(memory.NewStore()))
+
+	for _, url := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/hook",
+		"http://192.168.1.10/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://100.64.0.1/hook",
+	} {
+		_, err := s.CreateWebhook(userContext(), &dtos.CreateWebhookRequest{
+			URL:    url,
+			Events: []string{constants.EventImageCreated},
+		})
+		if err == nil || !err.IsOfType(errors.UNPROCESSABLE_ERROR) {
+			t.Errorf("expected %s to be rejected, got %v", url, err)
+		}
+	}
+
+	if _, err := s.CreateWebhook(userContext(), &dtos.CreateWebhookRequest{
+		URL:    "https://hooks.example.com/image",
+		Events: []string{constants.EventImageCreated},
+	}); err != nil {
+		t.Errorf("expected a public destination to be accepted, got %v", err)
+	}
+}
+
+func TestHTTPClientRefusesPrivateAddresses(t *testing.T) {
+	r := newReceiver(t, http.StatusOK)
+
+	_, err := newHTTPClient(time.Second, false).Get(r.URL)
+	if !goerr.Is(err, ErrForbiddenDestination) {
+		t.Errorf("expected the loopback to be refused at dial time, got %v", err)
+	}
+
+	resp, err := newHTTPClient(time.Second, true).Get(r.URL)
+	if err != nil {
+		t.Fatalf("expected the loopback to be allowed, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:2800::1":    true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.0.1":     false,
+		"169.254.169.254": false,
+		"100.100.1.1":     false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if got := isPublicIP(net.ParseIP(address)); got != public {
+			t.Errorf("isPublicIP(%s) = %v, want %v", address, got, public)
+		}
+	}
+}
+
+// fakeRepo serves the webhooks it holds, the other
+// methods of the repo are not expected to be called
+type fakeRepo struct {
+	repo.Repo
+	webhooks map[string]*model.Webhook
+	lookups  int
+}
+
+func (r *fakeRepo) FindWebhookByID(_ context.Context, id string) (*model.Webhook, errors.IError) {
+	r.lookups++
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, errors.NewBadRequestError("record_not_found")
+	}
+
+	return webhook, nil
+}
+
+func TestGetWebhook(t *testing.T) {
+	id := uuid.NewString()
+	webhook := &model.Webhook{UserID: "user_1", URL: "https://example.com"}
+	webhook.ID = id
+
+	tests := []struct {
+		name   string
+		id     string
+		userID string
+		found  bool
+		lookup bool
+	}{
+		{name: "owned webhook", id: model.WebhookIDPrefix + id, userID: "user_1", found: true, lookup: true},
+		{name: "webhook of another user", id: model.WebhookIDPrefix + id, userID: "user_2", lookup: true},
+		{name: "malformed id", id: model.WebhookIDPrefix + "1 OR 1=1", userID: "user_1"},
+		{name: "empty id", id: "", userID: "user_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeRepo{webhooks: map[string]*model.Webhook{id: webhook}}
+			svc := NewService()
+			svc.Repo = r
+			ctx := contextkey.SetInContext(context.Background(), contextkey.UserID, tt.userID)
+
+			got, err := svc.GetWebhook(ctx, tt.id)
+			if tt.found {
+				if err != nil || got != webhook {
+					t.Fatalf("expected the webhook, got %v, %v", got, err)
+				}
+			} else if err == nil || !err.IsOfType(errors.NOT_FOUND_ERROR) {
+				t.Fatalf("expected a not found error, got %v", err)
+			}
+
+			if lookedUp := r.lookups > 0; lookedUp != tt.lookup {
+				t.Errorf("expected the repo lookup to be %v, got %v", tt.lookup, lookedUp)
+			}
+		})
+	}
+}
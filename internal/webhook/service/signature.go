@@ -0,0 +1,52 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderSignature carries the HMAC-SHA256 signature of the delivery
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderTimestamp carries the unix time the delivery was signed at
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderDeliveryID identifies the delivery, it is the same across attempts
+	HeaderDeliveryID = "X-Webhook-Delivery-Id"
+	// HeaderEventType carries the type of the delivered event
+	HeaderEventType = "X-Webhook-Event-Type"
+
+	signatureVersion = "v1="
+)
+
+// Sign returns the signature of the body sent at the given time.
+// The signed content is "<timestamp>.<body>" so that a captured
+// delivery can not be replayed with a different timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a delivery received at the given time,
+// deliveries signed more than tolerance ago are rejected
+func Verify(
+	secret string,
+	timestamp int64,
+	body []byte,
+	signature string,
+	tolerance time.Duration,
+	now time.Time,
+) bool {
+	signedAt := time.Unix(timestamp, 0)
+	if now.Sub(signedAt) > tolerance || signedAt.Sub(now) > tolerance {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"id":"event-1"}`)
+	now := time.Unix(1700000000, 0)
+	timestamp := now.Unix()
+
+	signature := Sign(secret, timestamp, body)
+	if !strings.HasPrefix(signature, signatureVersion) {
+		t.Fatalf("expected the %q prefix, got %q", signatureVersion, signature)
+	}
+	if Sign(secret, timestamp, body) != signature {
+		t.Fatal("expected the signature to be deterministic")
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+		now       time.Time
+		valid     bool
+	}{
+		{"valid", secret, timestamp, body, signature, now, true},
+		{"within tolerance", secret, timestamp, body, signature, now.Add(4 * time.Minute), true},
+		{"other secret", "whsec_other", timestamp, body, signature, now, false},
+		{"tampered body", secret, timestamp, []byte(`{"id":"event-2"}`), signature, now, false},
+		{"replayed timestamp", secret, timestamp + 1, body, signature, now, false},
+		{"expired", secret, timestamp, body, signature, now.Add(6 * time.Minute), false},
+		{"from the future", secret, timestamp, body, signature, now.Add(-6 * time.Minute), false},
+		{"malformed", secret, timestamp, body, "v1=00", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid := Verify(tt.secret, tt.timestamp, tt.body, tt.signature, 5*time.Minute, tt.now)
+			if valid != tt.valid {
+				t.Errorf("Verify() = %v, want %v", valid, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/danushk97/image-analyzer/internal/outbox/model/v1"
+	"github.com/danushk97/image-analyzer/internal/outbox/sink"
+	"github.com/danushk97/image-analyzer/internal/webhook/service"
+)
+
+// eventOwner is the part of the event data identifying the owner of the aggregate
+type eventOwner struct {
+	UserID string `json:"user_id"`
+}
+
+// OutboxSink enqueues the outbox events as deliveries
+// to the webhooks of the owner of the changed aggregate
+type OutboxSink struct {
+	service *service.Service
+}
+
+// NewOutboxSink creates a new sink for the outbox relay
+func NewOutboxSink(webhookService *service.Service) *OutboxSink {
+	return &OutboxSink{service: webhookService}
+}
+
+// Name identifies the sink in the logs
+func (s *OutboxSink) Name() string {
+	return "user_webhooks"
+}
+
+// Send enqueues the event for the webhooks subscribed to it,
+// events without an owner are ignored
+func (s *OutboxSink) Send(ctx context.Context, event *model.OutboxEvent) error {
+	owner := &eventOwner{}
+	if err := json.Unmarshal([]byte(event.GetPayload()), owner); err != nil {
+		return err
+	}
+
+	if owner.UserID == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(sink.NewMessage(event))
+	if err != nil {
+		return err
+	}
+
+	if iErr := s.service.Enqueue(
+		ctx,
+		owner.UserID,
+		event.GetID(),
+		event.GetEventType(),
+		payload,
+	); iErr != nil {
+		return iErr
+	}
+
+	return nil
+}
+
+// Close releases the resources held by the sink
+func (s *OutboxSink) Close() error {
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -25,8 +26,14 @@ const (
 	// In test mode, the database is different
 	// Based on the mode, we pick a different configuration file
 	AppModeTest = "test"
+	// SecretFileSuffix is the suffix of the environment variables holding
+	// the path of a file to read a value from, e.g. STORE_SQL_PASSWORD_FILE
+	SecretFileSuffix = "_FILE"
 )
 
+// envKeyReplacer maps the keys to their environment variables, e.g. store.sql.password to STORE_SQL_PASSWORD
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
 // Options is config options.
 type Options struct {
 	configType            string
@@ -117,14 +124,16 @@ func (c *Loader) Load(env string, config interface{}) error {
 // loadByConfigName reads configuration from file and unmarshalls into config.
 func (c *Loader) loadByConfigName(configName string, config interface{}) error {
 	if configName == DefaultConfigFileName {
-		fmt.Printf(
+		fmt.Fprintf(
+			os.Stderr,
 			"Loading default config file: %v/%v.%v\n",
 			c.opts.configPath,
 			configName,
 			c.opts.configType,
 		)
 	} else {
-		fmt.Printf(
+		fmt.Fprintf(
+			os.Stderr,
 			"Loading config file: %v/%v.%v\n",
 			c.opts.configPath,
 			configName,
@@ -136,10 +145,39 @@ func (c *Loader) loadByConfigName(configName string, config interface{}) error {
 	c.viper.SetConfigType(c.opts.configType)
 	c.viper.AddConfigPath(c.opts.configPath)
 	c.viper.AutomaticEnv()
-	c.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	c.viper.SetEnvKeyReplacer(envKeyReplacer)
 	if err := c.viper.ReadInConfig(); err != nil {
 		return err
 	}
 
-	return c.viper.Unmarshal(config)
+	if err := c.loadSecretFiles(); err != nil {
+		return err
+	}
+
+	// the keys matching no field are rejected to catch the typos
+	return c.viper.Unmarshal(config, func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = true
+	})
+}
+
+// loadSecretFiles sets the keys whose <KEY>_FILE environment variable is set
+// to the content of the file, which keeps the secrets out of the config files
+func (c *Loader) loadSecretFiles() error {
+	for _, key := range c.viper.AllKeys() {
+		envKey := strings.ToUpper(envKeyReplacer.Replace(key)) + SecretFileSuffix
+
+		path, ok := os.LookupEnv(envKey)
+		if !ok || path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %v: %w", envKey, err)
+		}
+
+		c.viper.Set(key, strings.TrimSpace(string(content)))
+	}
+
+	return nil
 }
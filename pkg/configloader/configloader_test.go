@@ -0,0 +1,105 @@
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testConfig struct {
+	Store struct {
+		SQL struct {
+			Username string
+			Password string
+		}
+	}
+}
+
+// newTestLoader writes the default and dev config files to a new directory
+func newTestLoader(t *testing.T, defaultConfig string, devConfig string) *Loader {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"default.toml": defaultConfig, "dev.toml": devConfig} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+
+	return NewLoader(Options{configType: DefaultConfigType, configPath: dir, defaultConfigFileName: DefaultConfigFileName})
+}
+
+const defaultConfig = `
+[store.sql]
+username = "app"
+password = ""
+`
+
+func TestLoadEnvOverride(t *testing.T) {
+	loader := newTestLoader(t, defaultConfig, "[store.sql]\npassword = \"dev\"\n")
+
+	var config testConfig
+	if err := loader.Load("dev", &config); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if config.Store.SQL.Username != "app" || config.Store.SQL.Password != "dev" {
+		t.Errorf("expected the env file over the default one, got %+v", config)
+	}
+
+	t.Setenv("STORE_SQL_PASSWORD", "from-env")
+	loader = newTestLoader(t, defaultConfig, "[store.sql]\npassword = \"dev\"\n")
+	if err := loader.Load("dev", &config); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if config.Store.SQL.Password != "from-env" {
+		t.Errorf("expected the environment variable over the files, got %q", config.Store.SQL.Password)
+	}
+}
+
+func TestLoadUnusedKeys(t *testing.T) {
+	loader := newTestLoader(t, defaultConfig, "[store.sql]\npasword = \"typo\"\n")
+
+	var config testConfig
+	err := loader.Load("dev", &config)
+	if err == nil || !strings.Contains(err.Error(), "pasword") {
+		t.Fatalf("expected the unknown key to be rejected, got %v", err)
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "secret file", path: secret, want: "s3cr3t"},
+		{name: "empty path", path: "", want: "dev"},
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STORE_SQL_PASSWORD"+SecretFileSuffix, tt.path)
+			loader := newTestLoader(t, defaultConfig, "[store.sql]\npassword = \"dev\"\n")
+
+			var config testConfig
+			err := loader.Load("dev", &config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected an error to be %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && config.Store.SQL.Password != tt.want {
+				t.Errorf("expected the password %q, got %q", tt.want, config.Store.SQL.Password)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cr3t") {
+				t.Errorf("expected the error not to hold the secret, got %v", err)
+			}
+		})
+	}
+}
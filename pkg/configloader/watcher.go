@@ -0,0 +1,297 @@
+package configloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+// DefaultReloadDelay is the time waited after a change of the files before
+// reloading them, the editors often write a file in several steps
+const DefaultReloadDelay = 200 * time.Millisecond
+
+// secretKeys are the parts of the names of the keys whose values are not logged
+var secretKeys = []string{"password", "token", "secret"}
+
+// Event is published to the subscribers when the config is reloaded
+type Event struct {
+	// Config is the reloaded config, a pointer of the type given to Watch
+	Config interface{}
+	// Keys are the changed keys, lower cased and dot separated, e.g. logger.level
+	Keys []string
+}
+
+// Change is the change of the value of a key
+type Change struct {
+	Key      string
+	Previous interface{}
+	Current  interface{}
+}
+
+func (c Change) String() string {
+	previous, current := c.Previous, c.Current
+	if IsSecretKey(c.Key) {
+		previous, current = pkgLogger.Redacted, pkgLogger.Redacted
+	}
+
+	return fmt.Sprintf("%v: %v -> %v", c.Key, previous, current)
+}
+
+// Watcher reloads the config when its files change and publishes the
+// changes limited to the reloadable keys to the subscribers. The reloads
+// changing other keys, which require a restart, are rejected as a whole.
+type Watcher struct {
+	loader     *Loader
+	env        string
+	reloadable []string
+
+	mu          sync.Mutex
+	current     interface{}
+	subscribers []func(Event)
+}
+
+// Watch creates a watcher of the files of the env, config is the loaded config
+// and reloadable are the keys, or the sections, which can change at runtime
+func (c *Loader) Watch(env string, config interface{}, reloadable ...string) (*Watcher, error) {
+	if reflect.TypeOf(config).Kind() != reflect.Pointer {
+		return nil, fmt.Errorf("config must be a pointer, got %T", config)
+	}
+
+	keys := make([]string, 0, len(reloadable))
+	for _, key := range reloadable {
+		keys = append(keys, strings.ToLower(key))
+	}
+
+	return &Watcher{
+		loader:     c,
+		env:        env,
+		reloadable: keys,
+		current:    config,
+	}, nil
+}
+
+// Subscribe registers a function called with every accepted reload
+func (w *Watcher) Subscribe(subscriber func(Event)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, subscriber)
+}
+
+// Run watches the config directory until the context is done
+func (w *Watcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// the directory is watched as the editors replace the files
+	if err := watcher.Add(w.loader.opts.configPath); err != nil {
+		return err
+	}
+
+	logger := pkgLogger.Ctx(ctx)
+	timer := time.NewTimer(DefaultReloadDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if w.isConfigFile(event.Name) && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				timer.Reset(DefaultReloadDelay)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.WithError(err).Warn("CONFIG_WATCH_FAILED")
+
+		case <-timer.C:
+			w.Reload(ctx)
+		}
+	}
+}
+
+// Reload loads the config files again and publishes the changes when
+// they are limited to the reloadable keys, else logs them
+func (w *Watcher) Reload(ctx context.Context) {
+	logger := pkgLogger.Ctx(ctx)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	config := reflect.New(reflect.TypeOf(w.current).Elem()).Interface()
+
+	loader := NewLoader(w.loader.opts)
+	if err := loader.Load(w.env, config); err != nil {
+		logger.WithError(err).Error("CONFIG_RELOAD_FAILED")
+		return
+	}
+	if validatable, ok := config.(interface{ Validate() error }); ok {
+		if err := validatable.Validate(); err != nil {
+			logger.WithError(err).Error("CONFIG_RELOAD_INVALID")
+			return
+		}
+	}
+
+	changes, err := diff(w.current, config)
+	if err != nil {
+		logger.WithError(err).Error("CONFIG_RELOAD_FAILED")
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	var keys, rejected []string
+	for _, change := range changes {
+		if !w.isReloadable(change.Key) {
+			rejected = append(rejected, change.String())
+			continue
+		}
+		keys = append(keys, change.Key)
+	}
+
+	if len(rejected) > 0 {
+		logger.WithField("diff", strings.Join(rejected, "; ")).
+			Error("CONFIG_RELOAD_REJECTED_RESTART_REQUIRED")
+		return
+	}
+
+	logger.WithField("diff", joinChanges(changes)).Warn("CONFIG_RELOADED")
+
+	w.current = config
+	for _, subscriber := range w.subscribers {
+		subscriber(Event{Config: config, Keys: keys})
+	}
+}
+
+// isConfigFile checks if the file is one of the files of the env
+func (w *Watcher) isConfigFile(name string) bool {
+	env := w.env
+	if w.loader.opts.testMode {
+		env = env + "_test"
+	}
+
+	base := filepath.Base(name)
+	for _, configName := range []string{w.loader.opts.defaultConfigFileName, env} {
+		if base == configName+"."+w.loader.opts.configType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isReloadable checks if the key, or one of its sections, is reloadable
+func (w *Watcher) isReloadable(key string) bool {
+	for _, reloadable := range w.reloadable {
+		if key == reloadable || strings.HasPrefix(key, reloadable+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diff returns the changes of the keys between the configs, sorted by key
+func diff(previous interface{}, current interface{}) ([]Change, error) {
+	previousKeys, err := flatten(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentKeys, err := flatten(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for key, value := range currentKeys {
+		if !reflect.DeepEqual(previousKeys[key], value) {
+			changes = append(changes, Change{Key: key, Previous: previousKeys[key], Current: value})
+		}
+	}
+	for key, value := range previousKeys {
+		if _, ok := currentKeys[key]; !ok {
+			changes = append(changes, Change{Key: key, Previous: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes, nil
+}
+
+// flatten maps the dot separated and lower cased keys of the config to
+// their values, the lists are values of their own
+func flatten(config interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(encoded, &settings); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	flattenInto(keys, "", settings)
+
+	return keys, nil
+}
+
+func flattenInto(keys map[string]interface{}, prefix string, settings map[string]interface{}) {
+	for key, value := range settings {
+		key = prefix + strings.ToLower(key)
+
+		if section, ok := value.(map[string]interface{}); ok {
+			flattenInto(keys, key+".", section)
+			continue
+		}
+		keys[key] = value
+	}
+}
+
+// joinChanges formats the changes for the logs
+func joinChanges(changes []Change) string {
+	formatted := make([]string, 0, len(changes))
+	for _, change := range changes {
+		formatted = append(formatted, change.String())
+	}
+
+	return strings.Join(formatted, "; ")
+}
+
+// IsSecretKey checks if the key holds a secret from its name, case insensitive
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secretKey := range secretKeys {
+		if strings.Contains(key, secretKey) {
+			return true
+		}
+	}
+
+	return false
+}
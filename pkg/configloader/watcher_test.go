@@ -0,0 +1,32 @@
+package configloader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	for key, secret := range map[string]bool{
+		"store.sql.password":     true,
+		"admin.Token":            true,
+		"webhooks.signingSecret": true,
+		"logger.level":           false,
+		"store.sql.user":         false,
+	} {
+		if got := IsSecretKey(key); got != secret {
+			t.Errorf("IsSecretKey(%q) = %v, want %v", key, got, secret)
+		}
+	}
+}
+
+func TestChangeStringRedactsSecrets(t *testing.T) {
+	change := Change{Key: "admin.token", Previous: "old-token", Current: "new-token"}
+	if s := change.String(); strings.Contains(s, "old-token") || strings.Contains(s, "new-token") {
+		t.Errorf("expected the secrets to be redacted, got %q", s)
+	}
+
+	change = Change{Key: "logger.level", Previous: "info", Current: "debug"}
+	if s := change.String(); s != "logger.level: info -> debug" {
+		t.Errorf("unexpected change %q", s)
+	}
+}
@@ -6,6 +6,10 @@ import "os"
 // used to decide seed or not seed data
 const EnvDev = "dev"
 
+// EnvProd signifies it is the production and is
+// used to disable the debugging aids
+const EnvProd = "prod"
+
 // ModeTest signifies it is a testing mode
 const ModeTest = "test"
 
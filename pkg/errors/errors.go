@@ -5,37 +5,84 @@ type IError interface {
 	Cause() error
 	Wrap(err error) IError
 	IsOfType(ErrorType) bool
+	GetType() ErrorType
+	Details() []FieldError
+	WithDetails(details ...FieldError) IError
+	IsRetryable() bool
+	AsRetryable() IError
+}
+
+// FieldError describes the failure of a single field of the input
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 // AppError is the error type with a string type and a parent error class.
 type AppError struct {
-	message string
-	cause   error
-	Type    ErrorType
+	message   string
+	cause     error
+	details   []FieldError
+	retryable bool
+	Type      ErrorType
 }
 
 // NewAppError creates a new error with the given message and class.
-func NewBadRequestError(message string) IError {
+func NewAppError(message string, eType ErrorType) IError {
 	return AppError{
 		message: message,
-		Type:    BAD_REQUEST_ERROR,
+		Type:    eType,
 	}
 }
 
-// NewAppError creates a new error with the given message and class.
+// NewBadRequestError creates a new error with the given message and class.
+func NewBadRequestError(message string) IError {
+	return NewAppError(message, BAD_REQUEST_ERROR)
+}
+
+// NewServerError creates a new error with the given message and class.
 func NewServerError(message string) IError {
-	return AppError{
-		message: message,
-		Type:    INTERNAL_SERVER_ERROR,
-	}
+	return NewAppError(message, INTERNAL_SERVER_ERROR)
 }
 
-// NewAppError creates a new error with the given message and class.
+// NewAuthorizationError creates a new error with the given message and class.
 func NewAuthorizationError(message string) IError {
-	return AppError{
-		message: message,
-		Type:    AUTHORIZATION_ERROR,
-	}
+	return NewAppError(message, AUTHORIZATION_ERROR)
+}
+
+// NewConflictError creates a new error with the given message and class.
+func NewConflictError(message string) IError {
+	return NewAppError(message, CONFLICT_ERROR)
+}
+
+// NewNotFoundError creates a new error with the given message and class.
+func NewNotFoundError(message string) IError {
+	return NewAppError(message, NOT_FOUND_ERROR)
+}
+
+// NewForbiddenError creates a new error with the given message and class.
+func NewForbiddenError(message string) IError {
+	return NewAppError(message, FORBIDDEN_ERROR)
+}
+
+// NewUnprocessableError creates a new error with the given message and class.
+func NewUnprocessableError(message string) IError {
+	return NewAppError(message, UNPROCESSABLE_ERROR)
+}
+
+// NewRateLimitedError creates a new error with the given message and class.
+func NewRateLimitedError(message string) IError {
+	return NewAppError(message, RATE_LIMITED_ERROR)
+}
+
+// NewUnavailableError creates a new error with the given message and class.
+func NewUnavailableError(message string) IError {
+	return NewAppError(message, UNAVAILABLE_ERROR)
+}
+
+// NewNotImplementedError creates a new error with the given message and class.
+func NewNotImplementedError(message string) IError {
+	return NewAppError(message, NOT_IMPLEMENTED_ERROR)
 }
 
 // AppError returns the error message.
@@ -53,6 +100,40 @@ func (e AppError) Cause() error {
 	return e.cause
 }
 
+// Unwrap exposes the cause to errors.Is and errors.As
+func (e AppError) Unwrap() error {
+	return e.cause
+}
+
 func (e AppError) IsOfType(eType ErrorType) bool {
 	return e.Type == eType
 }
+
+// GetType returns the class of the error
+func (e AppError) GetType() ErrorType {
+	return e.Type
+}
+
+// Details returns the field level details of the error
+func (e AppError) Details() []FieldError {
+	return e.details
+}
+
+// WithDetails returns a copy of the error with the field level details added
+func (e AppError) WithDetails(details ...FieldError) IError {
+	e.details = append(append([]FieldError{}, e.details...), details...)
+
+	return e
+}
+
+// IsRetryable returns true if the failed operation can be safely retried
+func (e AppError) IsRetryable() bool {
+	return e.retryable
+}
+
+// AsRetryable returns a copy of the error marked as retryable
+func (e AppError) AsRetryable() IError {
+	e.retryable = true
+
+	return e
+}
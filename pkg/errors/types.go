@@ -6,4 +6,11 @@ const (
 	BAD_REQUEST_ERROR     ErrorType = "BAD_REQUEST_ERROR"
 	INTERNAL_SERVER_ERROR ErrorType = "INTERNAL_SERVER_ERROR"
 	AUTHORIZATION_ERROR   ErrorType = "AUTHORIZATION_ERROR"
+	CONFLICT_ERROR        ErrorType = "CONFLICT_ERROR"
+	NOT_FOUND_ERROR       ErrorType = "NOT_FOUND_ERROR"
+	FORBIDDEN_ERROR       ErrorType = "FORBIDDEN_ERROR"
+	UNPROCESSABLE_ERROR   ErrorType = "UNPROCESSABLE_ERROR"
+	RATE_LIMITED_ERROR    ErrorType = "RATE_LIMITED_ERROR"
+	UNAVAILABLE_ERROR     ErrorType = "UNAVAILABLE_ERROR"
+	NOT_IMPLEMENTED_ERROR ErrorType = "NOT_IMPLEMENTED_ERROR"
 )
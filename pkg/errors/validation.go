@@ -0,0 +1,56 @@
+package errors
+
+import (
+	goerr "errors"
+	"sort"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// NewValidationError creates an unprocessable error with the given message
+// and the field level details extracted from the ozzo-validation error
+func NewValidationError(message string, err error) IError {
+	return NewUnprocessableError(message).
+		Wrap(err).
+		WithDetails(FieldErrors(err)...)
+}
+
+// FieldErrors flattens the ozzo-validation errors into field level details.
+// Nested structs are reported with dotted field names
+func FieldErrors(err error) []FieldError {
+	var vErrs validation.Errors
+	if !goerr.As(err, &vErrs) {
+		return nil
+	}
+
+	return flattenFieldErrors("", vErrs)
+}
+
+func flattenFieldErrors(prefix string, vErrs validation.Errors) []FieldError {
+	fields := make([]string, 0, len(vErrs))
+	for field := range vErrs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var details []FieldError
+	for _, field := range fields {
+		name := field
+		if prefix != "" {
+			name = prefix + "." + field
+		}
+
+		var nested validation.Errors
+		if goerr.As(vErrs[field], &nested) {
+			details = append(details, flattenFieldErrors(name, nested)...)
+			continue
+		}
+
+		details = append(details, FieldError{
+			Field:   name,
+			Message: vErrs[field].Error(),
+		})
+	}
+
+	return details
+}
@@ -0,0 +1,45 @@
+package errors
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func TestFieldErrors(t *testing.T) {
+	err := validation.Errors{
+		"name": validation.ErrRequired,
+		"size": validation.ErrMinGreaterEqualThanRequired,
+		"owner": validation.Errors{
+			"id": validation.ErrRequired,
+		},
+	}
+
+	details := FieldErrors(err)
+
+	want := []string{"name", "owner.id", "size"}
+	if len(details) != len(want) {
+		t.Fatalf("expected %d field errors, got %+v", len(want), details)
+	}
+	for i, field := range want {
+		if details[i].Field != field || details[i].Message == "" {
+			t.Errorf("field error %d = %+v, want the field %s", i, details[i], field)
+		}
+	}
+}
+
+func TestNewValidationError(t *testing.T) {
+	err := NewValidationError("validation_failure", validation.Errors{"name": validation.ErrRequired})
+
+	if !err.IsOfType(UNPROCESSABLE_ERROR) || err.Error() != "validation_failure" {
+		t.Errorf("expected an unprocessable error, got %v", err)
+	}
+	if details := err.Details(); len(details) != 1 || details[0].Field != "name" {
+		t.Errorf("expected the field error, got %+v", details)
+	}
+
+	// the errors of other kinds have no field details
+	if details := FieldErrors(validation.ErrRequired); details != nil {
+		t.Errorf("expected no field error, got %+v", details)
+	}
+}
@@ -8,31 +8,40 @@ import (
 
 const (
 	ContextKey = "context"
+	// TraceIDKey and SpanIDKey are the fields of the span the log belongs to
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
 )
 
+// entryCallerSkip is the number of frames between the
+// caller of the Entry methods and Entry.enabled
+const entryCallerSkip = 3
+
 type Entry struct {
 	logger *Logger
 	Data   map[string]interface{}
+	// debug enables all the logs of the entry
+	debug bool
 }
 
 func (e *Entry) Info(msg string) {
-	e.logger.SugaredLogger.Logw(zapcore.InfoLevel, msg, ContextKey, e.Data)
+	e.log(zapcore.InfoLevel, msg)
 }
 
 func (e *Entry) Debug(msg string) {
-	e.logger.SugaredLogger.Logw(zapcore.InfoLevel, msg, ContextKey, e.Data)
+	e.log(zapcore.DebugLevel, msg)
 }
 
 func (e *Entry) Warn(msg string) {
-	e.logger.SugaredLogger.Logw(zapcore.WarnLevel, msg, ContextKey, e.Data)
+	e.log(zapcore.WarnLevel, msg)
 }
 
 func (e *Entry) Error(msg string) {
-	e.logger.SugaredLogger.Logw(zapcore.ErrorLevel, msg, ContextKey, e.Data)
+	e.log(zapcore.ErrorLevel, msg)
 }
 
 func (e *Entry) Panic(msg string) {
-	e.logger.SugaredLogger.Logw(zapcore.PanicLevel, ContextKey, msg)
+	e.log(zapcore.PanicLevel, msg)
 }
 
 func (e *Entry) WithError(err error) *Entry {
@@ -58,5 +67,28 @@ func (e *Entry) WithFields(fields map[string]interface{}) *Entry {
 		data[k] = v
 	}
 
-	return &Entry{logger: e.logger, Data: data}
+	return &Entry{logger: e.logger, Data: data, debug: e.debug}
+}
+
+// log writes the message if the level is enabled for the entry
+func (e *Entry) log(lvl zapcore.Level, msg string) {
+	if !e.enabled(lvl) {
+		return
+	}
+
+	e.logger.entries.Logw(lvl, msg, ContextKey, e.Data)
+}
+
+// enabled checks the level against the debug flag of the request,
+// then the override of the package of the caller, then the global level
+func (e *Entry) enabled(lvl zapcore.Level) bool {
+	if e.debug {
+		return true
+	}
+
+	if pkgLevel, ok := packageLevel(callerPackage(entryCallerSkip)); ok {
+		return lvl >= pkgLevel
+	}
+
+	return level.Enabled(lvl)
 }
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type debugCtxKey struct{}
+
+var (
+	// level is the minimum level of the logs, it can be changed at runtime
+	level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+	// packageLevels override the level for the packages, keyed by
+	// import path or path relative to the module
+	packageLevels   = map[string]zapcore.Level{}
+	packageLevelsMu sync.RWMutex
+
+	// modulePath is trimmed from the packages matched against the overrides
+	modulePath string
+)
+
+func init() {
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		modulePath = buildInfo.Main.Path
+	}
+}
+
+// ParseLevel parses a level from its name (e.g. debug) or its zap value (e.g. -1)
+func ParseLevel(text string) (zapcore.Level, error) {
+	if value, err := strconv.Atoi(text); err == nil {
+		return zapcore.Level(value), nil
+	}
+
+	return zapcore.ParseLevel(text)
+}
+
+// GetLevel returns the minimum level of the logs
+func GetLevel() zapcore.Level {
+	return level.Level()
+}
+
+// SetLevel changes the minimum level of the logs
+func SetLevel(lvl zapcore.Level) {
+	level.SetLevel(lvl)
+}
+
+// GetPackageLevels returns a copy of the level overrides of the packages
+func GetPackageLevels() map[string]zapcore.Level {
+	packageLevelsMu.RLock()
+	defer packageLevelsMu.RUnlock()
+
+	levels := make(map[string]zapcore.Level, len(packageLevels))
+	for pkg, lvl := range packageLevels {
+		levels[pkg] = lvl
+	}
+
+	return levels
+}
+
+// SetPackageLevel overrides the level for the package and its sub packages,
+// e.g. internal/webhook or github.com/danushk97/image-analyzer/internal/webhook
+func SetPackageLevel(pkg string, lvl zapcore.Level) {
+	packageLevelsMu.Lock()
+	defer packageLevelsMu.Unlock()
+
+	packageLevels[strings.Trim(pkg, "/")] = lvl
+}
+
+// ResetPackageLevel removes the level override of the package
+func ResetPackageLevel(pkg string) {
+	packageLevelsMu.Lock()
+	defer packageLevelsMu.Unlock()
+
+	delete(packageLevels, strings.Trim(pkg, "/"))
+}
+
+// WithDebug enables the debug logs of the entries created from the context
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugCtxKey{}, true)
+}
+
+// isDebug checks if the debug logs are enabled for the context
+func isDebug(ctx context.Context) bool {
+	debug, _ := ctx.Value(debugCtxKey{}).(bool)
+	return debug
+}
+
+// packageLevel returns the level override of the package, the
+// override of the longest matching package is used
+func packageLevel(pkg string) (zapcore.Level, bool) {
+	packageLevelsMu.RLock()
+	defer packageLevelsMu.RUnlock()
+
+	if len(packageLevels) == 0 {
+		return 0, false
+	}
+
+	relative := strings.TrimPrefix(pkg, modulePath+"/")
+
+	var (
+		matched string
+		lvl     zapcore.Level
+	)
+	for key, keyLevel := range packageLevels {
+		if len(key) > len(matched) && (matchesPackage(pkg, key) || matchesPackage(relative, key)) {
+			matched, lvl = key, keyLevel
+		}
+	}
+
+	return lvl, matched != ""
+}
+
+// matchesPackage checks if the package is the given one or one of its sub packages
+func matchesPackage(pkg string, key string) bool {
+	return pkg == key || strings.HasPrefix(pkg, key+"/")
+}
+
+// callerPackage returns the import path of the package of the caller
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+
+	// the name is <import path>.<function>, the path may hold dots
+	// only before its last slash
+	name := fn.Name()
+	lastSlash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[lastSlash+1:], "."); dot >= 0 {
+		return name[:lastSlash+1+dot]
+	}
+
+	return name
+}
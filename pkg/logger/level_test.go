@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{text: "debug", want: zapcore.DebugLevel},
+		{text: "WARN", want: zapcore.WarnLevel},
+		{text: "-1", want: zapcore.DebugLevel},
+		{text: "2", want: zapcore.ErrorLevel},
+		{text: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseLevel(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected an error to be %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPackageLevel(t *testing.T) {
+	defer func(path string) { modulePath = path }(modulePath)
+	modulePath = "github.com/danushk97/image-analyzer"
+
+	SetPackageLevel("internal/webhook", zapcore.DebugLevel)
+	SetPackageLevel("/internal/webhook/service/", zapcore.ErrorLevel)
+	SetPackageLevel("github.com/danushk97/image-analyzer/internal/outbox", zapcore.WarnLevel)
+	defer func() {
+		for pkg := range GetPackageLevels() {
+			ResetPackageLevel(pkg)
+		}
+	}()
+
+	tests := []struct {
+		name string
+		pkg  string
+		want zapcore.Level
+		ok   bool
+	}{
+		{name: "relative package", pkg: "github.com/danushk97/image-analyzer/internal/webhook", want: zapcore.DebugLevel, ok: true},
+		{name: "longest match", pkg: "github.com/danushk97/image-analyzer/internal/webhook/service", want: zapcore.ErrorLevel, ok: true},
+		{name: "sub package", pkg: "github.com/danushk97/image-analyzer/internal/webhook/repo/sql", want: zapcore.DebugLevel, ok: true},
+		{name: "import path", pkg: "github.com/danushk97/image-analyzer/internal/outbox/relay", want: zapcore.WarnLevel, ok: true},
+		{name: "package sharing the prefix", pkg: "github.com/danushk97/image-analyzer/internal/webhooks"},
+		{name: "package without override", pkg: "github.com/danushk97/image-analyzer/internal/image_metadata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := packageLevel(tt.pkg)
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("expected %v, %v, got %v, %v", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+
+	ResetPackageLevel("internal/webhook/service")
+	if got, _ := packageLevel("github.com/danushk97/image-analyzer/internal/webhook/service"); got != zapcore.DebugLevel {
+		t.Errorf("expected the override of the parent package once reset, got %v", got)
+	}
+}
+
+func TestCallerPackage(t *testing.T) {
+	if got := callerPackage(0); got != "github.com/danushk97/image-analyzer/pkg/logger" {
+		t.Errorf("expected the package of the caller, got %q", got)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	// the level of the environment is set along with the logger
+	NewLogger()
+	defer SetLevel(GetLevel())
+	SetLevel(zapcore.WarnLevel)
+
+	if Ctx(context.Background()).enabled(zapcore.InfoLevel) {
+		t.Error("expected the info logs to be disabled below the global level")
+	}
+	if !Ctx(WithDebug(context.Background())).enabled(zapcore.DebugLevel) {
+		t.Error("expected the debug logs to be enabled for the debug requests")
+	}
+}
@@ -2,12 +2,12 @@ package logger
 
 import (
 	"context"
-	"os"
+	"io"
 	"runtime/debug"
-	"strconv"
 	"sync"
 
 	"github.com/danushk97/image-analyzer/pkg/contextkey"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -15,12 +15,19 @@ import (
 type ctxKey struct{}
 
 var (
-	once   sync.Once
+	mu     sync.RWMutex
 	logger *Logger
 )
 
+// Logger logs at the global level, its entries are leveled per request and package
 type Logger struct {
 	*zap.SugaredLogger
+
+	// entries writes the logs of the entries, which check their level themselves
+	entries *zap.SugaredLogger
+
+	// closers release the files of the sinks
+	closers []io.Closer
 }
 
 func (l *Logger) newEntry() *Entry {
@@ -30,62 +37,110 @@ func (l *Logger) newEntry() *Entry {
 	}
 }
 
+// NewLogger returns the logger set up by Init, the JSON logs are
+// written to the standard output until it is called
 func NewLogger() *Logger {
-	once.Do(func() {
-		stdout := zapcore.AddSync(os.Stdout)
+	mu.RLock()
+	current := logger
+	mu.RUnlock()
+	if current != nil {
+		return current
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if logger == nil {
+		// the default sink and encoder cannot fail
+		logger, _ = newLogger(DefaultConfig())
+	}
+
+	return logger
+}
 
-		logLevel, err := strconv.Atoi(os.Getenv("LOG_LEVEL"))
+// Init sets up the logger from the config, replacing the current one
+func Init(config Config) (*Logger, error) {
+	if config.Level != "" {
+		logLevel, err := ParseLevel(config.Level)
 		if err != nil {
-			logLevel = int(zap.InfoLevel)
+			return nil, err
 		}
+		level.SetLevel(logLevel)
+	}
+
+	l, err := newLogger(config)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	previous := logger
+	logger = l
+	mu.Unlock()
+
+	if previous != nil {
+		_ = previous.Sync()
+	}
+
+	return l, nil
+}
 
-		level := zap.NewAtomicLevelAt(zapcore.Level(logLevel))
-
-		productionCfg := zap.NewProductionEncoderConfig()
-		productionCfg.TimeKey = "timestamp"
-		productionCfg.TimeKey = "message"
-		productionCfg.CallerKey = "caller"
-		productionCfg.LevelKey = "level"
-		productionCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-
-		developmentCfg := zap.NewDevelopmentEncoderConfig()
-		developmentCfg.TimeKey = "timestamp"
-		developmentCfg.MessageKey = "message"
-		developmentCfg.CallerKey = "caller"
-		developmentCfg.LevelKey = "level"
-		jsonEncoder := zapcore.NewJSONEncoder(developmentCfg)
-
-		core := zapcore.NewCore(jsonEncoder, stdout, level)
-		if os.Getenv("APP_ENV") != "dev" {
-			core = zapcore.NewTee(
-				zapcore.NewCore(jsonEncoder, stdout, level),
-			)
+// Close flushes the logs and releases the files of the sinks
+func (l *Logger) Close() error {
+	err := l.Sync()
+	for _, closer := range l.closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
 		}
-		var gitRevision string
-
-		buildInfo, ok := debug.ReadBuildInfo()
-		if ok {
-			for _, v := range buildInfo.Settings {
-				if v.Key == "vcs.revision" {
-					gitRevision = v.Value
-					break
-				}
-			}
+	}
+
+	return err
+}
+
+// newLogger creates a logger writing to the sinks of the config
+func newLogger(config Config) (*Logger, error) {
+	core, closers, err := newCore(config)
+	if err != nil {
+		for _, closer := range closers {
+			_ = closer.Close()
 		}
+		return nil, err
+	}
+
+	var gitRevision, goVersion string
 
-		logger = &Logger{
-			zap.New(core).
-				With(
-					zap.String(
-						"git_revision",
-						gitRevision,
-					),
-					zap.String("go_version", buildInfo.GoVersion),
-				).Sugar(),
+	buildInfo, ok := debug.ReadBuildInfo()
+	if ok {
+		for _, v := range buildInfo.Settings {
+			if v.Key == "vcs.revision" {
+				gitRevision = v.Value
+				break
+			}
 		}
-	})
+		goVersion = buildInfo.GoVersion
+	}
 
-	return logger
+	base := zap.New(core).
+		With(
+			zap.String(
+				"git_revision",
+				gitRevision,
+			),
+			zap.String("go_version", goVersion),
+		)
+
+	return &Logger{
+		SugaredLogger: base.WithOptions(
+			zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+				leveled, err := zapcore.NewIncreaseLevelCore(c, level)
+				if err != nil {
+					return c
+				}
+				return leveled
+			}),
+		).Sugar(),
+		entries: base.Sugar(),
+		closers: closers,
+	}, nil
 }
 
 // WithContext returns a copy of ctx with the Logger attached.
@@ -96,6 +151,7 @@ func Ctx(ctx context.Context) *Entry {
 
 	logger := NewLogger()
 	entry := logger.newEntry()
+	entry.debug = isDebug(ctx)
 	ctx = context.WithValue(ctx, ctxKey{}, entry)
 
 	fields := map[string]interface{}{}
@@ -108,6 +164,16 @@ func Ctx(ctx context.Context) *Entry {
 		if val, ok := ctx.Value(contextkey.RequestPath).(string); ok {
 			fields[contextkey.RequestPath.String()] = val
 		}
+
+		if val, ok := ctx.Value(contextkey.UserID).(string); ok {
+			fields[UserIDKey] = val
+		}
+
+		// the logs are correlated with the span of the context
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+			fields[TraceIDKey] = spanCtx.TraceID().String()
+			fields[SpanIDKey] = spanCtx.SpanID().String()
+		}
 	}
 
 	if len(fields) > 0 {
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// Redacted replaces the values of the sensitive fields and the matches of the patterns
+	Redacted = "[REDACTED]"
+	// UserIDKey is the field of the user making the request
+	UserIDKey = "user_id"
+	// hashedUserIDLength is the number of hex characters kept from the hash of the user IDs
+	hashedUserIDLength = 16
+)
+
+// defaultRedactedFields are always redacted, whatever the config
+var defaultRedactedFields = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+	"password",
+	"secret",
+	"token",
+}
+
+// authorizationPattern matches the credentials of the Authorization
+// headers, which are always redacted
+var authorizationPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+
+// userIDFields are hashed when the user IDs are private
+var userIDFields = []string{UserIDKey, "userid"}
+
+// RedactionConfig holds the rules redacting the logs before they are encoded
+type RedactionConfig struct {
+	// Fields are the names of the fields whose values are redacted, case insensitive,
+	// on top of the credentials and the Authorization headers
+	Fields []string
+	// Patterns are the regular expressions whose matches are redacted
+	// in the messages and the values of the fields
+	Patterns []string
+	// HashUserIDs replaces the user IDs with a hash, which still
+	// correlates the logs of a user without revealing it
+	HashUserIDs bool
+}
+
+// redactor applies the redaction rules to the messages and the fields
+type redactor struct {
+	fields      map[string]bool
+	userIDs     map[string]bool
+	patterns    []*regexp.Regexp
+	hashUserIDs bool
+}
+
+// newRedactor compiles the redaction rules of the config
+func newRedactor(config RedactionConfig) (*redactor, error) {
+	r := &redactor{
+		fields:      map[string]bool{},
+		userIDs:     map[string]bool{},
+		patterns:    []*regexp.Regexp{authorizationPattern},
+		hashUserIDs: config.HashUserIDs,
+	}
+
+	for _, field := range defaultRedactedFields {
+		r.fields[field] = true
+	}
+	for _, field := range config.Fields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	for _, field := range userIDFields {
+		r.userIDs[field] = true
+	}
+
+	for _, pattern := range config.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, compiled)
+	}
+
+	return r, nil
+}
+
+// text redacts the matches of the patterns
+func (r *redactor) text(value string) string {
+	for _, pattern := range r.patterns {
+		value = pattern.ReplaceAllString(value, Redacted)
+	}
+
+	return value
+}
+
+// value redacts the value of the field, values of other types
+// than strings and maps are redacted once formatted
+func (r *redactor) value(key string, value interface{}) interface{} {
+	name := strings.ToLower(key)
+	if r.fields[name] {
+		return Redacted
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for k, fieldValue := range v {
+			redacted[k] = r.value(k, fieldValue)
+		}
+		return redacted
+
+	case string:
+		if r.hashUserIDs && r.userIDs[name] {
+			return hashUserID(v)
+		}
+		return r.text(v)
+
+	default:
+		return r.text(fmt.Sprintf("%v", v))
+	}
+}
+
+// field redacts the zap field, the sensitive fields are redacted whatever
+// their type while only the values of the strings, errors, stringers and
+// maps of the other fields are inspected
+func (r *redactor) field(field zapcore.Field) zapcore.Field {
+	if r.fields[strings.ToLower(field.Key)] {
+		return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: Redacted}
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		if value, ok := r.value(field.Key, field.String).(string); ok {
+			field.String = value
+		}
+
+	case zapcore.ErrorType, zapcore.StringerType:
+		field = zapcore.Field{
+			Key:    field.Key,
+			Type:   zapcore.StringType,
+			String: fmt.Sprint(r.value(field.Key, fmt.Sprintf("%v", field.Interface))),
+		}
+
+	case zapcore.ReflectType:
+		if _, ok := field.Interface.(map[string]interface{}); ok {
+			field.Interface = r.value(field.Key, field.Interface)
+		}
+	}
+
+	return field
+}
+
+// redactFields redacts the zap fields, the given slice is left untouched
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.field(field)
+	}
+
+	return redacted
+}
+
+// hashUserID hashes the user ID with SHA-256
+func hashUserID(userID string) string {
+	if userID == "" {
+		return userID
+	}
+
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:])[:hashedUserIDLength]
+}
+
+// redactCore redacts the messages and the fields before they are encoded
+type redactCore struct {
+	zapcore.Core
+
+	redactor *redactor
+}
+
+func newRedactCore(core zapcore.Core, r *redactor) zapcore.Core {
+	return &redactCore{Core: core, redactor: r}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{
+		Core:     c.Core.With(c.redactor.redactFields(fields)),
+		redactor: c.redactor,
+	}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.redactor.text(ent.Message)
+
+	return c.Core.Write(ent, c.redactor.redactFields(fields))
+}
@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// SinkStdout writes the logs to the standard output
+	SinkStdout = "stdout"
+	// SinkFile writes the logs to a file rotated on its size and age
+	SinkFile = "file"
+)
+
+const (
+	// EncodingJSON encodes the logs as JSON objects
+	EncodingJSON = "json"
+	// EncodingConsole encodes the logs as human readable lines
+	EncodingConsole = "console"
+)
+
+// Config holds the logger configurations
+type Config struct {
+	// Level is the minimum level of the logs, e.g. debug or info
+	Level string
+	// Sinks the logs are written to, the standard output when empty
+	Sinks []SinkConfig
+	// Sampling limits the logs repeating the same message
+	Sampling SamplingConfig
+	// Redaction removes the personal data and the credentials from the logs
+	Redaction RedactionConfig
+}
+
+// SinkConfig holds the configurations of a sink
+type SinkConfig struct {
+	// Type of the sink: stdout or file
+	Type string
+	// Encoding of the logs: json or console
+	Encoding string
+	// Path is the file written by the file sink
+	Path string
+	// MaxSize is the size in megabytes the file is rotated at
+	MaxSize int
+	// MaxAge is the number of days the rotated files are kept
+	MaxAge int
+	// MaxBackups is the number of rotated files kept, all when 0
+	MaxBackups int
+	// Compress gzips the rotated files
+	Compress bool
+}
+
+// SamplingConfig holds the sampling configurations, the sampling is
+// disabled when Initial is 0
+type SamplingConfig struct {
+	// Initial is the number of logs with the same level and message
+	// written every Tick
+	Initial int
+	// Thereafter only every Thereafter-th of the next logs is written
+	Thereafter int
+	// Tick is the period in seconds the counts are reset
+	Tick int
+}
+
+// DefaultConfig writes the JSON logs to the standard output
+func DefaultConfig() Config {
+	return Config{
+		Level: zapcore.InfoLevel.String(),
+		Sinks: []SinkConfig{{Type: SinkStdout, Encoding: EncodingJSON}},
+	}
+}
+
+// newCore creates the core writing the redacted logs to all the sinks, sampled when enabled
+func newCore(config Config) (zapcore.Core, []io.Closer, error) {
+	sinks := config.Sinks
+	if len(sinks) == 0 {
+		sinks = DefaultConfig().Sinks
+	}
+
+	var (
+		cores   []zapcore.Core
+		closers []io.Closer
+	)
+	for _, sink := range sinks {
+		encoder, err := newEncoder(sink)
+		if err != nil {
+			return nil, closers, err
+		}
+
+		writer, closer, err := newWriter(sink)
+		if err != nil {
+			return nil, closers, err
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+
+		// the cores accept all the levels, they are checked by the
+		// entries and by the level core of the logger
+		cores = append(cores, zapcore.NewCore(encoder, writer, zapcore.DebugLevel))
+	}
+
+	redactor, err := newRedactor(config.Redaction)
+	if err != nil {
+		return nil, closers, err
+	}
+
+	// the logs are redacted before they are sampled and encoded
+	core := newRedactCore(zapcore.NewTee(cores...), redactor)
+
+	sampling := config.Sampling
+	if sampling.Initial > 0 {
+		tick := time.Duration(sampling.Tick) * time.Second
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, sampling.Initial, sampling.Thereafter)
+	}
+
+	return core, closers, nil
+}
+
+// newEncoder creates the encoder of the logs of the sink
+func newEncoder(sink SinkConfig) (zapcore.Encoder, error) {
+	encoderCfg := zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		FunctionKey:    zapcore.OmitKey,
+		MessageKey:     "message",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+
+	switch sink.Encoding {
+	case "", EncodingJSON:
+		return zapcore.NewJSONEncoder(encoderCfg), nil
+
+	case EncodingConsole:
+		// the files are kept free of the color escape codes
+		if sink.Type != SinkFile {
+			encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		return zapcore.NewConsoleEncoder(encoderCfg), nil
+
+	default:
+		return nil, fmt.Errorf("unknown log encoding: %v", sink.Encoding)
+	}
+}
+
+// newWriter creates the writer of the sink along with its closer, if any
+func newWriter(sink SinkConfig) (zapcore.WriteSyncer, io.Closer, error) {
+	switch sink.Type {
+	case "", SinkStdout:
+		return zapcore.Lock(os.Stdout), nil, nil
+
+	case SinkFile:
+		if sink.Path == "" {
+			return nil, nil, fmt.Errorf("missing path of the file log sink")
+		}
+		file := &lumberjack.Logger{
+			Filename:   sink.Path,
+			MaxSize:    sink.MaxSize,
+			MaxAge:     sink.MaxAge,
+			MaxBackups: sink.MaxBackups,
+			Compress:   sink.Compress,
+			LocalTime:  true,
+		}
+		return zapcore.AddSync(file), file, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown log sink: %v", sink.Type)
+	}
+}
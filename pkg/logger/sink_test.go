@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// readLines returns the lines written to the file of a sink
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestNewCoreSinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		sinks   []SinkConfig
+		wantErr string
+	}{
+		{name: "default sink"},
+		{name: "stdout console", sinks: []SinkConfig{{Type: SinkStdout, Encoding: EncodingConsole}}},
+		{name: "file without path", sinks: []SinkConfig{{Type: SinkFile}}, wantErr: "missing path"},
+		{name: "unknown sink", sinks: []SinkConfig{{Type: "syslog"}}, wantErr: "unknown log sink"},
+		{name: "unknown encoding", sinks: []SinkConfig{{Type: SinkStdout, Encoding: "logfmt"}}, wantErr: "unknown log encoding"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newCore(Config{Sinks: tt.sinks})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected an error with %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewCoreFileSinks(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "app.json.log")
+	consolePath := filepath.Join(dir, "app.log")
+
+	core, closers, err := newCore(Config{Sinks: []SinkConfig{
+		{Type: SinkFile, Encoding: EncodingJSON, Path: jsonPath},
+		{Type: SinkFile, Encoding: EncodingConsole, Path: consolePath},
+	}})
+	if err != nil {
+		t.Fatalf("new core: %v", err)
+	}
+
+	entry := zapcore.Entry{Level: zapcore.DebugLevel, Message: "IMAGE_CREATED"}
+	if err := core.Write(entry, []zapcore.Field{{Key: "image_id", Type: zapcore.StringType, String: "image_1"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	}
+
+	var log map[string]interface{}
+	if err := json.Unmarshal([]byte(readLines(t, jsonPath)[0]), &log); err != nil {
+		t.Fatalf("expected a JSON log, got %v", err)
+	}
+	if log["message"] != "IMAGE_CREATED" || log["level"] != "DEBUG" || log["image_id"] != "image_1" {
+		t.Errorf("unexpected JSON log %v", log)
+	}
+
+	line := readLines(t, consolePath)[0]
+	if !strings.Contains(line, "IMAGE_CREATED") || strings.Contains(line, "\x1b[") {
+		t.Errorf("expected a console log without colors, got %q", line)
+	}
+}
+
+func TestNewCoreSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	core, closers, err := newCore(Config{
+		Sinks:    []SinkConfig{{Type: SinkFile, Path: path}},
+		Sampling: SamplingConfig{Initial: 2, Thereafter: 3, Tick: 60},
+	})
+	if err != nil {
+		t.Fatalf("new core: %v", err)
+	}
+
+	for i := 0; i < 8; i++ {
+		entry := zapcore.Entry{Level: zapcore.InfoLevel, Message: "POLL"}
+		if checked := core.Check(entry, nil); checked != nil {
+			checked.Write()
+		}
+	}
+	for _, closer := range closers {
+		_ = closer.Close()
+	}
+
+	// the first 2 logs, then every 3rd of the next ones: the 5th and the 8th
+	if lines := readLines(t, path); len(lines) != 4 {
+		t.Errorf("expected 4 sampled logs, got %d", len(lines))
+	}
+}
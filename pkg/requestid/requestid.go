@@ -0,0 +1,42 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+
+	"github.com/danushk97/image-analyzer/pkg/contextkey"
+)
+
+// MaxLength bounds the length of the IDs accepted from the callers
+const MaxLength = 128
+
+// pattern restricts the IDs accepted from the callers to the characters
+// safe to log and to echo back in a header
+var pattern = regexp.MustCompile(`^[A-Za-z0-9._:\-]+$`)
+
+// IsValid returns true if the ID supplied by a caller can be used as is
+func IsValid(id string) bool {
+	return len(id) <= MaxLength && pattern.MatchString(id)
+}
+
+// New generates a new request ID
+func New() string {
+	return uuid.NewString()
+}
+
+// FromContext returns the request ID of the context, empty if there is none
+func FromContext(ctx context.Context) string {
+	return contextkey.GetFromFromCtx(ctx, contextkey.RequestID)
+}
+
+// WithContext sets the request ID a job was created by in the context,
+// the context is returned as is if the job has no request ID
+func WithContext(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+
+	return contextkey.SetInContext(ctx, contextkey.RequestID, id)
+}
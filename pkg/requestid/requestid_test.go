@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		valid bool
+	}{
+		{name: "uuid", id: "0b5c4c3e-6f0a-4bd5-9d4c-1f7d2a9f8e21", valid: true},
+		{name: "dotted with colons", id: "gateway:req.42_a", valid: true},
+		{name: "max length", id: strings.Repeat("a", MaxLength), valid: true},
+		{name: "too long", id: strings.Repeat("a", MaxLength+1)},
+		{name: "empty", id: ""},
+		{name: "header injection", id: "req\r\nSet-Cookie: a=b"},
+		{name: "spaces", id: "req 42"},
+		{name: "non ascii", id: "réq"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValid(tt.id); got != tt.valid {
+				t.Errorf("expected IsValid(%q) to be %v, got %v", tt.id, tt.valid, got)
+			}
+		})
+	}
+}
+
+func TestNewIsValid(t *testing.T) {
+	if id := New(); !IsValid(id) {
+		t.Errorf("expected the generated ID to be valid, got %q", id)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx := WithContext(context.Background(), "req_1")
+	if got := FromContext(ctx); got != "req_1" {
+		t.Errorf("expected the request ID of the job, got %q", got)
+	}
+
+	// the jobs created before the request IDs were stored keep the ID of the context
+	if got := FromContext(WithContext(ctx, "")); got != "req_1" {
+		t.Errorf("expected the request ID to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,435 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+const (
+	errNoRowAffected   = "no_row_affected"
+	errUniqueViolation = "unique_violation"
+	errInvalidModel    = "invalid_model"
+)
+
+type contextKey int
+
+const (
+	// used to set the transaction in context
+	contextKeyTx contextKey = iota
+)
+
+// tables holds the copies of the records keyed by table and ID, the
+// committed tables are never changed in place so that they are shared
+type tables map[string]map[string]sql.IModel
+
+// clone copies the list of the tables, the tables themselves are shared
+func (t tables) clone() tables {
+	cloned := make(tables, len(t))
+	for name, records := range t {
+		cloned[name] = records
+	}
+
+	return cloned
+}
+
+// tx is a transaction working on its own view of the tables, a table
+// is copied the first time it is written so that the writes only copy
+// the tables they touch
+type tx struct {
+	tables tables
+	// owned are the tables copied by the transaction, changed in place
+	owned map[string]bool
+}
+
+// newTx starts a transaction on the committed tables
+func newTx(committed tables) *tx {
+	return &tx{tables: committed.clone(), owned: map[string]bool{}}
+}
+
+// table returns the records of the table to be changed, it is copied
+// on the first write and created if missing
+func (t *tx) table(name string) map[string]sql.IModel {
+	if t.owned[name] {
+		return t.tables[name]
+	}
+
+	records := make(map[string]sql.IModel, len(t.tables[name]))
+	for id, record := range t.tables[name] {
+		records[id] = record
+	}
+	t.tables[name] = records
+	t.owned[name] = true
+
+	return records
+}
+
+// savepoint marks the current state of the transaction, the tables are
+// copied again on their next write so that the state can be restored
+func (t *tx) savepoint() *tx {
+	saved := &tx{tables: t.tables.clone(), owned: t.owned}
+	t.owned = map[string]bool{}
+
+	return saved
+}
+
+// rollback restores the state of the savepoint
+func (t *tx) rollback(saved *tx) {
+	t.tables = saved.tables
+	t.owned = saved.owned
+}
+
+// release keeps the changes made since the savepoint
+func (t *tx) release(saved *tx) {
+	for name := range saved.owned {
+		t.owned[name] = true
+	}
+}
+
+// Store is a thread safe storage of the models held in memory, e.g. for the
+// unit tests and the local development. The transactions work on a copy of
+// the tables they write which replaces them on commit, they are serialized
+// along with the writes made outside of them. The reads outside of the
+// transactions see the committed records.
+type Store struct {
+	// writeMu serializes the transactions and the writes
+	writeMu sync.Mutex
+
+	mu      sync.RWMutex
+	tables  tables
+	schemas sync.Map
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{tables: tables{}}
+}
+
+// Create inserts a copy of the receiver, the ID and the timestamps
+// are set as by the sql storage
+func (s *Store) Create(ctx context.Context, receiver sql.IModel) errors.IError {
+	if err := receiver.SetDefaults(); err != nil {
+		return err
+	}
+
+	if err := receiver.Validate(); err != nil {
+		return err
+	}
+
+	modelSchema, err := s.schema(receiver)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(receiver).Elem()
+	now := time.Now().Unix()
+	for _, field := range modelSchema.Fields {
+		_, zero := field.ValueOf(ctx, value)
+		switch {
+		case field.PrimaryKey && zero:
+			setField(ctx, field, value, uuid.New().String())
+		case (field.AutoCreateTime > 0 || field.AutoUpdateTime > 0) && zero:
+			setField(ctx, field, value, now)
+		}
+	}
+
+	return s.write(ctx, func(t *tx) errors.IError {
+		records := t.table(receiver.TableName())
+		if _, ok := records[receiver.GetID()]; ok {
+			return UniqueViolationError(sql.AttributeID)
+		}
+
+		records[receiver.GetID()] = copyModel(receiver)
+		return nil
+	})
+}
+
+// FindByID loads the record with the ID into the receiver
+func (s *Store) FindByID(ctx context.Context, receiver sql.IModel, id string) errors.IError {
+	record, ok := s.read(ctx)[receiver.TableName()][id]
+	if !ok {
+		return RecordNotFoundError()
+	}
+
+	reflect.ValueOf(receiver).Elem().Set(reflect.ValueOf(copyModel(record)).Elem())
+	return nil
+}
+
+// Update updates the given attributes of the receiver identified by its primary key
+// if no attributes are given then all the non zero fields are updated
+func (s *Store) Update(ctx context.Context, receiver sql.IModel, attributes ...string) errors.IError {
+	if err := receiver.Validate(); err != nil {
+		return err
+	}
+
+	modelSchema, err := s.schema(receiver)
+	if err != nil {
+		return err
+	}
+
+	return s.write(ctx, func(t *tx) errors.IError {
+		records := t.table(receiver.TableName())
+		record, ok := records[receiver.GetID()]
+		if !ok {
+			return errors.NewBadRequestError(errNoRowAffected)
+		}
+
+		value := reflect.ValueOf(receiver).Elem()
+		now := time.Now().Unix()
+		for _, field := range modelSchema.Fields {
+			if field.AutoUpdateTime > 0 {
+				setField(ctx, field, value, now)
+			}
+		}
+
+		// the values of the receiver are copied, it may be changed by the caller
+		source := reflect.ValueOf(copyModel(receiver)).Elem()
+		updated := copyModel(record)
+		updatedValue := reflect.ValueOf(updated).Elem()
+		for _, field := range modelSchema.Fields {
+			fieldValue, zero := field.ValueOf(ctx, source)
+			if field.PrimaryKey || !field.Updatable || !isUpdated(field, zero, attributes) {
+				continue
+			}
+			setField(ctx, field, updatedValue, fieldValue)
+		}
+
+		records[receiver.GetID()] = updated
+		return nil
+	})
+}
+
+// Delete deletes the record of the given model
+func (s *Store) Delete(ctx context.Context, receiver sql.IModel) errors.IError {
+	return s.write(ctx, func(t *tx) errors.IError {
+		delete(t.table(receiver.TableName()), receiver.GetID())
+		return nil
+	})
+}
+
+// Each calls fn with a copy of every record of the table of the model, in the order of their IDs
+func (s *Store) Each(ctx context.Context, model sql.IModel, fn func(record sql.IModel)) {
+	records := s.read(ctx)[model.TableName()]
+
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		fn(copyModel(records[id]))
+	}
+}
+
+// DeleteWhere deletes the records of the table of the model matching
+// the function and returns the number of records deleted
+func (s *Store) DeleteWhere(
+	ctx context.Context,
+	model sql.IModel,
+	match func(record sql.IModel) bool,
+) (int64, errors.IError) {
+	var deleted int64
+	err := s.write(ctx, func(t *tx) errors.IError {
+		records := t.table(model.TableName())
+		for id, record := range records {
+			if match(record) {
+				delete(records, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+
+	return deleted, err
+}
+
+// Transaction will manage the execution inside a transaction
+// adds the transaction in the context for downstream use case.
+// If a transaction is already active then the changes of the function
+// are rolled back on its own, as with a savepoint. The options are
+// ignored as the transactions are serialized.
+func (s *Store) Transaction(
+	ctx context.Context,
+	fc func(ctx context.Context) errors.IError,
+	_ ...transaction.Option,
+) errors.IError {
+	if active, ok := ctx.Value(contextKeyTx).(*tx); ok {
+		saved := active.savepoint()
+		if err := fc(ctx); err != nil {
+			active.rollback(saved)
+			return err
+		}
+		active.release(saved)
+		return nil
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	active := newTx(s.tables)
+	s.mu.RUnlock()
+
+	if err := fc(context.WithValue(ctx, contextKeyTx, active)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tables = active.tables
+	s.mu.Unlock()
+
+	return nil
+}
+
+// IsTransactionActive returns true if a transaction is active
+func (s *Store) IsTransactionActive(ctx context.Context) bool {
+	_, ok := ctx.Value(contextKeyTx).(*tx)
+	return ok
+}
+
+// RecordNotFoundError is returned when no record matches, it is
+// recognized by sql.IsRecordNotFoundError as the error of the sql storage
+func RecordNotFoundError() errors.IError {
+	return sql.ToDBError(gorm.ErrRecordNotFound)
+}
+
+// UniqueViolationError is returned when a record conflicts with another one on the field
+func UniqueViolationError(field string) errors.IError {
+	return errors.NewConflictError(errUniqueViolation).
+		WithDetails(errors.FieldError{Field: field, Message: "must be unique"})
+}
+
+// read returns the tables of the transaction in progress, else the committed ones
+func (s *Store) read(ctx context.Context) tables {
+	if active, ok := ctx.Value(contextKeyTx).(*tx); ok {
+		return active.tables
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tables
+}
+
+// write applies the change to the transaction in progress, else to a
+// transaction of its own which commits it
+func (s *Store) write(ctx context.Context, change func(t *tx) errors.IError) errors.IError {
+	if active, ok := ctx.Value(contextKeyTx).(*tx); ok {
+		return change(active)
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) errors.IError {
+		return s.write(ctx, change)
+	})
+}
+
+// schema parses the fields of the model, the schemas are cached
+func (s *Store) schema(model sql.IModel) (*schema.Schema, errors.IError) {
+	modelSchema, err := schema.Parse(model, &s.schemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, errors.NewServerError(errInvalidModel).Wrap(err)
+	}
+
+	return modelSchema, nil
+}
+
+// isUpdated checks if the field is updated, the given attributes and the
+// update time are updated, all the non zero fields when none is given
+func isUpdated(field *schema.Field, zero bool, attributes []string) bool {
+	if field.AutoUpdateTime > 0 {
+		return true
+	}
+	if len(attributes) == 0 {
+		return !zero
+	}
+
+	for _, attribute := range attributes {
+		if attribute == field.DBName || attribute == field.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setField sets the value of the field, the values are of the type of the field
+func setField(ctx context.Context, field *schema.Field, value reflect.Value, fieldValue interface{}) {
+	_ = field.Set(ctx, value, fieldValue)
+}
+
+// copyModel returns a deep copy of the model, the stored records share
+// no pointer, slice or map with the models of the callers
+func copyModel(model sql.IModel) sql.IModel {
+	value := reflect.ValueOf(model).Elem()
+	copied := reflect.New(value.Type())
+	copied.Elem().Set(deepCopy(value))
+
+	return copied.Interface().(sql.IModel)
+}
+
+// deepCopy returns a copy of the value and of the values it references,
+// the unexported fields of the structs are copied as they are
+func deepCopy(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(deepCopy(value.Elem()))
+		return copied
+
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(deepCopy(value.Elem()))
+		return copied
+
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(deepCopy(value.Field(i)))
+			}
+		}
+		return copied
+
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopy(value.Index(i)))
+		}
+		return copied
+
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+
+	default:
+		return value
+	}
+}
@@ -0,0 +1,319 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// note is the model stored by the tests
+type note struct {
+	sql.Model
+	Text     string
+	Priority *int
+}
+
+func (n *note) TableName() string          { return "notes" }
+func (n *note) EntityName() string         { return "note" }
+func (n *note) SetDefaults() errors.IError { return nil }
+func (n *note) Validate() errors.IError    { return n.Model.Validate() }
+
+// text returns the stored text of the note, empty if it is not found
+func text(ctx context.Context, s *Store, id string) string {
+	found := &note{}
+	if err := s.FindByID(ctx, found, id); err != nil {
+		return ""
+	}
+	return found.Text
+}
+
+// tag is the model of another table
+type tag struct {
+	sql.Model
+}
+
+func (t *tag) TableName() string          { return "tags" }
+func (t *tag) EntityName() string         { return "tag" }
+func (t *tag) SetDefaults() errors.IError { return nil }
+func (t *tag) Validate() errors.IError    { return t.Model.Validate() }
+
+// count returns the number of records of the table of the model
+func count(ctx context.Context, s *Store, model sql.IModel) int {
+	var n int
+	s.Each(ctx, model, func(sql.IModel) { n++ })
+	return n
+}
+
+func TestCreateFindUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	n := &note{Text: "first"}
+	if err := s.Create(ctx, n); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if n.GetID() == "" || n.GetCreatedAt() == 0 || n.GetUpdatedAt() == 0 {
+		t.Fatalf("expected the ID and the timestamps to be set, got %+v", n)
+	}
+
+	// the stored record is a copy of the receiver
+	n.Text = "changed in place"
+	if got := text(ctx, s, n.GetID()); got != "first" {
+		t.Errorf("expected the stored text, got %q", got)
+	}
+
+	n.Text = "second"
+	if err := s.Update(ctx, n, "text"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := text(ctx, s, n.GetID()); got != "second" {
+		t.Errorf("expected the updated text, got %q", got)
+	}
+
+	if err := s.Delete(ctx, n); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.FindByID(ctx, &note{}, n.GetID()); !sql.IsRecordNotFoundError(err) {
+		t.Errorf("expected the record not to be found, got %v", err)
+	}
+}
+
+func TestRecordsAreDeepCopied(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	priority := 1
+	n := &note{Text: "first", Priority: &priority}
+	if err := s.Create(ctx, n); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// the pointers of the receiver are not shared with the stored record
+	priority = 2
+	found := &note{}
+	if err := s.FindByID(ctx, found, n.GetID()); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Priority == nil || *found.Priority != 1 {
+		t.Fatalf("expected the stored priority 1, got %v", found.Priority)
+	}
+
+	// nor the pointers of the found records
+	*found.Priority = 3
+	if err := s.Update(ctx, found, "priority"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	*found.Priority = 4
+
+	again := &note{}
+	if err := s.FindByID(ctx, again, n.GetID()); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if *again.Priority != 3 {
+		t.Errorf("expected the updated priority 3, got %d", *again.Priority)
+	}
+	if again.Priority == found.Priority {
+		t.Error("expected each found record to hold its own pointers")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	n := &note{Text: "first"}
+	if err := s.Create(ctx, n); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// the not found error is the one of the sql storage
+	err := s.FindByID(ctx, &note{}, "missing")
+	if !sql.IsRecordNotFoundError(err) || !err.IsOfType(errors.NOT_FOUND_ERROR) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	// the unique violation is a conflict on the field
+	duplicate := &note{Model: sql.Model{ID: n.GetID()}, Text: "duplicate"}
+	err = s.Create(ctx, duplicate)
+	if err == nil || !err.IsOfType(errors.CONFLICT_ERROR) || err.Error() != errUniqueViolation {
+		t.Fatalf("expected a unique violation, got %v", err)
+	}
+	if details := err.Details(); len(details) != 1 || details[0].Field != sql.AttributeID {
+		t.Errorf("expected the ID in the details, got %+v", details)
+	}
+	if got := text(ctx, s, n.GetID()); got != "first" {
+		t.Errorf("expected the record to be kept, got %q", got)
+	}
+
+	if err = s.Update(ctx, &note{Model: sql.Model{ID: "missing"}}); err == nil || err.Error() != errNoRowAffected {
+		t.Errorf("expected no row to be affected, got %v", err)
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	kept := &note{Text: "kept"}
+	if err := s.Create(ctx, kept); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	err := s.Transaction(ctx, func(ctx context.Context) errors.IError {
+		if err := s.Create(ctx, &note{Text: "rolled back"}); err != nil {
+			return err
+		}
+		if err := s.Create(ctx, &tag{}); err != nil {
+			return err
+		}
+		kept.Text = "changed"
+		if err := s.Update(ctx, kept, "text"); err != nil {
+			return err
+		}
+
+		// the transaction reads its own writes, the others do not
+		if n := count(ctx, s, &note{}); n != 2 {
+			t.Errorf("expected 2 notes in the transaction, got %d", n)
+		}
+		if n := count(context.Background(), s, &note{}); n != 1 {
+			t.Errorf("expected the committed note only outside the transaction, got %d", n)
+		}
+
+		return errors.NewConflictError("rolled back")
+	})
+	if err == nil {
+		t.Fatal("expected the error of the transaction")
+	}
+
+	if n := count(ctx, s, &note{}); n != 1 {
+		t.Errorf("expected the created note to be rolled back, got %d notes", n)
+	}
+	if n := count(ctx, s, &tag{}); n != 0 {
+		t.Errorf("expected the created tag to be rolled back, got %d tags", n)
+	}
+	if got := text(ctx, s, kept.GetID()); got != "kept" {
+		t.Errorf("expected the update to be rolled back, got %q", got)
+	}
+}
+
+func TestTransactionSavepoint(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	err := s.Transaction(ctx, func(ctx context.Context) errors.IError {
+		outer := &note{Text: "outer"}
+		if err := s.Create(ctx, outer); err != nil {
+			return err
+		}
+
+		// the failed nested transaction only rolls back its own changes,
+		// including the ones on the tables already written before it
+		err := s.Transaction(ctx, func(ctx context.Context) errors.IError {
+			if err := s.Create(ctx, &note{Text: "inner"}); err != nil {
+				return err
+			}
+			if err := s.Create(ctx, &tag{}); err != nil {
+				return err
+			}
+			outer.Text = "changed"
+			if err := s.Update(ctx, outer, "text"); err != nil {
+				return err
+			}
+			return errors.NewConflictError("rolled back")
+		})
+		if err == nil {
+			t.Error("expected the error of the nested transaction")
+		}
+
+		if n := count(ctx, s, &note{}); n != 1 {
+			t.Errorf("expected the note of the outer transaction only, got %d", n)
+		}
+		if got := text(ctx, s, outer.GetID()); got != "outer" {
+			t.Errorf("expected the update to be rolled back, got %q", got)
+		}
+
+		// the succeeded nested transaction is kept
+		return s.Transaction(ctx, func(ctx context.Context) errors.IError {
+			return s.Create(ctx, &tag{})
+		})
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+
+	if n := count(ctx, s, &note{}); n != 1 {
+		t.Errorf("expected 1 note, got %d", n)
+	}
+	if n := count(ctx, s, &tag{}); n != 1 {
+		t.Errorf("expected the tag of the nested transaction, got %d", n)
+	}
+}
+
+func TestTransactionCopiesTheWrittenTablesOnly(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	if err := s.Create(ctx, &tag{}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	tags := s.tables["tags"]
+
+	if err := s.Create(ctx, &note{Text: "first"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// the committed table which was not written is shared
+	if reflect.ValueOf(s.tables["tags"]).Pointer() != reflect.ValueOf(tags).Pointer() {
+		t.Error("expected the tags not to be copied by the write of a note")
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	counter := &note{Text: "0"}
+	if err := s.Create(ctx, counter); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// the read and the write of the counter are not interleaved
+			// with the ones of the other transactions
+			err := s.Transaction(ctx, func(ctx context.Context) errors.IError {
+				var value int
+				_, _ = fmt.Sscan(text(ctx, s, counter.GetID()), &value)
+
+				if err := s.Create(ctx, &tag{}); err != nil {
+					return err
+				}
+				return s.Update(ctx, &note{Model: sql.Model{ID: counter.GetID()}, Text: fmt.Sprint(value + 1)}, "text")
+			})
+			if err != nil {
+				t.Errorf("transaction: %v", err)
+			}
+
+			// the reads outside of the transactions do not race with them
+			count(context.Background(), s, &tag{})
+		}()
+	}
+	wg.Wait()
+
+	if got := text(ctx, s, counter.GetID()); got != fmt.Sprint(writers) {
+		t.Errorf("expected the counter to be %d, got %s", writers, got)
+	}
+	if n := count(ctx, s, &tag{}); n != writers {
+		t.Errorf("expected %d tags, got %d", writers, n)
+	}
+}
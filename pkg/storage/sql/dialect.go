@@ -0,0 +1,18 @@
+package sql
+
+import (
+	"fmt"
+)
+
+// JSONField returns the expression reading the field of the JSON object
+// stored in the column as text, the column and the field are trusted
+func (db *DB) JSONField(column string, field string) string {
+	switch db.GetDialect() {
+	case DialectMySQL:
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '$.%s'))", column, field)
+	case DialectSQLite:
+		return fmt.Sprintf("json_extract(%s, '$.%s')", column, field)
+	default:
+		return fmt.Sprintf("%s::jsonb ->> '%s'", column, field)
+	}
+}
@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+)
+
+func TestJSONField(t *testing.T) {
+	for dialect, want := range map[string]string{
+		DialectPostgres: "payload::jsonb ->> 'user_id'",
+		DialectMySQL:    "JSON_UNQUOTE(JSON_EXTRACT(payload, '$.user_id'))",
+		DialectSQLite:   "json_extract(payload, '$.user_id')",
+	} {
+		db := &DB{dbConfig: &DbConnectionConfig{Dialect: dialect}}
+		if got := db.JSONField("payload", "user_id"); got != want {
+			t.Errorf("JSONField() of %s = %q, want %q", dialect, got, want)
+		}
+	}
+}
+
+func TestMySQLConnectionPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		url      string
+		port     int
+		username string
+		password string
+		net      string
+		addr     string
+	}{
+		{name: "tcp", url: "db.local", port: 3306, username: "app", password: "secret", net: "tcp", addr: "db.local:3306"},
+		{name: "separators in the credentials", url: "db.local", port: 3306, username: "app", password: "p@ss/w:rd?x=1", net: "tcp", addr: "db.local:3306"},
+		{name: "unix socket", protocol: "unix", url: "/var/run/mysqld/mysqld.sock", username: "app", password: "secret", net: "unix", addr: "/var/run/mysqld/mysqld.sock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DbConnectionConfig{
+				Dialect:  DialectMySQL,
+				Protocol: tt.protocol,
+				URL:      tt.url,
+				Port:     tt.port,
+				Username: tt.username,
+				Password: tt.password,
+				Name:     "images",
+			}
+
+			// the driver reads back the settings of the connection path
+			dsn, err := mysqlDriver.ParseDSN(config.GetConnectionPath())
+			if err != nil {
+				t.Fatalf("parse %q: %v", config.GetConnectionPath(), err)
+			}
+			if dsn.User != tt.username || dsn.Passwd != tt.password || dsn.Net != tt.net ||
+				dsn.Addr != tt.addr || dsn.DBName != "images" {
+				t.Errorf("unexpected connection settings %+v", dsn)
+			}
+			if !dsn.ParseTime || dsn.Loc != time.UTC || dsn.Params["charset"] != "utf8mb4" {
+				t.Errorf("expected the UTC times and the utf8mb4 charset, got %+v", dsn)
+			}
+		})
+	}
+}
@@ -1,32 +1,109 @@
 package sql
 
 import (
+	"context"
 	goerr "errors"
+	"regexp"
+	"strings"
 
 	"github.com/danushk97/image-analyzer/pkg/errors"
+	sqlite "github.com/glebarez/go-sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
 const (
-	errDBError           = "db_error"
-	errNoRowAffected     = "no_row_affected"
-	errRecordNotFound    = "record_not_found"
-	errValidationFailure = "validation_failure"
+	errDBError              = "db_error"
+	errNoRowAffected        = "no_row_affected"
+	errRecordNotFound       = "record_not_found"
+	errValidationFailure    = "validation_failure"
+	errUniqueViolation      = "unique_violation"
+	errForeignKeyViolation  = "foreign_key_violation"
+	errCheckViolation       = "check_violation"
+	errNotNullViolation     = "not_null_violation"
+	errSerializationFailure = "serialization_failure"
+	errDeadlockDetected     = "deadlock_detected"
+	errStatementTimeout     = "statement_timeout"
+	errLockNotAvailable     = "lock_not_available"
 )
 
+// Postgres error codes, refer https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgCodeNotNullViolation     = "23502"
+	pgCodeForeignKeyViolation  = "23503"
+	pgCodeUniqueViolation      = "23505"
+	pgCodeCheckViolation       = "23514"
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlockDetected     = "40P01"
+	pgCodeQueryCanceled        = "57014"
+	pgCodeLockNotAvailable     = "55P03"
+)
+
+// MySQL error numbers, refer https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	mysqlNumberNotNullViolation    = 1048
+	mysqlNumberUniqueViolation     = 1062
+	mysqlNumberLockWaitTimeout     = 1205
+	mysqlNumberDeadlockDetected    = 1213
+	mysqlNumberForeignKeyViolation = 1452
+	mysqlNumberQueryInterrupted    = 3024
+	mysqlNumberLockNotAvailable    = 3572
+	mysqlNumberCheckViolation      = 3819
+)
+
+// SQLite extended result codes, refer https://www.sqlite.org/rescode.html
+const (
+	sqliteCodeBusy                = 5
+	sqliteCodeCheckViolation      = 275
+	sqliteCodeBusySnapshot        = 517
+	sqliteCodeForeignKeyViolation = 787
+	sqliteCodeNotNullViolation    = 1299
+	sqliteCodePrimaryKeyViolation = 1555
+	sqliteCodeUniqueViolation     = 2067
+)
+
+// mysqlNamePattern matches the name of the constraint or the column
+// quoted in the messages of the MySQL errors
+var mysqlNamePattern = regexp.MustCompile("(?:key|CONSTRAINT|constraint|Column) [`']([^`']+)[`']")
+
 // GetDBError accepts db instance and the details
 // creates appropriate error based on the type of query result
 // if there is no error then returns nil
 func GetDBError(db *gorm.DB) errors.IError {
-	if db.Error == nil {
+	return ToDBError(db.Error)
+}
+
+// ToDBError creates appropriate error based on the error returned
+// by the database. if there is no error then returns nil
+func ToDBError(dbErr error) errors.IError {
+	if dbErr == nil {
 		return nil
 	}
 
 	// Construct error based on type of db operation
 	err := func() errors.IError {
+		var (
+			pgErr     *pgconn.PgError
+			mysqlErr  *mysql.MySQLError
+			sqliteErr *sqlite.Error
+		)
+
 		switch true {
-		case goerr.Is(db.Error, gorm.ErrRecordNotFound):
-			return errors.NewBadRequestError(errRecordNotFound)
+		case goerr.Is(dbErr, gorm.ErrRecordNotFound):
+			return errors.NewNotFoundError(errRecordNotFound)
+
+		case goerr.Is(dbErr, context.DeadlineExceeded):
+			return errors.NewUnavailableError(errStatementTimeout)
+
+		case goerr.As(dbErr, &pgErr):
+			return getPgError(pgErr)
+
+		case goerr.As(dbErr, &mysqlErr):
+			return getMySQLError(mysqlErr)
+
+		case goerr.As(dbErr, &sqliteErr):
+			return getSQLiteError(sqliteErr)
 
 		default:
 			return errors.NewServerError(errDBError)
@@ -34,15 +111,184 @@ func GetDBError(db *gorm.DB) errors.IError {
 	}()
 
 	// add specific details of error
-	return err.Wrap(db.Error)
+	return err.Wrap(dbErr)
+}
+
+// getPgError maps the postgres error codes to the domain errors
+func getPgError(pgErr *pgconn.PgError) errors.IError {
+	switch pgErr.Code {
+	case pgCodeUniqueViolation:
+		return errors.NewConflictError(errUniqueViolation).
+			WithDetails(constraintDetail(pgErr, "must be unique"))
+
+	case pgCodeForeignKeyViolation:
+		return errors.NewUnprocessableError(errForeignKeyViolation).
+			WithDetails(constraintDetail(pgErr, "must reference an existing record"))
+
+	case pgCodeCheckViolation:
+		return errors.NewUnprocessableError(errCheckViolation).
+			WithDetails(constraintDetail(pgErr, "is not a valid value"))
+
+	case pgCodeNotNullViolation:
+		return errors.NewUnprocessableError(errNotNullViolation).
+			WithDetails(errors.FieldError{
+				Field:   pgErr.ColumnName,
+				Message: "cannot be blank",
+			})
+
+	case pgCodeSerializationFailure:
+		return errors.NewConflictError(errSerializationFailure).AsRetryable()
+
+	case pgCodeDeadlockDetected:
+		return errors.NewConflictError(errDeadlockDetected).AsRetryable()
+
+	case pgCodeQueryCanceled:
+		return errors.NewUnavailableError(errStatementTimeout)
+
+	case pgCodeLockNotAvailable:
+		return errors.NewConflictError(errLockNotAvailable)
+
+	default:
+		return errors.NewServerError(errDBError)
+	}
+}
+
+// getMySQLError maps the MySQL error numbers to the domain errors
+func getMySQLError(mysqlErr *mysql.MySQLError) errors.IError {
+	// the names are only given in the messages, e.g. in
+	// Duplicate entry 'x' for key 'images_metadata.idx'
+	name := ""
+	if match := mysqlNamePattern.FindStringSubmatch(mysqlErr.Message); match != nil {
+		name = match[1]
+	}
+
+	switch mysqlErr.Number {
+	case mysqlNumberUniqueViolation:
+		return errors.NewConflictError(errUniqueViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "must be unique"})
+
+	case mysqlNumberForeignKeyViolation:
+		return errors.NewUnprocessableError(errForeignKeyViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "must reference an existing record"})
+
+	case mysqlNumberCheckViolation:
+		return errors.NewUnprocessableError(errCheckViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "is not a valid value"})
+
+	case mysqlNumberNotNullViolation:
+		return errors.NewUnprocessableError(errNotNullViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "cannot be blank"})
+
+	// InnoDB rolls back the statement on a lock wait timeout, the
+	// transaction can be retried as a whole as for the deadlocks
+	case mysqlNumberDeadlockDetected, mysqlNumberLockWaitTimeout:
+		return errors.NewConflictError(errDeadlockDetected).AsRetryable()
+
+	case mysqlNumberQueryInterrupted:
+		return errors.NewUnavailableError(errStatementTimeout)
+
+	case mysqlNumberLockNotAvailable:
+		return errors.NewConflictError(errLockNotAvailable)
+
+	default:
+		return errors.NewServerError(errDBError)
+	}
+}
+
+// constraintDetail names the violated constraint in the error details
+func constraintDetail(pgErr *pgconn.PgError, message string) errors.FieldError {
+	field := pgErr.ConstraintName
+	if field == "" {
+		field = pgErr.ColumnName
+	}
+
+	return errors.FieldError{
+		Field:   field,
+		Message: message,
+	}
+}
+
+// IsRecordNotFoundError returns true if the given error was caused
+// by a query which did not match any record
+func IsRecordNotFoundError(err errors.IError) bool {
+	if err == nil {
+		return false
+	}
+
+	return goerr.Is(err.Cause(), gorm.ErrRecordNotFound)
+}
+
+// IsNoRowAffectedError returns true if the given error was caused
+// by an update which did not match any record
+func IsNoRowAffectedError(err errors.IError) bool {
+	if err == nil {
+		return false
+	}
+
+	return err.IsOfType(errors.BAD_REQUEST_ERROR) && err.Error() == errNoRowAffected
+}
+
+// getSQLiteError maps the SQLite result codes to the domain errors
+func getSQLiteError(sqliteErr *sqlite.Error) errors.IError {
+	// the columns are only given in the messages, e.g. in constraint
+	// failed: UNIQUE constraint failed: feature_flags.name (2067)
+	name := ""
+	if i := strings.LastIndex(sqliteErr.Error(), "failed: "); i >= 0 {
+		name, _, _ = strings.Cut(sqliteErr.Error()[i+len("failed: "):], " (")
+	}
+
+	switch sqliteErr.Code() {
+	case sqliteCodeUniqueViolation, sqliteCodePrimaryKeyViolation:
+		return errors.NewConflictError(errUniqueViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "must be unique"})
+
+	case sqliteCodeForeignKeyViolation:
+		return errors.NewUnprocessableError(errForeignKeyViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "must reference an existing record"})
+
+	case sqliteCodeCheckViolation:
+		return errors.NewUnprocessableError(errCheckViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "is not a valid value"})
+
+	case sqliteCodeNotNullViolation:
+		return errors.NewUnprocessableError(errNotNullViolation).
+			WithDetails(errors.FieldError{Field: name, Message: "cannot be blank"})
+
+	// the database is locked by another connection, e.g. of the migrations
+	case sqliteCodeBusy, sqliteCodeBusySnapshot:
+		return errors.NewConflictError(errLockNotAvailable).AsRetryable()
+
+	default:
+		return errors.NewServerError(errDBError)
+	}
+}
+
+// IsLockNotAvailableError returns true if the given error was caused
+// by a lock taken without waiting which is held by another transaction
+func IsLockNotAvailableError(err errors.IError) bool {
+	if err == nil {
+		return false
+	}
+
+	var (
+		pgErr    *pgconn.PgError
+		mysqlErr *mysql.MySQLError
+	)
+	switch cause := err.Cause(); {
+	case goerr.As(cause, &pgErr):
+		return pgErr.Code == pgCodeLockNotAvailable
+	case goerr.As(cause, &mysqlErr):
+		return mysqlErr.Number == mysqlNumberLockNotAvailable
+	default:
+		return false
+	}
 }
 
 // GetValidationError wraps the error and returns instance of ValidationError
 // if the provided error is nil then it just returns nil
 func GetValidationError(err error) errors.IError {
 	if err != nil {
-		return errors.NewBadRequestError(errValidationFailure).
-			Wrap(err)
+		return errors.NewValidationError(errValidationFailure, err)
 	}
 
 	return nil
@@ -0,0 +1,272 @@
+package sql
+
+import (
+	"context"
+	goerr "errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+)
+
+func TestToDBErrorPostgres(t *testing.T) {
+	tests := []struct {
+		name      string
+		pgErr     *pgconn.PgError
+		errType   errors.ErrorType
+		message   string
+		field     string
+		retryable bool
+	}{
+		{
+			name:    "unique violation",
+			pgErr:   &pgconn.PgError{Code: pgCodeUniqueViolation, ConstraintName: "feature_flags_name_key"},
+			errType: errors.CONFLICT_ERROR, message: errUniqueViolation, field: "feature_flags_name_key",
+		},
+		{
+			name:    "foreign key violation",
+			pgErr:   &pgconn.PgError{Code: pgCodeForeignKeyViolation, ConstraintName: "fk_webhook"},
+			errType: errors.UNPROCESSABLE_ERROR, message: errForeignKeyViolation, field: "fk_webhook",
+		},
+		{
+			name:    "check violation without constraint",
+			pgErr:   &pgconn.PgError{Code: pgCodeCheckViolation, ColumnName: "status"},
+			errType: errors.UNPROCESSABLE_ERROR, message: errCheckViolation, field: "status",
+		},
+		{
+			name:    "not null violation",
+			pgErr:   &pgconn.PgError{Code: pgCodeNotNullViolation, ColumnName: "user_id"},
+			errType: errors.UNPROCESSABLE_ERROR, message: errNotNullViolation, field: "user_id",
+		},
+		{
+			name:    "serialization failure",
+			pgErr:   &pgconn.PgError{Code: pgCodeSerializationFailure},
+			errType: errors.CONFLICT_ERROR, message: errSerializationFailure, retryable: true,
+		},
+		{
+			name:    "deadlock",
+			pgErr:   &pgconn.PgError{Code: pgCodeDeadlockDetected},
+			errType: errors.CONFLICT_ERROR, message: errDeadlockDetected, retryable: true,
+		},
+		{
+			name:    "statement timeout",
+			pgErr:   &pgconn.PgError{Code: pgCodeQueryCanceled},
+			errType: errors.UNAVAILABLE_ERROR, message: errStatementTimeout,
+		},
+		{
+			name:    "other",
+			pgErr:   &pgconn.PgError{Code: "22P02"},
+			errType: errors.INTERNAL_SERVER_ERROR, message: errDBError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the drivers wrap the errors of the server
+			err := ToDBError(fmt.Errorf("exec: %w", tt.pgErr))
+
+			if !err.IsOfType(tt.errType) || err.Error() != tt.message || err.IsRetryable() != tt.retryable {
+				t.Fatalf("unexpected error: %v %s retryable=%v", err, err.GetType(), err.IsRetryable())
+			}
+			if tt.field != "" {
+				if details := err.Details(); len(details) != 1 || details[0].Field != tt.field {
+					t.Errorf("expected the field %s in the details, got %+v", tt.field, details)
+				}
+			}
+			if !goerr.Is(err, tt.pgErr) {
+				t.Error("expected the error of the database to be wrapped")
+			}
+		})
+	}
+}
+
+func TestToDBErrorMySQL(t *testing.T) {
+	tests := []struct {
+		name      string
+		mysqlErr  *mysql.MySQLError
+		errType   errors.ErrorType
+		message   string
+		field     string
+		retryable bool
+	}{
+		{
+			name:     "unique violation",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberUniqueViolation, Message: "Duplicate entry 'x' for key 'feature_flags.name'"},
+			errType:  errors.CONFLICT_ERROR, message: errUniqueViolation, field: "feature_flags.name",
+		},
+		{
+			name:     "foreign key violation",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberForeignKeyViolation, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`webhook_deliveries`, CONSTRAINT `fk_webhook` FOREIGN KEY (`webhook_id`) REFERENCES `webhooks` (`id`))"},
+			errType:  errors.UNPROCESSABLE_ERROR, message: errForeignKeyViolation, field: "fk_webhook",
+		},
+		{
+			name:     "check violation",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberCheckViolation, Message: "Check constraint 'chk_status' is violated."},
+			errType:  errors.UNPROCESSABLE_ERROR, message: errCheckViolation,
+		},
+		{
+			name:     "not null violation",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberNotNullViolation, Message: "Column 'user_id' cannot be null"},
+			errType:  errors.UNPROCESSABLE_ERROR, message: errNotNullViolation, field: "user_id",
+		},
+		{
+			name:     "deadlock",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberDeadlockDetected},
+			errType:  errors.CONFLICT_ERROR, message: errDeadlockDetected, retryable: true,
+		},
+		{
+			name:     "lock wait timeout",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberLockWaitTimeout},
+			errType:  errors.CONFLICT_ERROR, message: errDeadlockDetected, retryable: true,
+		},
+		{
+			name:     "statement timeout",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberQueryInterrupted},
+			errType:  errors.UNAVAILABLE_ERROR, message: errStatementTimeout,
+		},
+		{
+			name:     "lock not available",
+			mysqlErr: &mysql.MySQLError{Number: mysqlNumberLockNotAvailable},
+			errType:  errors.CONFLICT_ERROR, message: errLockNotAvailable,
+		},
+		{
+			name:     "other",
+			mysqlErr: &mysql.MySQLError{Number: 1064},
+			errType:  errors.INTERNAL_SERVER_ERROR, message: errDBError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ToDBError(fmt.Errorf("exec: %w", tt.mysqlErr))
+
+			if !err.IsOfType(tt.errType) || err.Error() != tt.message || err.IsRetryable() != tt.retryable {
+				t.Fatalf("unexpected error: %v %s retryable=%v", err, err.GetType(), err.IsRetryable())
+			}
+			if tt.field != "" {
+				if details := err.Details(); len(details) != 1 || details[0].Field != tt.field {
+					t.Errorf("expected the field %s in the details, got %+v", tt.field, details)
+				}
+			}
+			if !goerr.Is(err, tt.mysqlErr) {
+				t.Error("expected the error of the database to be wrapped")
+			}
+		})
+	}
+}
+
+func TestToDBErrorSQLite(t *testing.T) {
+	db, err := NewDb(&DbConnectionConfig{Dialect: DialectSQLite, Name: SQLiteMemory})
+	if err != nil {
+		t.Fatalf("open the database: %v", err)
+	}
+	instance := db.GetInstance(context.Background())
+	conn, _ := instance.DB()
+	defer conn.Close()
+
+	schema := []string{
+		`CREATE TABLE parents (id TEXT PRIMARY KEY)`,
+		`CREATE TABLE children (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			age INTEGER CHECK (age >= 0),
+			parent_id TEXT REFERENCES parents (id)
+		)`,
+		`INSERT INTO children (id, name) VALUES ('1', 'first')`,
+	}
+	for _, statement := range schema {
+		if err := instance.Exec(statement).Error; err != nil {
+			t.Fatalf("create the schema: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		statement string
+		errType   errors.ErrorType
+		message   string
+		field     string
+	}{
+		{
+			name:      "unique violation",
+			statement: `INSERT INTO children (id, name) VALUES ('2', 'first')`,
+			errType:   errors.CONFLICT_ERROR, message: errUniqueViolation, field: "children.name",
+		},
+		{
+			name:      "primary key violation",
+			statement: `INSERT INTO children (id, name) VALUES ('1', 'second')`,
+			errType:   errors.CONFLICT_ERROR, message: errUniqueViolation, field: "children.id",
+		},
+		{
+			name:      "foreign key violation",
+			statement: `INSERT INTO children (id, name, parent_id) VALUES ('3', 'third', 'unknown')`,
+			errType:   errors.UNPROCESSABLE_ERROR, message: errForeignKeyViolation,
+		},
+		{
+			name:      "check violation",
+			statement: `INSERT INTO children (id, name, age) VALUES ('4', 'fourth', -1)`,
+			errType:   errors.UNPROCESSABLE_ERROR, message: errCheckViolation,
+		},
+		{
+			name:      "not null violation",
+			statement: `INSERT INTO children (id) VALUES ('5')`,
+			errType:   errors.UNPROCESSABLE_ERROR, message: errNotNullViolation, field: "children.name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GetDBError(instance.Exec(tt.statement))
+
+			if err == nil || !err.IsOfType(tt.errType) || err.Error() != tt.message {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.field != "" {
+				if details := err.Details(); len(details) != 1 || details[0].Field != tt.field {
+					t.Errorf("expected the field %s in the details, got %+v", tt.field, details)
+				}
+			}
+		})
+	}
+}
+
+func TestToDBError(t *testing.T) {
+	if err := ToDBError(nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	err := ToDBError(gorm.ErrRecordNotFound)
+	if !err.IsOfType(errors.NOT_FOUND_ERROR) || !IsRecordNotFoundError(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	if err = ToDBError(context.DeadlineExceeded); !err.IsOfType(errors.UNAVAILABLE_ERROR) {
+		t.Errorf("expected an unavailable error, got %v", err)
+	}
+
+	if err = ToDBError(goerr.New("connection reset")); !err.IsOfType(errors.INTERNAL_SERVER_ERROR) || IsRecordNotFoundError(err) {
+		t.Errorf("expected a server error, got %v", err)
+	}
+}
+
+func TestIsNoRowAffectedError(t *testing.T) {
+	tests := []struct {
+		err  errors.IError
+		want bool
+	}{
+		{err: errors.NewBadRequestError(errNoRowAffected), want: true},
+		{err: errors.NewBadRequestError("invalid_input")},
+		{err: errors.NewNotFoundError(errNoRowAffected)},
+		{err: nil},
+	}
+
+	for _, tt := range tests {
+		if got := IsNoRowAffectedError(tt.err); got != tt.want {
+			t.Errorf("expected %v for %v, got %v", tt.want, tt.err, got)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+const (
+	// listenerRetryDelay is the delay before re-establishing a lost connection
+	listenerRetryDelay = 2 * time.Second
+)
+
+var ErrorListenUnsupported = errors.New("listen is supported only by the postgres dialect")
+
+// Listener receives the notifications sent on a postgres channel
+// over a dedicated connection, outside of the connection pool
+type Listener struct {
+	dsn     string
+	channel string
+}
+
+// NewListener creates a new listener for the channel
+func NewListener(dbConfig IDbConnectionConfig, channel string) (*Listener, error) {
+	if dbConfig.GetDialect() != DialectPostgres {
+		return nil, ErrorListenUnsupported
+	}
+
+	return &Listener{
+		dsn:     dbConfig.GetConnectionPath(),
+		channel: channel,
+	}, nil
+}
+
+// Listen calls onNotify with the payload of every notification until the
+// context is done. The connection is re-established when it is lost and
+// onConnect is called every time the channel is listened to, so that the
+// notifications missed in the meantime can be caught up.
+func (l *Listener) Listen(
+	ctx context.Context,
+	onConnect func(ctx context.Context),
+	onNotify func(ctx context.Context, payload string),
+) {
+	logger := pkgLogger.Ctx(ctx)
+
+	for {
+		err := l.listen(ctx, onConnect, onNotify)
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.WithError(err).WithField("channel", l.channel).Warn("DB_LISTENER_DISCONNECTED")
+
+		if wait(ctx, listenerRetryDelay) != nil {
+			return
+		}
+	}
+}
+
+func (l *Listener) listen(
+	ctx context.Context,
+	onConnect func(ctx context.Context),
+	onNotify func(ctx context.Context, payload string),
+) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	if onConnect != nil {
+		onConnect(ctx)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		onNotify(ctx, notification.Payload)
+	}
+}
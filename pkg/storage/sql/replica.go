@@ -0,0 +1,178 @@
+package sql
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
+)
+
+const (
+	// DefaultReplicaHealthCheckInterval is the default time between the health checks of the replicas
+	DefaultReplicaHealthCheckInterval = 10 * time.Second
+	// replicaPingTimeout bounds the health check of a replica
+	replicaPingTimeout = 2 * time.Second
+)
+
+// WithPrimary routes the reads made with the context to the primary, e.g. to
+// read the writes just made, which the replicas may not have received yet
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ContextKeyPrimary, true)
+}
+
+// IsPrimaryRead checks if the reads made with the context go to the primary
+func IsPrimaryRead(ctx context.Context) bool {
+	primary, _ := ctx.Value(ContextKeyPrimary).(bool)
+	return primary
+}
+
+// replicaPolicy picks a random replica among the healthy ones,
+// the primary serves the reads while none of them is healthy
+type replicaPolicy struct {
+	primary  gorm.ConnPool
+	replicas int
+
+	mu        sync.RWMutex
+	unhealthy map[gorm.ConnPool]bool
+}
+
+func newReplicaPolicy(primary gorm.ConnPool, replicas int) *replicaPolicy {
+	return &replicaPolicy{
+		primary:   primary,
+		replicas:  replicas,
+		unhealthy: map[gorm.ConnPool]bool{},
+	}
+}
+
+// Resolve implements dbresolver.Policy
+func (p *replicaPolicy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	healthy := make([]gorm.ConnPool, 0, len(pools))
+	for _, pool := range pools {
+		if !p.unhealthy[pool] {
+			healthy = append(healthy, pool)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return p.primary
+	}
+
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// hasHealthy checks if any replica is healthy, the resolver skips
+// the policy when there is a single replica
+func (p *replicaPolicy) hasHealthy() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return len(p.unhealthy) < p.replicas
+}
+
+// setHealthy records the health of the replica, it returns true when it changed
+func (p *replicaPolicy) setHealthy(pool gorm.ConnPool, healthy bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.unhealthy[pool] == !healthy {
+		return false
+	}
+
+	if healthy {
+		delete(p.unhealthy, pool)
+	} else {
+		p.unhealthy[pool] = true
+	}
+
+	return true
+}
+
+// registerReplicas registers the resolver routing the reads to the replicas,
+// the writes and the transactions always go to the primary
+func (db *DB) registerReplicas() error {
+	if len(db.replicaDialectors) == 0 {
+		for _, path := range db.dbConfig.GetReplicaConnectionPaths() {
+			d, err := openDialector(db.dbConfig.GetDialect(), path)
+			if err != nil {
+				return err
+			}
+			db.replicaDialectors = append(db.replicaDialectors, d)
+		}
+	}
+
+	if len(db.replicaDialectors) == 0 {
+		return nil
+	}
+
+	primary := db.instance.Config.ConnPool
+	if preparedStmt, ok := primary.(*gorm.PreparedStmtDB); ok {
+		primary = preparedStmt.ConnPool
+	}
+	db.replicaPolicy = newReplicaPolicy(primary, len(db.replicaDialectors))
+
+	// the replicas share the pool settings of the primary
+	db.resolver = dbresolver.Register(dbresolver.Config{
+		Replicas: db.replicaDialectors,
+		Policy:   db.replicaPolicy,
+	}).
+		SetMaxIdleConns(db.dbConfig.GetMaxIdleConnections()).
+		SetMaxOpenConns(db.dbConfig.GetMaxOpenConnections()).
+		SetConnMaxLifetime(db.dbConfig.GetConnMaxLifetime() * time.Second).
+		SetConnMaxIdleTime(db.dbConfig.GetConnMaxIdleTime() * time.Second)
+
+	return db.instance.Use(db.resolver)
+}
+
+// MonitorReplicas checks the health of the replicas until the context is done,
+// the reads are not routed to the replicas failing their check
+func (db *DB) MonitorReplicas(ctx context.Context) {
+	if db.resolver == nil {
+		return
+	}
+
+	ticker := time.NewTicker(db.dbConfig.GetReplicaHealthCheckInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			db.checkReplicas(ctx)
+		}
+	}
+}
+
+// checkReplicas pings the replicas and records their health
+func (db *DB) checkReplicas(ctx context.Context) {
+	_ = db.resolver.Call(func(pool gorm.ConnPool) error {
+		pinger, ok := pool.(interface{ PingContext(context.Context) error })
+		if pool == db.replicaPolicy.primary || !ok {
+			return nil
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := pinger.PingContext(pingCtx)
+		cancel()
+
+		if !db.replicaPolicy.setHealthy(pool, err == nil) {
+			return nil
+		}
+
+		if err != nil {
+			pkgLogger.Ctx(ctx).WithError(err).Error("DB_REPLICA_UNHEALTHY")
+		} else {
+			pkgLogger.Ctx(ctx).Info("DB_REPLICA_HEALTHY")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,173 @@
+package sql
+
+import (
+	"context"
+	goerr "errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakePool is a connection pool told apart by its pointer
+type fakePool struct {
+	gorm.ConnPool
+	name string
+}
+
+func TestReplicaPolicy(t *testing.T) {
+	primary := &fakePool{name: "primary"}
+	replica1 := &fakePool{name: "replica_1"}
+	replica2 := &fakePool{name: "replica_2"}
+	pools := []gorm.ConnPool{replica1, replica2}
+
+	policy := newReplicaPolicy(primary, len(pools))
+	if !policy.hasHealthy() {
+		t.Fatal("expected the replicas to be healthy until checked")
+	}
+
+	if !policy.setHealthy(replica1, false) {
+		t.Error("expected the health of the replica to change")
+	}
+	if policy.setHealthy(replica1, false) {
+		t.Error("expected the health of the replica to be unchanged")
+	}
+	for i := 0; i < 20; i++ {
+		if got := policy.Resolve(pools); got != replica2 {
+			t.Fatalf("expected the healthy replica, got %v", got.(*fakePool).name)
+		}
+	}
+
+	policy.setHealthy(replica2, false)
+	if policy.hasHealthy() {
+		t.Error("expected no healthy replica")
+	}
+	if got := policy.Resolve(pools); got != primary {
+		t.Errorf("expected the primary while no replica is healthy, got %v", got.(*fakePool).name)
+	}
+
+	if !policy.setHealthy(replica1, true) || !policy.hasHealthy() {
+		t.Error("expected the replica to be healthy again")
+	}
+	if got := policy.Resolve(pools); got != replica1 {
+		t.Errorf("expected the recovered replica, got %v", got.(*fakePool).name)
+	}
+}
+
+func TestGetReplicaConnectionPaths(t *testing.T) {
+	config := DbConnectionConfig{
+		Dialect:  DialectPostgres,
+		URL:      "primary",
+		Port:     5432,
+		Username: "app",
+		Password: "secret",
+		Name:     "images",
+		SslMode:  "disable",
+		Replicas: []ReplicaConfig{
+			{URL: "replica_1"},
+			{URL: "replica_2", Port: 6432, Username: "reader", Password: "other"},
+		},
+	}
+
+	replica1 := config
+	replica1.URL = "replica_1"
+	replica2 := config
+	replica2.URL, replica2.Port, replica2.Username, replica2.Password = "replica_2", 6432, "reader", "other"
+
+	paths := config.GetReplicaConnectionPaths()
+	if len(paths) != 2 || paths[0] != replica1.GetConnectionPath() || paths[1] != replica2.GetConnectionPath() {
+		t.Errorf("expected the replicas to inherit the unset settings of the primary, got %v", paths)
+	}
+
+	if got := config.GetReplicaHealthCheckInterval(); got != DefaultReplicaHealthCheckInterval {
+		t.Errorf("expected the default health check interval, got %v", got)
+	}
+	config.ReplicaHealthCheckInterval = 3
+	if got := config.GetReplicaHealthCheckInterval(); got != 3*time.Second {
+		t.Errorf("expected the configured health check interval, got %v", got)
+	}
+}
+
+// newMockReplicatedDb returns the storage connected to mocks of a
+// postgres primary and of its replica
+func newMockReplicatedDb(t *testing.T) (*DB, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	t.Helper()
+
+	primaryConn, primary, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create the primary mock: %v", err)
+	}
+	replicaConn, replica, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("create the replica mock: %v", err)
+	}
+	t.Cleanup(func() {
+		for name, mock := range map[string]sqlmock.Sqlmock{"primary": primary, "replica": replica} {
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations of the %v: %v", name, err)
+			}
+		}
+		_ = primaryConn.Close()
+		_ = replicaConn.Close()
+	})
+
+	// the replica is pinged when it is opened
+	replica.ExpectPing()
+	db, err := NewDb(
+		// the mocks serve a single connection, which is kept idle between the statements
+		&DbConnectionConfig{Dialect: DialectPostgres, MaxOpenConnections: 1, MaxIdleConnections: 1},
+		Dialector(postgres.New(postgres.Config{Conn: primaryConn})),
+		ReplicaDialectors(postgres.New(postgres.Config{Conn: replicaConn})),
+		GormConfig(&gorm.Config{
+			SkipDefaultTransaction: true,
+			Logger:                 logger.Default.LogMode(logger.Silent),
+		}),
+	)
+	if err != nil {
+		t.Fatalf("open the database mocks: %v", err)
+	}
+
+	return db, primary, replica
+}
+
+// count runs a read with the context
+func count(t *testing.T, db *DB, ctx context.Context) {
+	t.Helper()
+
+	var n int
+	if err := db.Instance(ctx).Raw("SELECT count(*) FROM images").Scan(&n).Error; err != nil {
+		t.Fatalf("read: %v", err)
+	}
+}
+
+func TestReadsRoutedToReplicas(t *testing.T) {
+	db, primary, replica := newMockReplicatedDb(t)
+	ctx := context.Background()
+	rows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"count"}).AddRow(1) }
+
+	// the reads go to the replica, the writes and the primary reads to the primary
+	replica.ExpectQuery("SELECT count").WillReturnRows(rows())
+	count(t, db, ctx)
+
+	primary.ExpectExec("DELETE FROM images").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := db.Instance(ctx).Exec("DELETE FROM images").Error; err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	primary.ExpectQuery("SELECT count").WillReturnRows(rows())
+	count(t, db, WithPrimary(ctx))
+
+	// the reads fall back to the primary while the replica fails its check
+	replica.ExpectPing().WillReturnError(goerr.New("connection refused"))
+	db.checkReplicas(ctx)
+	primary.ExpectQuery("SELECT count").WillReturnRows(rows())
+	count(t, db, ctx)
+
+	replica.ExpectPing()
+	db.checkReplicas(ctx)
+	replica.ExpectQuery("SELECT count").WillReturnRows(rows())
+	count(t, db, ctx)
+}
@@ -2,10 +2,12 @@ package sql
 
 import (
 	"context"
+	"database/sql"
 
 	"gorm.io/gorm"
 
 	"github.com/danushk97/image-analyzer/pkg/errors"
+	pkgLogger "github.com/danushk97/image-analyzer/pkg/logger"
 )
 
 const updatedAtField = "updated_at"
@@ -39,6 +41,30 @@ func (repo Repo) Create(ctx context.Context, receiver IModel) errors.IError {
 	return GetDBError(q)
 }
 
+// Update updates the given attributes of the receiver identified by its primary key
+// if no attributes are given then all the non zero fields are updated
+func (repo Repo) Update(ctx context.Context, receiver IModel, attributes ...string) errors.IError {
+	if err := receiver.Validate(); err != nil {
+		return err
+	}
+
+	q := repo.DBInstance(ctx).WithContext(ctx).Model(receiver)
+	if len(attributes) > 0 {
+		q = q.Select(append(attributes, updatedAtField))
+	}
+
+	q = q.Updates(receiver)
+	if err := GetDBError(q); err != nil {
+		return err
+	}
+
+	if q.RowsAffected == 0 {
+		return errors.NewBadRequestError(errNoRowAffected)
+	}
+
+	return nil
+}
+
 // Delete deletes the given model
 // Soft or hard delete of model depends on the models implementation
 // if the model composites SoftDeletableModel then it'll be soft deleted
@@ -49,10 +75,45 @@ func (repo Repo) Delete(ctx context.Context, receiver IModel) errors.IError {
 }
 
 // Transaction will manage the execution inside a transactions
-// adds the txn db in the context for downstream use case
-func (repo Repo) Transaction(ctx context.Context, fc func(ctx context.Context) errors.IError) errors.IError {
-	var err = repo.DBInstance(ctx).Transaction(func(tx *gorm.DB) error {
+// adds the txn db in the context for downstream use case.
+// Retryable errors such as serialization failures and deadlocks re-run the
+// whole transaction with a jittered backoff, bounded by the retry options.
+// If a transaction is already active then a savepoint is used instead, such
+// nested transactions are never retried as the outer transaction is aborted.
+func (repo Repo) Transaction(
+	ctx context.Context,
+	fc func(ctx context.Context) errors.IError,
+	opts ...TxOption,
+) errors.IError {
+	config := newTxConfig(opts...)
+
+	if repo.IsTransactionActive(ctx) {
+		return repo.transaction(ctx, fc, nil)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := repo.transaction(ctx, fc, config.sqlTxOptions())
+		if err == nil || !err.IsRetryable() || attempt >= config.maxRetries {
+			return err
+		}
 
+		pkgLogger.Ctx(ctx).WithError(err).
+			WithField("attempt", attempt+1).
+			Warn("DB_TRANSACTION_RETRY")
+
+		if waitErr := wait(ctx, config.backoff(attempt)); waitErr != nil {
+			return err
+		}
+	}
+}
+
+// transaction runs the function once inside a transaction or a savepoint
+func (repo Repo) transaction(
+	ctx context.Context,
+	fc func(ctx context.Context) errors.IError,
+	txOptions *sql.TxOptions,
+) errors.IError {
+	txFn := func(tx *gorm.DB) error {
 		// This will ensure that when db.Instance(context) we return the txn on the context
 		// & all repo queries are done on this txn. Refer usage in test.
 		if err := fc(context.WithValue(ctx, ContextKeyDatabase, tx)); err != nil {
@@ -60,7 +121,15 @@ func (repo Repo) Transaction(ctx context.Context, fc func(ctx context.Context) e
 		}
 
 		return GetDBError(tx)
-	})
+	}
+
+	var err error
+	if txOptions == nil {
+		// gorm creates a savepoint when the instance is already a transaction
+		err = repo.DBInstance(ctx).Transaction(txFn)
+	} else {
+		err = repo.DBInstance(ctx).Transaction(txFn, txOptions)
+	}
 
 	if err == nil {
 		return nil
@@ -71,8 +140,8 @@ func (repo Repo) Transaction(ctx context.Context, fc func(ctx context.Context) e
 		return iErr
 	}
 
-	// use the default code and wrap err in internal
-	return errors.NewServerError(errDBError).Wrap(err)
+	// classify the error returned by the database, e.g. on commit
+	return ToDBError(err)
 }
 
 // IsTransactionActive returns true if a transaction is active
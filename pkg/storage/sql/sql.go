@@ -7,25 +7,38 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/glebarez/sqlite"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
 const (
 	DBConnectionString           = "host=%s port=%d dbname=%s sslmode=%s user=%s password=%s"
 	DBConnectionStringWithSchema = "host=%s port=%d dbname=%s sslmode=%s user=%s password=%s search_path=%s"
+	DBConnectionStringSQLite     = "file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
 )
 
 const (
 	DialectPostgres string = "postgres"
+	DialectMySQL    string = "mysql"
+	DialectSQLite   string = "sqlite"
 )
 
+// SQLiteMemory is the name of the SQLite database held in memory
+const SQLiteMemory = ":memory:"
+
 type contextKey int
 
 const (
 	// used to set the db instance in context in case of transactions
 	ContextKeyDatabase contextKey = iota
+	// used to route the reads of the context to the primary
+	ContextKeyPrimary
 )
 
 var ErrorUndefinedDialect = errors.New("dialect for the db is not defined")
@@ -39,6 +52,8 @@ type IDbConnectionConfig interface {
 	GetMaxOpenConnections() int
 	GetConnMaxLifetime() time.Duration
 	GetConnMaxIdleTime() time.Duration
+	GetReplicaConnectionPaths() []string
+	GetReplicaHealthCheckInterval() time.Duration
 	IsDebugMode() bool
 }
 
@@ -51,13 +66,55 @@ type DbConnectionConfig struct {
 	Username              string
 	Password              string
 	SslMode               string
-	Name                  string
+	Name                  string // the file of the database in SQLite, or :memory:
 	Schema                string //can be used in Postgres (optional)
 	MaxOpenConnections    int
 	MaxIdleConnections    int
 	ConnectionLifetime    time.Duration
 	ConnectionMaxIdleTime time.Duration
 	Debug                 bool
+	// Replicas serve the reads, the primary serves them all when empty
+	Replicas []ReplicaConfig
+	// ReplicaHealthCheckInterval is the time in seconds between the health checks of the replicas
+	ReplicaHealthCheckInterval int
+}
+
+// ReplicaConfig holds the connection settings of a read replica,
+// the settings left empty are the ones of the primary
+type ReplicaConfig struct {
+	URL      string
+	Port     int
+	Username string
+	Password string
+}
+
+// Validate checks the settings of the replica
+func (c ReplicaConfig) Validate() error {
+	return validation.ValidateStruct(
+		&c,
+		validation.Field(&c.URL, validation.Required),
+		validation.Field(&c.Port, validation.Min(0), validation.Max(65535)),
+	)
+}
+
+// Validate checks the settings required to connect to the database,
+// SQLite only requires the name of the database
+func (c DbConnectionConfig) Validate() error {
+	server := c.Dialect != DialectSQLite
+
+	return validation.ValidateStruct(
+		&c,
+		validation.Field(&c.Dialect, validation.Required, validation.In(DialectPostgres, DialectMySQL, DialectSQLite)),
+		validation.Field(&c.URL, validation.When(server, validation.Required)),
+		validation.Field(&c.Port, validation.When(server, validation.Required), validation.Min(0), validation.Max(65535)),
+		validation.Field(&c.Username, validation.When(server, validation.Required)),
+		validation.Field(&c.Password, validation.When(server, validation.Required)),
+		validation.Field(&c.Name, validation.Required),
+		validation.Field(&c.MaxOpenConnections, validation.Min(0)),
+		validation.Field(&c.MaxIdleConnections, validation.Min(0)),
+		validation.Field(&c.Replicas),
+		validation.Field(&c.ReplicaHealthCheckInterval, validation.Min(0)),
+	)
 }
 
 // GetDialect returns a dialect identifier
@@ -78,11 +135,62 @@ func (c DbConnectionConfig) GetConnectionPath() string {
 			return fmt.Sprintf(DBConnectionString, c.URL, c.Port, c.Name, c.SslMode, c.Username, c.Password)
 		}
 		return fmt.Sprintf(DBConnectionStringWithSchema, c.URL, c.Port, c.Name, c.SslMode, c.Username, c.Password, c.Schema)
+	case DialectMySQL:
+		protocol := c.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		// the config escapes the credentials holding the separators of the DSN, e.g. @ or /
+		dsn := mysqlDriver.NewConfig()
+		dsn.User = c.Username
+		dsn.Passwd = c.Password
+		dsn.Net = protocol
+		dsn.Addr = fmt.Sprintf("%s:%d", c.URL, c.Port)
+		if protocol == "unix" {
+			dsn.Addr = c.URL
+		}
+		dsn.DBName = c.Name
+		dsn.Params = map[string]string{"charset": "utf8mb4"}
+		dsn.ParseTime = true
+		dsn.Loc = time.UTC
+		return dsn.FormatDSN()
+	case DialectSQLite:
+		return fmt.Sprintf(DBConnectionStringSQLite, c.Name)
 	default:
 		return ""
 	}
 }
 
+// GetReplicaConnectionPaths returns the connection strings of the replicas
+func (c DbConnectionConfig) GetReplicaConnectionPaths() []string {
+	paths := make([]string, 0, len(c.Replicas))
+	for _, replica := range c.Replicas {
+		replicaConfig := c
+		replicaConfig.URL = replica.URL
+		if replica.Port != 0 {
+			replicaConfig.Port = replica.Port
+		}
+		if replica.Username != "" {
+			replicaConfig.Username = replica.Username
+		}
+		if replica.Password != "" {
+			replicaConfig.Password = replica.Password
+		}
+		paths = append(paths, replicaConfig.GetConnectionPath())
+	}
+
+	return paths
+}
+
+// GetReplicaHealthCheckInterval returns the time between the health checks of the replicas
+func (c DbConnectionConfig) GetReplicaHealthCheckInterval() time.Duration {
+	if c.ReplicaHealthCheckInterval <= 0 {
+		return DefaultReplicaHealthCheckInterval
+	}
+
+	return time.Duration(c.ReplicaHealthCheckInterval) * time.Second
+}
+
 // GetMaxOpenConnections returns max open connections for the db.
 func (c DbConnectionConfig) GetMaxOpenConnections() int {
 	return c.MaxOpenConnections
@@ -114,6 +222,11 @@ type DB struct {
 	dialector  gorm.Dialector
 	gormConfig *gorm.Config
 	instance   *gorm.DB
+
+	// the reads are routed to the replicas when any
+	replicaDialectors []gorm.Dialector
+	resolver          *dbresolver.DBResolver
+	replicaPolicy     *replicaPolicy
 }
 
 func GormConfig(c *gorm.Config) func(*DB) error {
@@ -130,13 +243,26 @@ func Dialector(gd gorm.Dialector) func(*DB) error {
 	}
 }
 
-func NewDb(dbConfig IDbConnectionConfig) (*DB, error) {
+func ReplicaDialectors(gds ...gorm.Dialector) func(*DB) error {
+	return func(db *DB) error {
+		db.replicaDialectors = gds
+		return nil
+	}
+}
+
+func NewDb(dbConfig IDbConnectionConfig, opts ...func(*DB) error) (*DB, error) {
 	if dbConfig == nil {
 		dbConfig = &DbConnectionConfig{}
 	}
 
 	db := &DB{dbConfig: dbConfig}
 
+	for _, opt := range opts {
+		if err := opt(db); err != nil {
+			return nil, err
+		}
+	}
+
 	if db.dialector == nil {
 		if err := db.initDialector(); err != nil {
 			return nil, err
@@ -163,10 +289,15 @@ func NewDb(dbConfig IDbConnectionConfig) (*DB, error) {
 // Instance returns underlying instance of gorm db.
 // If the transaction/session in progress then it'll return
 // the *gorm.DB from the context.
+// The reads go to the primary for the contexts created by WithPrimary
+// and while none of the replicas is healthy.
 func (db *DB) Instance(ctx context.Context) *gorm.DB {
 	if instance, ok := ctx.Value(ContextKeyDatabase).(*gorm.DB); ok {
 		return instance
 	}
+	if db.resolver != nil && (IsPrimaryRead(ctx) || !db.replicaPolicy.hasHealthy()) {
+		return db.instance.Clauses(dbresolver.Write)
+	}
 	return db.instance
 }
 
@@ -215,15 +346,41 @@ func (db *DB) connect() error {
 	dbConn.SetConnMaxLifetime(db.dbConfig.GetConnMaxLifetime() * time.Second)
 	dbConn.SetConnMaxIdleTime(db.dbConfig.GetConnMaxIdleTime() * time.Second)
 
-	return nil
+	// SQLite serializes the writes, a single connection avoids the busy
+	// errors and keeps the database held in memory alive
+	if db.dbConfig.GetDialect() == DialectSQLite {
+		dbConn.SetMaxOpenConns(1)
+		dbConn.SetMaxIdleConns(1)
+		dbConn.SetConnMaxLifetime(0)
+		dbConn.SetConnMaxIdleTime(0)
+	}
+
+	if err = db.registerReplicas(); err != nil {
+		return err
+	}
+
+	// the statements are traced as children of the spans in their context
+	return db.instance.Use(&tracingPlugin{
+		dialect:  db.dbConfig.GetDialect(),
+		database: db.dbConfig.GetDatabaseName(),
+	})
 }
 
 func getDialector(connReader IDbConnectionConfig) (gorm.Dialector, error) {
-	switch connReader.GetDialect() {
+	return openDialector(connReader.GetDialect(), connReader.GetConnectionPath())
+}
+
+// openDialector returns the dialector connecting to the database of the connection string
+func openDialector(dialect string, path string) (gorm.Dialector, error) {
+	switch dialect {
 	case DialectPostgres:
-		return postgres.Open(connReader.GetConnectionPath()), nil
+		return postgres.Open(path), nil
+	case DialectMySQL:
+		return mysql.Open(path), nil
+	case DialectSQLite:
+		return sqlite.Open(path), nil
 	default:
-		return nil, errors.New("dialect for the db is not defined")
+		return nil, ErrorUndefinedDialect
 	}
 }
 
@@ -231,6 +388,11 @@ func (db *DB) GetDatabaseName() string {
 	return db.dbConfig.GetDatabaseName()
 }
 
+// GetDialect returns the dialect of the database
+func (db *DB) GetDialect() string {
+	return db.dbConfig.GetDialect()
+}
+
 // getLogLevelByDebugMode return logger log level based on debug mode.
 // If app db is in debug mode, make log level as info
 // Default log level for gorm db is warning, overriding that by this method.
@@ -0,0 +1,117 @@
+// Package sqltest provides the sql storage backed by a mock of the
+// database, to check the statements written in each dialect, or by an
+// in memory SQLite database and the database servers of the
+// environment, to run the repo tests
+package sqltest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+// Dialects are the dialects of the database servers
+var Dialects = []string{sql.DialectPostgres, sql.DialectMySQL}
+
+// ServerDSNEnvs are the environment variables holding the connection strings
+// of the database servers the repo tests run against. The databases are
+// emptied by the tests, they must not hold any data to keep.
+var ServerDSNEnvs = map[string]string{
+	sql.DialectPostgres: "TEST_POSTGRES_DSN",
+	sql.DialectMySQL:    "TEST_MYSQL_DSN",
+}
+
+// NewRepo returns the storage of the dialect connected to a mock of the
+// database, the expectations are checked once the test is done
+func NewRepo(t *testing.T, dialect string) (*sql.Repo, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create the database mock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations of the database: %v", err)
+		}
+		_ = conn.Close()
+	})
+
+	var dialector gorm.Dialector
+	switch dialect {
+	case sql.DialectPostgres:
+		dialector = postgres.New(postgres.Config{Conn: conn})
+	case sql.DialectMySQL:
+		dialector = mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true})
+	default:
+		t.Fatalf("unsupported dialect of the database mock: %s", dialect)
+	}
+
+	return open(t, dialect, dialector), mock
+}
+
+// RepoDialects are the dialects the repo tests run against, SQLite
+// first as it is embedded and always available
+var RepoDialects = append([]string{sql.DialectSQLite}, Dialects...)
+
+// NewServerRepo returns the storage of the dialect connected to the database
+// server of its environment variable, the test is skipped when it is not set.
+// SQLite needs no server, its database is held in memory.
+func NewServerRepo(t *testing.T, dialect string) *sql.Repo {
+	t.Helper()
+
+	dsn := os.Getenv(ServerDSNEnvs[dialect])
+	if dsn == "" && dialect != sql.DialectSQLite {
+		t.Skipf("%s is not set, the tests are not run on %s", ServerDSNEnvs[dialect], dialect)
+	}
+
+	var dialector gorm.Dialector
+	switch dialect {
+	case sql.DialectSQLite:
+		dialector = sqlite.Open(sql.DbConnectionConfig{Dialect: dialect, Name: sql.SQLiteMemory}.GetConnectionPath())
+	case sql.DialectPostgres:
+		dialector = postgres.Open(dsn)
+	case sql.DialectMySQL:
+		dialector = mysql.Open(dsn)
+	default:
+		t.Fatalf("unsupported dialect of the database server: %s", dialect)
+	}
+
+	store := open(t, dialect, dialector)
+	t.Cleanup(func() {
+		if conn, err := store.Db.GetInstance(context.Background()).DB(); err == nil {
+			_ = conn.Close()
+		}
+	})
+
+	return store
+}
+
+// open returns the storage of the dialect connected with the dialector
+func open(t *testing.T, dialect string, dialector gorm.Dialector) *sql.Repo {
+	t.Helper()
+
+	db, err := sql.NewDb(
+		// the mock has a single connection, it must be kept idle between the statements
+		&sql.DbConnectionConfig{Dialect: dialect, Name: "test", MaxIdleConnections: 1},
+		sql.Dialector(dialector),
+		sql.GormConfig(&gorm.Config{
+			SkipDefaultTransaction: true,
+			Logger:                 logger.Default.LogMode(logger.Silent),
+		}),
+	)
+	if err != nil {
+		t.Fatalf("open the database: %v", err)
+	}
+
+	return &sql.Repo{Db: db}
+}
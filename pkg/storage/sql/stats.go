@@ -0,0 +1,17 @@
+package sql
+
+import (
+	"github.com/dlmiddlecote/sqlstats"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewStatsCollector returns a prometheus collector of the
+// connection pool stats of the database
+func (db *DB) NewStatsCollector() (prometheus.Collector, error) {
+	dbConn, err := db.instance.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlstats.NewStatsCollector(db.GetDatabaseName(), dbConn), nil
+}
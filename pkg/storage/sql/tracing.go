@@ -0,0 +1,97 @@
+package sql
+
+import (
+	goerr "errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/danushk97/image-analyzer/pkg/tracing"
+)
+
+// tracingSpanKey is the instance key the span of the statement is kept at
+const tracingSpanKey = "tracing:span"
+
+// tracingPlugin is a gorm plugin creating a span for every statement
+// executed, as a child of the span in the context of the statement
+type tracingPlugin struct {
+	dialect  string
+	database string
+}
+
+func (p *tracingPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the callbacks around the ones of each operation
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	return goerr.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", p.before("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	)
+}
+
+// before starts the span of the statement
+func (p *tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement == nil || tx.Statement.Context == nil {
+			return
+		}
+
+		ctx, span := tracing.Tracer().Start(
+			tx.Statement.Context,
+			"gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(p.dialect),
+				semconv.DBName(p.database),
+				semconv.DBOperation(operation),
+			),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+// after ends the span of the statement with its query and result
+func (p *tracingPlugin) after(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		semconv.DBStatement(tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Statement.Table != "" {
+		span.SetAttributes(semconv.DBSQLTable(tx.Statement.Table))
+	}
+
+	// a missing record is an expected result of the lookups
+	var err error
+	if !goerr.Is(tx.Error, gorm.ErrRecordNotFound) {
+		err = tx.Error
+	}
+
+	tracing.End(span, err)
+}
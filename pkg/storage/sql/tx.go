@@ -0,0 +1,131 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"time"
+
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+const (
+	// DefaultTxMaxRetries is the default number of times a transaction
+	// is retried after a serialization failure or a deadlock
+	DefaultTxMaxRetries = 3
+	// DefaultTxRetryBaseDelay is the default delay before the first retry
+	DefaultTxRetryBaseDelay = 10 * time.Millisecond
+	// DefaultTxRetryMaxDelay is the default upper bound of the delay between retries
+	DefaultTxRetryMaxDelay = 500 * time.Millisecond
+)
+
+// TxOption configures the execution of a transaction
+type TxOption func(*txConfig)
+
+// txConfig holds the configurations of a transaction
+type txConfig struct {
+	isolation      sql.IsolationLevel
+	readOnly       bool
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+}
+
+func newTxConfig(opts ...TxOption) *txConfig {
+	c := &txConfig{
+		isolation:      sql.LevelDefault,
+		maxRetries:     DefaultTxMaxRetries,
+		retryBaseDelay: DefaultTxRetryBaseDelay,
+		retryMaxDelay:  DefaultTxRetryMaxDelay,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithIsolation sets the isolation level of the transaction
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) {
+		c.isolation = level
+	}
+}
+
+// WithReadOnly marks the transaction as read only
+func WithReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.readOnly = true
+	}
+}
+
+// WithMaxRetries sets the number of times the transaction is retried
+// on retryable errors, zero disables the retries
+func WithMaxRetries(retries int) TxOption {
+	return func(c *txConfig) {
+		if retries >= 0 {
+			c.maxRetries = retries
+		}
+	}
+}
+
+// WithRetryBackoff sets the base and the maximum delay between the retries
+func WithRetryBackoff(base time.Duration, max time.Duration) TxOption {
+	return func(c *txConfig) {
+		if base > 0 {
+			c.retryBaseDelay = base
+		}
+		if max >= base {
+			c.retryMaxDelay = max
+		}
+	}
+}
+
+// TxOptions converts the options shared by the storages to the ones of the sql storage
+func TxOptions(opts ...transaction.Option) []TxOption {
+	o := transaction.NewOptions(opts...)
+
+	txOpts := []TxOption{WithIsolation(o.Isolation)}
+	if o.ReadOnly {
+		txOpts = append(txOpts, WithReadOnly())
+	}
+	if o.MaxRetries >= 0 {
+		txOpts = append(txOpts, WithMaxRetries(o.MaxRetries))
+	}
+
+	return txOpts
+}
+
+// sqlTxOptions returns the options to begin the transaction with
+func (c *txConfig) sqlTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{
+		Isolation: c.isolation,
+		ReadOnly:  c.readOnly,
+	}
+}
+
+// backoff returns the jittered delay before the given retry attempt
+// the delay grows exponentially and is capped by retryMaxDelay
+func (c *txConfig) backoff(attempt int) time.Duration {
+	delay := c.retryBaseDelay << attempt
+	if delay <= 0 || delay > c.retryMaxDelay {
+		delay = c.retryMaxDelay
+	}
+
+	// full jitter to spread the retries of the conflicting transactions
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// wait blocks for the delay or until the context is done
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
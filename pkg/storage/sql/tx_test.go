@@ -0,0 +1,184 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/transaction"
+)
+
+// newMockRepo returns the storage connected to a mock of a postgres database
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("create the database mock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations of the database: %v", err)
+		}
+		_ = conn.Close()
+	})
+
+	instance, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open the database mock: %v", err)
+	}
+
+	return Repo{Db: &DB{instance: instance}}, mock
+}
+
+// insert runs a statement in the transaction of the context
+func insert(repo Repo) func(ctx context.Context) errors.IError {
+	return func(ctx context.Context) errors.IError {
+		return GetDBError(repo.DBInstance(ctx).Exec("INSERT INTO images DEFAULT VALUES"))
+	}
+}
+
+// fastRetries keeps the backoff of the tests short
+var fastRetries = WithRetryBackoff(time.Millisecond, time.Millisecond)
+
+func TestTransactionRetriesSerializationFailures(t *testing.T) {
+	repo, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO images").WillReturnError(&pgconn.PgError{Code: pgCodeSerializationFailure})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO images").WillReturnError(&pgconn.PgError{Code: pgCodeDeadlockDetected})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO images").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	calls := 0
+	err := repo.Transaction(context.Background(), func(ctx context.Context) errors.IError {
+		calls++
+		return insert(repo)(ctx)
+	}, fastRetries)
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected the transaction to run 3 times, got %d", calls)
+	}
+}
+
+func TestTransactionRetriesAreBounded(t *testing.T) {
+	repo, mock := newMockRepo(t)
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO images").WillReturnError(&pgconn.PgError{Code: pgCodeSerializationFailure})
+		mock.ExpectRollback()
+	}
+
+	err := repo.Transaction(context.Background(), insert(repo), fastRetries, WithMaxRetries(1))
+	if err == nil || !err.IsRetryable() || err.Error() != errSerializationFailure {
+		t.Errorf("expected the serialization failure once the retries are exhausted, got %v", err)
+	}
+}
+
+func TestTransactionDoesNotRetryOtherErrors(t *testing.T) {
+	repo, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO images").WillReturnError(&pgconn.PgError{Code: pgCodeUniqueViolation})
+	mock.ExpectRollback()
+
+	calls := 0
+	err := repo.Transaction(context.Background(), func(ctx context.Context) errors.IError {
+		calls++
+		return insert(repo)(ctx)
+	}, fastRetries)
+	if err == nil || err.Error() != errUniqueViolation || calls != 1 {
+		t.Errorf("expected the unique violation without retry, got %v after %d calls", err, calls)
+	}
+}
+
+func TestNestedTransactionUsesSavepoint(t *testing.T) {
+	repo, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO images").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("SAVEPOINT sp").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO images").WillReturnError(&pgconn.PgError{Code: pgCodeSerializationFailure})
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	inner := 0
+	err := repo.Transaction(context.Background(), func(ctx context.Context) errors.IError {
+		if err := insert(repo)(ctx); err != nil {
+			return err
+		}
+
+		// the failed savepoint is rolled back on its own and never retried
+		err := repo.Transaction(ctx, func(ctx context.Context) errors.IError {
+			inner++
+			return insert(repo)(ctx)
+		}, fastRetries)
+		if err == nil || inner != 1 {
+			t.Errorf("expected the error of the savepoint without retry, got %v after %d calls", err, inner)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+}
+
+func TestTxOptions(t *testing.T) {
+	config := newTxConfig(TxOptions(
+		transaction.WithIsolation(sql.LevelSerializable),
+		transaction.WithReadOnly(),
+		transaction.WithMaxRetries(0),
+	)...)
+
+	if options := config.sqlTxOptions(); options.Isolation != sql.LevelSerializable || !options.ReadOnly {
+		t.Errorf("unexpected options: %+v", options)
+	}
+	if config.maxRetries != 0 {
+		t.Errorf("expected the retries to be disabled, got %d", config.maxRetries)
+	}
+
+	// the defaults of the storage are kept when unset
+	config = newTxConfig(TxOptions()...)
+	if options := config.sqlTxOptions(); options.Isolation != sql.LevelDefault || options.ReadOnly {
+		t.Errorf("unexpected options: %+v", options)
+	}
+	if config.maxRetries != DefaultTxMaxRetries {
+		t.Errorf("expected the default retries, got %d", config.maxRetries)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := newTxConfig(WithRetryBackoff(10*time.Millisecond, 40*time.Millisecond))
+
+	for attempt, bound := range []time.Duration{10, 20, 40, 40, 40} {
+		for i := 0; i < 20; i++ {
+			if delay := config.backoff(attempt); delay < 0 || delay > bound*time.Millisecond {
+				t.Fatalf("backoff(%d) = %v, want at most %v", attempt, delay, bound*time.Millisecond)
+			}
+		}
+	}
+
+	// the shift overflows after many attempts
+	if delay := config.backoff(100); delay < 0 || delay > 40*time.Millisecond {
+		t.Errorf("backoff(100) = %v, want at most the maximum delay", delay)
+	}
+}
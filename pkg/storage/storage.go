@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
 	"github.com/danushk97/image-analyzer/pkg/errors"
+	"github.com/danushk97/image-analyzer/pkg/storage/memory"
 	sql "github.com/danushk97/image-analyzer/pkg/storage/sql"
 )
 
@@ -14,23 +17,45 @@ type Choice string
 var (
 	// SQLChoice is the dialect for all sql storages
 	SQLChoice Choice = "sql"
+	// MemoryChoice keeps the records in the memory of the process, e.g. for
+	// the unit tests, they are lost on exit and not shared with other processes
+	MemoryChoice Choice = "memory"
 )
 
 // Config defines the database config
 type Config struct {
-	// Choice defines the storage choice: sql, dynamodb
+	// Choice defines the storage choice: sql, memory
 	Choice Choice
 	// SQL specifies the configuration
 	// if database choice is mysql
 	SQL sql.DbConnectionConfig
 }
 
+// Validate checks the storage choice and the configuration of the chosen storage
+func (c Config) Validate() error {
+	return validation.ValidateStruct(
+		&c,
+		validation.Field(&c.Choice, validation.Required, validation.In(SQLChoice, MemoryChoice)),
+		validation.Field(&c.SQL, validation.Skip.When(c.Choice != SQLChoice)),
+	)
+}
+
+// IsInProcess returns true when the records are held in the memory of the
+// process, by the memory store or an in memory SQLite database, the other
+// processes such as the outbox relay cannot read them
+func (c Config) IsInProcess() bool {
+	return c.Choice == MemoryChoice ||
+		(c.Choice == SQLChoice && c.SQL.Dialect == sql.DialectSQLite && c.SQL.Name == sql.SQLiteMemory)
+}
+
 // Store is the interface supporting all storage operations
 type Store interface {
 	Create(
 		ctx context.Context, receiver sql.IModel) errors.IError
 	FindByID(
 		ctx context.Context, receiver sql.IModel, id string) errors.IError
+	Update(
+		ctx context.Context, receiver sql.IModel, attributes ...string) errors.IError
 	Delete(
 		ctx context.Context, receiver sql.IModel) errors.IError
 }
@@ -44,6 +69,8 @@ func New(ctx context.Context, config Config) (Store, error) {
 			return nil, err
 		}
 		return &sql.Repo{Db: db}, nil
+	case MemoryChoice:
+		return memory.NewStore(), nil
 	}
 
 	return nil, fmt.Errorf("unknown database choice: %v", config.Choice)
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/danushk97/image-analyzer/pkg/storage/sql"
+)
+
+func TestIsInProcess(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   bool
+	}{
+		{name: "memory", config: Config{Choice: MemoryChoice}, want: true},
+		{
+			name:   "sqlite in memory",
+			config: Config{Choice: SQLChoice, SQL: sql.DbConnectionConfig{Dialect: sql.DialectSQLite, Name: sql.SQLiteMemory}},
+			want:   true,
+		},
+		{
+			name:   "sqlite file",
+			config: Config{Choice: SQLChoice, SQL: sql.DbConnectionConfig{Dialect: sql.DialectSQLite, Name: "image_service.db"}},
+		},
+		{
+			name:   "postgres",
+			config: Config{Choice: SQLChoice, SQL: sql.DbConnectionConfig{Dialect: sql.DialectPostgres, Name: "image_service"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.IsInProcess(); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Package transaction holds the options of the transactions shared by the
+// repos of every storage, each storage applies the settings it supports
+package transaction
+
+import "database/sql"
+
+// Option configures the execution of a transaction
+type Option func(*Options)
+
+// Options holds the settings of a transaction
+type Options struct {
+	// Isolation is the isolation level, the default of the storage when unset
+	Isolation sql.IsolationLevel
+	// ReadOnly marks the transaction as read only
+	ReadOnly bool
+	// MaxRetries is the number of times the transaction is retried on
+	// retryable errors, the default of the storage when negative
+	MaxRetries int
+}
+
+// NewOptions applies the options over the defaults
+func NewOptions(opts ...Option) Options {
+	o := Options{Isolation: sql.LevelDefault, MaxRetries: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithIsolation sets the isolation level of the transaction
+func WithIsolation(level sql.IsolationLevel) Option {
+	return func(o *Options) {
+		o.Isolation = level
+	}
+}
+
+// WithReadOnly marks the transaction as read only
+func WithReadOnly() Option {
+	return func(o *Options) {
+		o.ReadOnly = true
+	}
+}
+
+// WithMaxRetries sets the number of times the transaction is retried
+// on retryable errors, zero disables the retries
+func WithMaxRetries(retries int) Option {
+	return func(o *Options) {
+		if retries >= 0 {
+			o.MaxRetries = retries
+		}
+	}
+}
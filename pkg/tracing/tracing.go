@@ -0,0 +1,199 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// InstrumentationName identifies the spans created by the application
+	InstrumentationName = "github.com/danushk97/image-analyzer"
+	// ShutdownTimeout is the time allowed to flush the spans on shutdown
+	ShutdownTimeout = 5 * time.Second
+)
+
+// HeaderTraceParent is the W3C trace context header
+const HeaderTraceParent = "traceparent"
+
+const (
+	// ExporterOTLP exports the spans to an OTLP collector over HTTP
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes the spans to the standard output
+	ExporterStdout = "stdout"
+	// ExporterFile writes the spans to a file
+	ExporterFile = "file"
+)
+
+// Config holds the tracing configurations
+type Config struct {
+	// Exporter of the spans: otlp, stdout or file, the spans
+	// are still created for the trace IDs of the logs when empty
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector
+	Endpoint string
+	// Insecure disables TLS towards the OTLP collector
+	Insecure bool
+	// FilePath is the file written by the file exporter
+	FilePath string
+	// SampleRatio is the ratio of the root spans sampled, between 0 and 1
+	SampleRatio float64
+}
+
+// Validate checks the exporter and the sample ratio
+func (c Config) Validate() error {
+	return validation.ValidateStruct(
+		&c,
+		validation.Field(&c.Exporter, validation.In(ExporterOTLP, ExporterStdout, ExporterFile)),
+		validation.Field(&c.FilePath, validation.When(c.Exporter == ExporterFile, validation.Required)),
+		validation.Field(&c.SampleRatio, validation.Min(0.0), validation.Max(1.0)),
+	)
+}
+
+// Provider is the tracer provider along with the resources of its exporter
+type Provider struct {
+	*sdktrace.TracerProvider
+
+	closer io.Closer
+}
+
+// NewProvider creates the tracer provider of the service and sets it, along with
+// the W3C trace context and baggage propagators, as the global ones
+func NewProvider(ctx context.Context, config Config, serviceName string) (*Provider, error) {
+	res, err := resource.New(
+		ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &Provider{}
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(
+			sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio)),
+		),
+	}
+
+	exporter, err := provider.newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider.TracerProvider = sdktrace.NewTracerProvider(options...)
+
+	otel.SetTracerProvider(provider.TracerProvider)
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+	)
+
+	return provider, nil
+}
+
+// Shutdown flushes the spans and releases the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.TracerProvider.Shutdown(ctx)
+
+	if p.closer != nil {
+		if closeErr := p.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// newExporter creates the exporter of the config, nil when the spans are not exported
+func (p *Provider) newExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case "":
+		return nil, nil
+
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{}
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+	case ExporterFile:
+		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		p.closer = file
+		return stdouttrace.New(stdouttrace.WithWriter(file))
+
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %v", config.Exporter)
+	}
+}
+
+// TraceParent returns the W3C traceparent of the span in the context,
+// it is stored along with the jobs to continue their trace
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return carrier.Get(HeaderTraceParent)
+}
+
+// WithTraceParent sets the span of the W3C traceparent as the remote parent
+// of the spans started from the context, invalid values are ignored
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+
+	carrier := propagation.MapCarrier{HeaderTraceParent: traceParent}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
+
+// Tracer returns the tracer of the application from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Start starts a span as a child of the span in the context
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording the error when there is one
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	goerr "errors"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	tests := []struct {
+		name   string
+		err    error
+		status codes.Code
+	}{
+		{name: "success", status: codes.Unset},
+		{name: "failure", err: goerr.New("db unavailable"), status: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, span := Start(context.Background(), tt.name)
+			End(span, tt.err)
+
+			spans := recorder.Ended()
+			got := spans[len(spans)-1]
+			if got.Name() != tt.name || got.Status().Code != tt.status {
+				t.Errorf("expected span %q with status %v, got %q with %v", tt.name, tt.status, got.Name(), got.Status().Code)
+			}
+			if recorded := len(got.Events()) > 0; recorded != (tt.err != nil) {
+				t.Errorf("expected the error recorded to be %v, got events %v", tt.err != nil, got.Events())
+			}
+		})
+	}
+}
+
+func TestNewProviderExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "not exported", config: Config{}},
+		{name: "stdout", config: Config{Exporter: ExporterStdout}},
+		{name: "file", config: Config{Exporter: ExporterFile, FilePath: filepath.Join(t.TempDir(), "traces.json")}},
+		{name: "file in a missing directory", config: Config{Exporter: ExporterFile, FilePath: filepath.Join(t.TempDir(), "missing", "traces.json")}, wantErr: true},
+		{name: "unknown exporter", config: Config{Exporter: "jaeger"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(context.Background(), tt.config, "image-analyzer")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected an error to be %v, got %v", tt.wantErr, err)
+			}
+			if err == nil {
+				if err := provider.Shutdown(context.Background()); err != nil {
+					t.Errorf("shutdown: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTraceParent(t *testing.T) {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name        string
+		traceParent string
+		want        string
+	}{
+		{name: "valid", traceParent: traceParent, want: traceParent},
+		{name: "empty", traceParent: ""},
+		{name: "malformed", traceParent: "00-4bf92f3577b34da6-00f067aa0ba902b7-01"},
+		{name: "all zero span id", traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{name: "unsupported version", traceParent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithTraceParent(context.Background(), tt.traceParent)
+			if got := TraceParent(ctx); got != tt.want {
+				t.Errorf("expected the traceparent %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
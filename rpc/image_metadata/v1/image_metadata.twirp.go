@@ -0,0 +1,1430 @@
+// Code generated by protoc-gen-twirp v5.10.1, DO NOT EDIT.
+// source: image_metadata/v1/image_metadata.proto
+
+/*
+Package image_metadatav1 is a generated twirp stub package.
+This code was generated with github.com/twitchtv/twirp/protoc-gen-twirp v5.10.1.
+
+It is generated from these files:
+
+	image_metadata/v1/image_metadata.proto
+*/
+package image_metadatav1
+
+import bytes "bytes"
+import strings "strings"
+import context "context"
+import fmt "fmt"
+import ioutil "io/ioutil"
+import http "net/http"
+import strconv "strconv"
+
+import jsonpb "github.com/golang/protobuf/jsonpb"
+import proto "github.com/golang/protobuf/proto"
+import twirp "github.com/twitchtv/twirp"
+import ctxsetters "github.com/twitchtv/twirp/ctxsetters"
+
+// Imports only used by utility functions:
+import io "io"
+import json "encoding/json"
+import url "net/url"
+
+// ==============================
+// ImageMetadataService Interface
+// ==============================
+
+// ImageMetadataService manages the metadata of the images of the caller.
+// The caller is identified by the x-user-id metadata.
+type ImageMetadataService interface {
+	// CreateImageMetadata registers a new image to be uploaded
+	CreateImageMetadata(context.Context, *CreateImageMetadataRequest) (*CreateImageMetadataResponse, error)
+
+	// GetImageMetadata returns an image of the caller
+	GetImageMetadata(context.Context, *GetImageMetadataRequest) (*GetImageMetadataResponse, error)
+
+	// ListImageMetadata returns a page of the images of the caller, latest first
+	ListImageMetadata(context.Context, *ListImageMetadataRequest) (*ListImageMetadataResponse, error)
+
+	// DeleteImageMetadata removes an image of the caller
+	DeleteImageMetadata(context.Context, *DeleteImageMetadataRequest) (*DeleteImageMetadataResponse, error)
+}
+
+// ====================================
+// ImageMetadataService Protobuf Client
+// ====================================
+
+type imageMetadataServiceProtobufClient struct {
+	client HTTPClient
+	urls   [4]string
+	opts   twirp.ClientOptions
+}
+
+// NewImageMetadataServiceProtobufClient creates a Protobuf client that implements the ImageMetadataService interface.
+// It communicates using Protobuf and can be configured with a custom HTTPClient.
+func NewImageMetadataServiceProtobufClient(addr string, client HTTPClient, opts ...twirp.ClientOption) ImageMetadataService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	prefix := urlBase(addr) + ImageMetadataServicePathPrefix
+	urls := [4]string{
+		prefix + "CreateImageMetadata",
+		prefix + "GetImageMetadata",
+		prefix + "ListImageMetadata",
+		prefix + "DeleteImageMetadata",
+	}
+
+	return &imageMetadataServiceProtobufClient{
+		client: client,
+		urls:   urls,
+		opts:   clientOpts,
+	}
+}
+
+func (c *imageMetadataServiceProtobufClient) CreateImageMetadata(ctx context.Context, in *CreateImageMetadataRequest) (*CreateImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "CreateImageMetadata")
+	out := new(CreateImageMetadataResponse)
+	err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *imageMetadataServiceProtobufClient) GetImageMetadata(ctx context.Context, in *GetImageMetadataRequest) (*GetImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetImageMetadata")
+	out := new(GetImageMetadataResponse)
+	err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *imageMetadataServiceProtobufClient) ListImageMetadata(ctx context.Context, in *ListImageMetadataRequest) (*ListImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListImageMetadata")
+	out := new(ListImageMetadataResponse)
+	err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *imageMetadataServiceProtobufClient) DeleteImageMetadata(ctx context.Context, in *DeleteImageMetadataRequest) (*DeleteImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteImageMetadata")
+	out := new(DeleteImageMetadataResponse)
+	err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ================================
+// ImageMetadataService JSON Client
+// ================================
+
+type imageMetadataServiceJSONClient struct {
+	client HTTPClient
+	urls   [4]string
+	opts   twirp.ClientOptions
+}
+
+// NewImageMetadataServiceJSONClient creates a JSON client that implements the ImageMetadataService interface.
+// It communicates using JSON and can be configured with a custom HTTPClient.
+func NewImageMetadataServiceJSONClient(addr string, client HTTPClient, opts ...twirp.ClientOption) ImageMetadataService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	prefix := urlBase(addr) + ImageMetadataServicePathPrefix
+	urls := [4]string{
+		prefix + "CreateImageMetadata",
+		prefix + "GetImageMetadata",
+		prefix + "ListImageMetadata",
+		prefix + "DeleteImageMetadata",
+	}
+
+	return &imageMetadataServiceJSONClient{
+		client: client,
+		urls:   urls,
+		opts:   clientOpts,
+	}
+}
+
+func (c *imageMetadataServiceJSONClient) CreateImageMetadata(ctx context.Context, in *CreateImageMetadataRequest) (*CreateImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "CreateImageMetadata")
+	out := new(CreateImageMetadataResponse)
+	err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *imageMetadataServiceJSONClient) GetImageMetadata(ctx context.Context, in *GetImageMetadataRequest) (*GetImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetImageMetadata")
+	out := new(GetImageMetadataResponse)
+	err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *imageMetadataServiceJSONClient) ListImageMetadata(ctx context.Context, in *ListImageMetadataRequest) (*ListImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListImageMetadata")
+	out := new(ListImageMetadataResponse)
+	err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *imageMetadataServiceJSONClient) DeleteImageMetadata(ctx context.Context, in *DeleteImageMetadataRequest) (*DeleteImageMetadataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteImageMetadata")
+	out := new(DeleteImageMetadataResponse)
+	err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ===================================
+// ImageMetadataService Server Handler
+// ===================================
+
+type imageMetadataServiceServer struct {
+	ImageMetadataService
+	hooks *twirp.ServerHooks
+}
+
+func NewImageMetadataServiceServer(svc ImageMetadataService, hooks *twirp.ServerHooks) TwirpServer {
+	return &imageMetadataServiceServer{
+		ImageMetadataService: svc,
+		hooks:                hooks,
+	}
+}
+
+// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func (s *imageMetadataServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	writeError(ctx, resp, err, s.hooks)
+}
+
+// ImageMetadataServicePathPrefix is used for all URL paths on a twirp ImageMetadataService server.
+// Requests are always: POST ImageMetadataServicePathPrefix/method
+// It can be used in an HTTP mux to route twirp requests along with non-twirp requests on other routes.
+const ImageMetadataServicePathPrefix = "/twirp/image_metadata.v1.ImageMetadataService/"
+
+func (s *imageMetadataServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "image_metadata.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "ImageMetadataService")
+	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+
+	var err error
+	ctx, err = callRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	if req.Method != "POST" {
+		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
+		err = badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	switch req.URL.Path {
+	case "/twirp/image_metadata.v1.ImageMetadataService/CreateImageMetadata":
+		s.serveCreateImageMetadata(ctx, resp, req)
+		return
+	case "/twirp/image_metadata.v1.ImageMetadataService/GetImageMetadata":
+		s.serveGetImageMetadata(ctx, resp, req)
+		return
+	case "/twirp/image_metadata.v1.ImageMetadataService/ListImageMetadata":
+		s.serveListImageMetadata(ctx, resp, req)
+		return
+	case "/twirp/image_metadata.v1.ImageMetadataService/DeleteImageMetadata":
+		s.serveDeleteImageMetadata(ctx, resp, req)
+		return
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		err = badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, err)
+		return
+	}
+}
+
+func (s *imageMetadataServiceServer) serveCreateImageMetadata(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCreateImageMetadataJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreateImageMetadataProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *imageMetadataServiceServer) serveCreateImageMetadataJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(CreateImageMetadataRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the json request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *CreateImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.CreateImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateImageMetadataResponse and nil error while calling CreateImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	respBytes := buf.Bytes()
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) serveCreateImageMetadataProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to read request body"))
+		return
+	}
+	reqContent := new(CreateImageMetadataRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *CreateImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.CreateImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateImageMetadataResponse and nil error while calling CreateImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) serveGetImageMetadata(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveGetImageMetadataJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGetImageMetadataProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *imageMetadataServiceServer) serveGetImageMetadataJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(GetImageMetadataRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the json request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *GetImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.GetImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetImageMetadataResponse and nil error while calling GetImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	respBytes := buf.Bytes()
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) serveGetImageMetadataProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to read request body"))
+		return
+	}
+	reqContent := new(GetImageMetadataRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *GetImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.GetImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetImageMetadataResponse and nil error while calling GetImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) serveListImageMetadata(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListImageMetadataJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListImageMetadataProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *imageMetadataServiceServer) serveListImageMetadataJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(ListImageMetadataRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the json request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *ListImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.ListImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListImageMetadataResponse and nil error while calling ListImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	respBytes := buf.Bytes()
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) serveListImageMetadataProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to read request body"))
+		return
+	}
+	reqContent := new(ListImageMetadataRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *ListImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.ListImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListImageMetadataResponse and nil error while calling ListImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) serveDeleteImageMetadata(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDeleteImageMetadataJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeleteImageMetadataProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *imageMetadataServiceServer) serveDeleteImageMetadataJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	reqContent := new(DeleteImageMetadataRequest)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the json request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *DeleteImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.DeleteImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteImageMetadataResponse and nil error while calling DeleteImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	respBytes := buf.Bytes()
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) serveDeleteImageMetadataProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteImageMetadata")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to read request body"))
+		return
+	}
+	reqContent := new(DeleteImageMetadataRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	// Call service method
+	var respContent *DeleteImageMetadataResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = s.ImageMetadataService.DeleteImageMetadata(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteImageMetadataResponse and nil error while calling DeleteImageMetadata. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *imageMetadataServiceServer) ServiceDescriptor() ([]byte, int) {
+	return twirpFileDescriptor0, 0
+}
+
+func (s *imageMetadataServiceServer) ProtocGenTwirpVersion() string {
+	return "v5.10.1"
+}
+
+func (s *imageMetadataServiceServer) PathPrefix() string {
+	return ImageMetadataServicePathPrefix
+}
+
+// =====
+// Utils
+// =====
+
+// HTTPClient is the interface used by generated clients to send HTTP requests.
+// It is fulfilled by *(net/http).Client, which is sufficient for most users.
+// Users can provide their own implementation for special retry policies.
+//
+// HTTPClient implementations should not follow redirects. Redirects are
+// automatically disabled if *(net/http).Client is passed to client
+// constructors. See the withoutRedirects function in this file for more
+// details.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TwirpServer is the interface generated server structs will support: they're
+// HTTP handlers with additional methods for accessing metadata about the
+// service. Those accessors are a low-level API for building reflection tools.
+// Most people can think of TwirpServers as just http.Handlers.
+type TwirpServer interface {
+	http.Handler
+	// ServiceDescriptor returns gzipped bytes describing the .proto file that
+	// this service was generated from. Once unzipped, the bytes can be
+	// unmarshalled as a
+	// github.com/golang/protobuf/protoc-gen-go/descriptor.FileDescriptorProto.
+	//
+	// The returned integer is the index of this particular service within that
+	// FileDescriptorProto's 'Service' slice of ServiceDescriptorProtos. This is a
+	// low-level field, expected to be used for reflection.
+	ServiceDescriptor() ([]byte, int)
+	// ProtocGenTwirpVersion is the semantic version string of the version of
+	// twirp used to generate this file.
+	ProtocGenTwirpVersion() string
+	// PathPrefix returns the HTTP URL path prefix for all methods handled by this
+	// service. This can be used with an HTTP mux to route twirp requests
+	// alongside non-twirp requests on one HTTP listener.
+	PathPrefix() string
+}
+
+// WriteError writes an HTTP response with a valid Twirp error format (code, msg, meta).
+// Useful outside of the Twirp server (e.g. http middleware), but does not trigger hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func WriteError(resp http.ResponseWriter, err error) {
+	writeError(context.Background(), resp, err, nil)
+}
+
+// writeError writes Twirp errors in the response and triggers hooks.
+func writeError(ctx context.Context, resp http.ResponseWriter, err error, hooks *twirp.ServerHooks) {
+	// Non-twirp errors are wrapped as Internal (default)
+	twerr, ok := err.(twirp.Error)
+	if !ok {
+		twerr = twirp.InternalErrorWith(err)
+	}
+
+	statusCode := twirp.ServerHTTPStatusFromErrorCode(twerr.Code())
+	ctx = ctxsetters.WithStatusCode(ctx, statusCode)
+	ctx = callError(ctx, hooks, twerr)
+
+	respBody := marshalErrorToJSON(twerr)
+
+	resp.Header().Set("Content-Type", "application/json") // Error responses are always JSON
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBody)))
+	resp.WriteHeader(statusCode) // set HTTP status code and send response
+
+	_, writeErr := resp.Write(respBody)
+	if writeErr != nil {
+		// We have three options here. We could log the error, call the Error
+		// hook, or just silently ignore the error.
+		//
+		// Logging is unacceptable because we don't have a user-controlled
+		// logger; writing out to stderr without permission is too rude.
+		//
+		// Calling the Error hook would confuse users: it would mean the Error
+		// hook got called twice for one request, which is likely to lead to
+		// duplicated log messages and metrics, no matter how well we document
+		// the behavior.
+		//
+		// Silently ignoring the error is our least-bad option. It's highly
+		// likely that the connection is broken and the original 'err' says
+		// so anyway.
+		_ = writeErr
+	}
+
+	callResponseSent(ctx, hooks)
+}
+
+// urlBase helps ensure that addr specifies a scheme. If it is unparsable
+// as a URL, it returns addr unchanged.
+func urlBase(addr string) string {
+	// If the addr specifies a scheme, use it. If not, default to
+	// http. If url.Parse fails on it, return it unchanged.
+	url, err := url.Parse(addr)
+	if err != nil {
+		return addr
+	}
+	if url.Scheme == "" {
+		url.Scheme = "http"
+	}
+	return url.String()
+}
+
+// getCustomHTTPReqHeaders retrieves a copy of any headers that are set in
+// a context through the twirp.WithHTTPRequestHeaders function.
+// If there are no headers set, or if they have the wrong type, nil is returned.
+func getCustomHTTPReqHeaders(ctx context.Context) http.Header {
+	header, ok := twirp.HTTPRequestHeaders(ctx)
+	if !ok || header == nil {
+		return nil
+	}
+	copied := make(http.Header)
+	for k, vv := range header {
+		if vv == nil {
+			copied[k] = nil
+			continue
+		}
+		copied[k] = make([]string, len(vv))
+		copy(copied[k], vv)
+	}
+	return copied
+}
+
+// newRequest makes an http.Request from a client, adding common headers.
+func newRequest(ctx context.Context, url string, reqBody io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequest("POST", url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if customHeader := getCustomHTTPReqHeaders(ctx); customHeader != nil {
+		req.Header = customHeader
+	}
+	req.Header.Set("Accept", contentType)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Twirp-Version", "v5.10.1")
+	return req, nil
+}
+
+// JSON serialization for errors
+type twerrJSON struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// marshalErrorToJSON returns JSON from a twirp.Error, that can be used as HTTP error response body.
+// If serialization fails, it will use a descriptive Internal error instead.
+func marshalErrorToJSON(twerr twirp.Error) []byte {
+	// make sure that msg is not too large
+	msg := twerr.Msg()
+	if len(msg) > 1e6 {
+		msg = msg[:1e6]
+	}
+
+	tj := twerrJSON{
+		Code: string(twerr.Code()),
+		Msg:  msg,
+		Meta: twerr.MetaMap(),
+	}
+
+	buf, err := json.Marshal(&tj)
+	if err != nil {
+		buf = []byte("{\"type\": \"" + twirp.Internal + "\", \"msg\": \"There was an error but it could not be serialized into JSON\"}") // fallback
+	}
+
+	return buf
+}
+
+// errorFromResponse builds a twirp.Error from a non-200 HTTP response.
+// If the response has a valid serialized Twirp error, then it's returned.
+// If not, the response status code is used to generate a similar twirp
+// error. See twirpErrorFromIntermediary for more info on intermediary errors.
+func errorFromResponse(resp *http.Response) twirp.Error {
+	statusCode := resp.StatusCode
+	statusText := http.StatusText(statusCode)
+
+	if isHTTPRedirect(statusCode) {
+		// Unexpected redirect: it must be an error from an intermediary.
+		// Twirp clients don't follow redirects automatically, Twirp only handles
+		// POST requests, redirects should only happen on GET and HEAD requests.
+		location := resp.Header.Get("Location")
+		msg := fmt.Sprintf("unexpected HTTP status code %d %q received, Location=%q", statusCode, statusText, location)
+		return twirpErrorFromIntermediary(statusCode, msg, location)
+	}
+
+	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return wrapInternal(err, "failed to read server error response body")
+	}
+
+	var tj twerrJSON
+	dec := json.NewDecoder(bytes.NewReader(respBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&tj); err != nil || tj.Code == "" {
+		// Invalid JSON response; it must be an error from an intermediary.
+		msg := fmt.Sprintf("Error from intermediary with HTTP status code %d %q", statusCode, statusText)
+		return twirpErrorFromIntermediary(statusCode, msg, string(respBodyBytes))
+	}
+
+	errorCode := twirp.ErrorCode(tj.Code)
+	if !twirp.IsValidErrorCode(errorCode) {
+		msg := "invalid type returned from server error response: " + tj.Code
+		return twirp.InternalError(msg)
+	}
+
+	twerr := twirp.NewError(errorCode, tj.Msg)
+	for k, v := range tj.Meta {
+		twerr = twerr.WithMeta(k, v)
+	}
+	return twerr
+}
+
+// twirpErrorFromIntermediary maps HTTP errors from non-twirp sources to twirp errors.
+// The mapping is similar to gRPC: https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md.
+// Returned twirp Errors have some additional metadata for inspection.
+func twirpErrorFromIntermediary(status int, msg string, bodyOrLocation string) twirp.Error {
+	var code twirp.ErrorCode
+	if isHTTPRedirect(status) { // 3xx
+		code = twirp.Internal
+	} else {
+		switch status {
+		case 400: // Bad Request
+			code = twirp.Internal
+		case 401: // Unauthorized
+			code = twirp.Unauthenticated
+		case 403: // Forbidden
+			code = twirp.PermissionDenied
+		case 404: // Not Found
+			code = twirp.BadRoute
+		case 429, 502, 503, 504: // Too Many Requests, Bad Gateway, Service Unavailable, Gateway Timeout
+			code = twirp.Unavailable
+		default: // All other codes
+			code = twirp.Unknown
+		}
+	}
+
+	twerr := twirp.NewError(code, msg)
+	twerr = twerr.WithMeta("http_error_from_intermediary", "true") // to easily know if this error was from intermediary
+	twerr = twerr.WithMeta("status_code", strconv.Itoa(status))
+	if isHTTPRedirect(status) {
+		twerr = twerr.WithMeta("location", bodyOrLocation)
+	} else {
+		twerr = twerr.WithMeta("body", bodyOrLocation)
+	}
+	return twerr
+}
+
+func isHTTPRedirect(status int) bool {
+	return status >= 300 && status <= 399
+}
+
+// wrapInternal wraps an error with a prefix as an Internal error.
+// The original error cause is accessible by github.com/pkg/errors.Cause.
+func wrapInternal(err error, prefix string) twirp.Error {
+	return twirp.InternalErrorWith(&wrappedError{prefix: prefix, cause: err})
+}
+
+type wrappedError struct {
+	prefix string
+	cause  error
+}
+
+func (e *wrappedError) Cause() error  { return e.cause }
+func (e *wrappedError) Error() string { return e.prefix + ": " + e.cause.Error() }
+
+// ensurePanicResponses makes sure that rpc methods causing a panic still result in a Twirp Internal
+// error response (status 500), and error hooks are properly called with the panic wrapped as an error.
+// The panic is re-raised so it can be handled normally with middleware.
+func ensurePanicResponses(ctx context.Context, resp http.ResponseWriter, hooks *twirp.ServerHooks) {
+	if r := recover(); r != nil {
+		// Wrap the panic as an error so it can be passed to error hooks.
+		// The original error is accessible from error hooks, but not visible in the response.
+		err := errFromPanic(r)
+		twerr := &internalWithCause{msg: "Internal service panic", cause: err}
+		// Actually write the error
+		writeError(ctx, resp, twerr, hooks)
+		// If possible, flush the error to the wire.
+		f, ok := resp.(http.Flusher)
+		if ok {
+			f.Flush()
+		}
+
+		panic(r)
+	}
+}
+
+// errFromPanic returns the typed error if the recovered panic is an error, otherwise formats as error.
+func errFromPanic(p interface{}) error {
+	if err, ok := p.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", p)
+}
+
+// internalWithCause is a Twirp Internal error wrapping an original error cause, accessible
+// by github.com/pkg/errors.Cause, but the original error message is not exposed on Msg().
+type internalWithCause struct {
+	msg   string
+	cause error
+}
+
+func (e *internalWithCause) Cause() error                                { return e.cause }
+func (e *internalWithCause) Error() string                               { return e.msg + ": " + e.cause.Error() }
+func (e *internalWithCause) Code() twirp.ErrorCode                       { return twirp.Internal }
+func (e *internalWithCause) Msg() string                                 { return e.msg }
+func (e *internalWithCause) Meta(key string) string                      { return "" }
+func (e *internalWithCause) MetaMap() map[string]string                  { return nil }
+func (e *internalWithCause) WithMeta(key string, val string) twirp.Error { return e }
+
+// malformedRequestError is used when the twirp server cannot unmarshal a request
+func malformedRequestError(msg string) twirp.Error {
+	return twirp.NewError(twirp.Malformed, msg)
+}
+
+// badRouteError is used when the twirp server cannot route a request
+func badRouteError(msg string, method, url string) twirp.Error {
+	err := twirp.NewError(twirp.BadRoute, msg)
+	err = err.WithMeta("twirp_invalid_route", method+" "+url)
+	return err
+}
+
+// withoutRedirects makes sure that the POST request can not be redirected.
+// The standard library will, by default, redirect requests (including POSTs) if it gets a 302 or
+// 303 response, and also 301s in go1.8. It redirects by making a second request, changing the
+// method to GET and removing the body. This produces very confusing error messages, so instead we
+// set a redirect policy that always errors. This stops Go from executing the redirect.
+//
+// We have to be a little careful in case the user-provided http.Client has its own CheckRedirect
+// policy - if so, we'll run through that policy first.
+//
+// Because this requires modifying the http.Client, we make a new copy of the client and return it.
+func withoutRedirects(in *http.Client) *http.Client {
+	copy := *in
+	copy.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if in.CheckRedirect != nil {
+			// Run the input's redirect if it exists, in case it has side effects, but ignore any error it
+			// returns, since we want to use ErrUseLastResponse.
+			err := in.CheckRedirect(req, via)
+			_ = err // Silly, but this makes sure generated code passes errcheck -blank, which some people use.
+		}
+		return http.ErrUseLastResponse
+	}
+	return &copy
+}
+
+// doProtobufRequest makes a Protobuf request to the remote Twirp service.
+func doProtobufRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (err error) {
+	reqBodyBytes, err := proto.Marshal(in)
+	if err != nil {
+		return wrapInternal(err, "failed to marshal proto request")
+	}
+	reqBody := bytes.NewBuffer(reqBodyBytes)
+	if err = ctx.Err(); err != nil {
+		return wrapInternal(err, "aborted because context was done")
+	}
+
+	req, err := newRequest(ctx, url, reqBody, "application/protobuf")
+	if err != nil {
+		return wrapInternal(err, "could not build request")
+	}
+	ctx, err = callClientRequestPrepared(ctx, hooks, req)
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return wrapInternal(err, "failed to do request")
+	}
+
+	defer func() {
+		cerr := resp.Body.Close()
+		if err == nil && cerr != nil {
+			err = wrapInternal(cerr, "failed to close response body")
+		}
+	}()
+
+	if err = ctx.Err(); err != nil {
+		return wrapInternal(err, "aborted because context was done")
+	}
+
+	if resp.StatusCode != 200 {
+		return errorFromResponse(resp)
+	}
+
+	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return wrapInternal(err, "failed to read response body")
+	}
+	if err = ctx.Err(); err != nil {
+		return wrapInternal(err, "aborted because context was done")
+	}
+
+	if err = proto.Unmarshal(respBodyBytes, out); err != nil {
+		return wrapInternal(err, "failed to unmarshal proto response")
+	}
+	return nil
+}
+
+// doJSONRequest makes a JSON request to the remote Twirp service.
+func doJSONRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (err error) {
+	reqBody := bytes.NewBuffer(nil)
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(reqBody, in); err != nil {
+		return wrapInternal(err, "failed to marshal json request")
+	}
+	if err = ctx.Err(); err != nil {
+		return wrapInternal(err, "aborted because context was done")
+	}
+
+	req, err := newRequest(ctx, url, reqBody, "application/json")
+	if err != nil {
+		return wrapInternal(err, "could not build request")
+	}
+	ctx, err = callClientRequestPrepared(ctx, hooks, req)
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return wrapInternal(err, "failed to do request")
+	}
+
+	defer func() {
+		cerr := resp.Body.Close()
+		if err == nil && cerr != nil {
+			err = wrapInternal(cerr, "failed to close response body")
+		}
+	}()
+
+	if err = ctx.Err(); err != nil {
+		return wrapInternal(err, "aborted because context was done")
+	}
+
+	if resp.StatusCode != 200 {
+		return errorFromResponse(resp)
+	}
+
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(resp.Body, out); err != nil {
+		return wrapInternal(err, "failed to unmarshal json response")
+	}
+	if err = ctx.Err(); err != nil {
+		return wrapInternal(err, "aborted because context was done")
+	}
+	return nil
+}
+
+// Call twirp.ServerHooks.RequestReceived if the hook is available
+func callRequestReceived(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
+	if h == nil || h.RequestReceived == nil {
+		return ctx, nil
+	}
+	return h.RequestReceived(ctx)
+}
+
+// Call twirp.ServerHooks.RequestRouted if the hook is available
+func callRequestRouted(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
+	if h == nil || h.RequestRouted == nil {
+		return ctx, nil
+	}
+	return h.RequestRouted(ctx)
+}
+
+// Call twirp.ServerHooks.ResponsePrepared if the hook is available
+func callResponsePrepared(ctx context.Context, h *twirp.ServerHooks) context.Context {
+	if h == nil || h.ResponsePrepared == nil {
+		return ctx
+	}
+	return h.ResponsePrepared(ctx)
+}
+
+// Call twirp.ServerHooks.ResponseSent if the hook is available
+func callResponseSent(ctx context.Context, h *twirp.ServerHooks) {
+	if h == nil || h.ResponseSent == nil {
+		return
+	}
+	h.ResponseSent(ctx)
+}
+
+// Call twirp.ServerHooks.Error if the hook is available
+func callError(ctx context.Context, h *twirp.ServerHooks, err twirp.Error) context.Context {
+	if h == nil || h.Error == nil {
+		return ctx
+	}
+	return h.Error(ctx, err)
+}
+
+func callClientResponseReceived(ctx context.Context, h *twirp.ClientHooks) {
+	if h == nil || h.ResponseReceived == nil {
+		return
+	}
+	h.ResponseReceived(ctx)
+}
+
+func callClientRequestPrepared(ctx context.Context, h *twirp.ClientHooks, req *http.Request) (context.Context, error) {
+	if h == nil || h.RequestPrepared == nil {
+		return ctx, nil
+	}
+	return h.RequestPrepared(ctx, req)
+}
+
+func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error) {
+	if h == nil || h.Error == nil {
+		return
+	}
+	h.Error(ctx, err)
+}
+
+var twirpFileDescriptor0 = []byte{
+	// 541 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0x95, 0x93, 0x3a, 0x6d, 0x26, 0x50, 0xe8, 0x36, 0xa2, 0x8b, 0xa3, 0x4a, 0xc1, 0x07, 0x08,
+	0xd0, 0x3a, 0x4a, 0x91, 0x40, 0x15, 0x37, 0x40, 0x42, 0x45, 0x85, 0x83, 0x43, 0x2e, 0x5c, 0xa2,
+	0x6d, 0x3c, 0x24, 0xab, 0xf8, 0x0b, 0xef, 0x3a, 0x55, 0xf2, 0xe7, 0xf8, 0x29, 0xfc, 0x15, 0x94,
+	0xb5, 0x9b, 0xd4, 0xc9, 0x06, 0x59, 0xe2, 0xb6, 0xf3, 0xe6, 0xed, 0xce, 0xec, 0xbc, 0xa7, 0x81,
+	0xe7, 0x3c, 0x60, 0x63, 0x1c, 0x06, 0x28, 0x99, 0xc7, 0x24, 0xeb, 0xce, 0x7a, 0xdd, 0x22, 0xe2,
+	0xc4, 0x49, 0x24, 0x23, 0x72, 0xb4, 0x81, 0xce, 0x7a, 0xf6, 0xef, 0x0a, 0x3c, 0xbc, 0x5a, 0xa2,
+	0x5f, 0x73, 0x90, 0x1c, 0x42, 0x85, 0x7b, 0xd4, 0x68, 0x1b, 0x9d, 0xba, 0x5b, 0xe1, 0x1e, 0x39,
+	0x81, 0xfd, 0x54, 0x60, 0x32, 0xe4, 0x1e, 0xad, 0x28, 0xb0, 0xb6, 0x0c, 0xaf, 0x3c, 0x62, 0xc1,
+	0xc1, 0x4f, 0xee, 0x63, 0xc8, 0x02, 0xa4, 0x55, 0x95, 0x59, 0xc5, 0xa4, 0x05, 0xf5, 0xe5, 0x79,
+	0x28, 0xe7, 0x31, 0xd2, 0xbd, 0x75, 0xf2, 0xfb, 0x3c, 0x5e, 0x27, 0x05, 0x5f, 0x20, 0x35, 0xdb,
+	0x46, 0xa7, 0x9a, 0x25, 0xfb, 0x7c, 0x81, 0xa4, 0x09, 0xe6, 0x2d, 0xf7, 0xe4, 0x84, 0xd6, 0xda,
+	0x46, 0xc7, 0x74, 0xb3, 0x80, 0x3c, 0x81, 0xda, 0x04, 0xf9, 0x78, 0x22, 0xe9, 0xbe, 0x82, 0xf3,
+	0x68, 0x89, 0x0b, 0xc9, 0x64, 0x2a, 0xe8, 0x41, 0xd6, 0x5b, 0x16, 0x91, 0x17, 0xf0, 0x88, 0x85,
+	0xcc, 0x9f, 0x0b, 0x2e, 0x86, 0x09, 0x8a, 0xd4, 0x97, 0xb4, 0xae, 0x08, 0x87, 0x77, 0xb0, 0xab,
+	0x50, 0x72, 0x0a, 0x90, 0xc6, 0x7e, 0xc4, 0xbc, 0x61, 0x9a, 0xf8, 0x14, 0x14, 0xa7, 0x9e, 0x21,
+	0x83, 0xc4, 0x27, 0xcf, 0xe0, 0x81, 0x17, 0xdd, 0x86, 0x2b, 0x42, 0x43, 0x11, 0x1a, 0x77, 0xd8,
+	0x20, 0xf1, 0xed, 0x4b, 0xb0, 0x3e, 0x26, 0xc8, 0x24, 0x16, 0xc6, 0xe8, 0xe2, 0xaf, 0x14, 0x85,
+	0x5c, 0xfd, 0x55, 0x4d, 0xc9, 0x58, 0x0f, 0xe2, 0x1b, 0x0b, 0xd0, 0x1e, 0x40, 0x4b, 0x7b, 0x55,
+	0xc4, 0x51, 0x28, 0x90, 0xbc, 0x05, 0x53, 0x09, 0xa6, 0xee, 0x35, 0x2e, 0xda, 0xce, 0x96, 0x7c,
+	0x4e, 0xf1, 0x62, 0x46, 0xb7, 0x5f, 0xc2, 0xc9, 0x67, 0x94, 0xda, 0x76, 0x36, 0xc4, 0xb5, 0x5d,
+	0xa0, 0xdb, 0xd4, 0xff, 0x2c, 0x3f, 0x07, 0x7a, 0xcd, 0x85, 0xbe, 0x7e, 0x13, 0xcc, 0x51, 0x94,
+	0x86, 0x52, 0xbd, 0x69, 0xba, 0x59, 0x40, 0x08, 0xec, 0x89, 0x29, 0x8f, 0x95, 0xbf, 0x4c, 0x57,
+	0x9d, 0xef, 0x29, 0x5b, 0x2d, 0x28, 0xfb, 0x2f, 0x67, 0xd9, 0x7d, 0x78, 0xaa, 0x29, 0x7d, 0xef,
+	0x3f, 0x12, 0x03, 0x41, 0x8d, 0x76, 0xb5, 0xe4, 0x7f, 0x96, 0x74, 0xfb, 0x0c, 0xac, 0x4f, 0xe8,
+	0xa3, 0xc4, 0x52, 0x13, 0x3d, 0x85, 0x96, 0x96, 0x9d, 0x35, 0x71, 0xf1, 0xa7, 0x0a, 0xcd, 0x42,
+	0xa6, 0x8f, 0xc9, 0x8c, 0x8f, 0x90, 0x48, 0x38, 0xd6, 0x78, 0x81, 0x9c, 0x6b, 0xba, 0xdc, 0x6d,
+	0x37, 0xcb, 0x29, 0x4b, 0xcf, 0x67, 0x32, 0x85, 0xc7, 0x9b, 0xfa, 0x93, 0x57, 0x9a, 0x37, 0x76,
+	0xf8, 0xc9, 0x7a, 0x5d, 0x8a, 0x9b, 0x17, 0x0b, 0xe1, 0x68, 0x4b, 0x1d, 0xa2, 0x7b, 0x61, 0x97,
+	0x7d, 0xac, 0xb3, 0x72, 0xe4, 0xbc, 0x9e, 0x84, 0x63, 0x8d, 0x14, 0xda, 0x91, 0xee, 0x16, 0xd8,
+	0x72, 0xca, 0xd2, 0xb3, 0xaa, 0x1f, 0xae, 0x7f, 0x7c, 0x19, 0x73, 0x39, 0x49, 0x6f, 0x9c, 0x51,
+	0x14, 0x74, 0x3d, 0x16, 0xa6, 0x62, 0x32, 0xbd, 0x7c, 0x97, 0x6d, 0xe4, 0x73, 0xb5, 0x7e, 0x16,
+	0x98, 0x74, 0x93, 0x78, 0xd4, 0xdd, 0x5a, 0xdb, 0xef, 0x8b, 0xc8, 0xac, 0x77, 0x53, 0x53, 0x9b,
+	0xfb, 0xcd, 0xdf, 0x01, 0x00, 0x5b, 0x58, 0xd0, 0x2b, 0xe3, 0x05, 0x00, 0x00,
+}
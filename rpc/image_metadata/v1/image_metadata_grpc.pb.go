@@ -0,0 +1,238 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: image_metadata/v1/image_metadata.proto
+
+package image_metadatav1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	ImageMetadataService_CreateImageMetadata_FullMethodName = "/image_metadata.v1.ImageMetadataService/CreateImageMetadata"
+	ImageMetadataService_GetImageMetadata_FullMethodName    = "/image_metadata.v1.ImageMetadataService/GetImageMetadata"
+	ImageMetadataService_ListImageMetadata_FullMethodName   = "/image_metadata.v1.ImageMetadataService/ListImageMetadata"
+	ImageMetadataService_DeleteImageMetadata_FullMethodName = "/image_metadata.v1.ImageMetadataService/DeleteImageMetadata"
+)
+
+// ImageMetadataServiceClient is the client API for ImageMetadataService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ImageMetadataService manages the metadata of the images of the caller.
+// The caller is identified by the x-user-id metadata.
+type ImageMetadataServiceClient interface {
+	// CreateImageMetadata registers a new image to be uploaded
+	CreateImageMetadata(ctx context.Context, in *CreateImageMetadataRequest, opts ...grpc.CallOption) (*CreateImageMetadataResponse, error)
+	// GetImageMetadata returns an image of the caller
+	GetImageMetadata(ctx context.Context, in *GetImageMetadataRequest, opts ...grpc.CallOption) (*GetImageMetadataResponse, error)
+	// ListImageMetadata returns a page of the images of the caller, latest first
+	ListImageMetadata(ctx context.Context, in *ListImageMetadataRequest, opts ...grpc.CallOption) (*ListImageMetadataResponse, error)
+	// DeleteImageMetadata removes an image of the caller
+	DeleteImageMetadata(ctx context.Context, in *DeleteImageMetadataRequest, opts ...grpc.CallOption) (*DeleteImageMetadataResponse, error)
+}
+
+type imageMetadataServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewImageMetadataServiceClient(cc grpc.ClientConnInterface) ImageMetadataServiceClient {
+	return &imageMetadataServiceClient{cc}
+}
+
+func (c *imageMetadataServiceClient) CreateImageMetadata(ctx context.Context, in *CreateImageMetadataRequest, opts ...grpc.CallOption) (*CreateImageMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateImageMetadataResponse)
+	err := c.cc.Invoke(ctx, ImageMetadataService_CreateImageMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageMetadataServiceClient) GetImageMetadata(ctx context.Context, in *GetImageMetadataRequest, opts ...grpc.CallOption) (*GetImageMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetImageMetadataResponse)
+	err := c.cc.Invoke(ctx, ImageMetadataService_GetImageMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageMetadataServiceClient) ListImageMetadata(ctx context.Context, in *ListImageMetadataRequest, opts ...grpc.CallOption) (*ListImageMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListImageMetadataResponse)
+	err := c.cc.Invoke(ctx, ImageMetadataService_ListImageMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageMetadataServiceClient) DeleteImageMetadata(ctx context.Context, in *DeleteImageMetadataRequest, opts ...grpc.CallOption) (*DeleteImageMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteImageMetadataResponse)
+	err := c.cc.Invoke(ctx, ImageMetadataService_DeleteImageMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ImageMetadataServiceServer is the server API for ImageMetadataService service.
+// All implementations must embed UnimplementedImageMetadataServiceServer
+// for forward compatibility
+//
+// ImageMetadataService manages the metadata of the images of the caller.
+// The caller is identified by the x-user-id metadata.
+type ImageMetadataServiceServer interface {
+	// CreateImageMetadata registers a new image to be uploaded
+	CreateImageMetadata(context.Context, *CreateImageMetadataRequest) (*CreateImageMetadataResponse, error)
+	// GetImageMetadata returns an image of the caller
+	GetImageMetadata(context.Context, *GetImageMetadataRequest) (*GetImageMetadataResponse, error)
+	// ListImageMetadata returns a page of the images of the caller, latest first
+	ListImageMetadata(context.Context, *ListImageMetadataRequest) (*ListImageMetadataResponse, error)
+	// DeleteImageMetadata removes an image of the caller
+	DeleteImageMetadata(context.Context, *DeleteImageMetadataRequest) (*DeleteImageMetadataResponse, error)
+	mustEmbedUnimplementedImageMetadataServiceServer()
+}
+
+// UnimplementedImageMetadataServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedImageMetadataServiceServer struct {
+}
+
+func (UnimplementedImageMetadataServiceServer) CreateImageMetadata(context.Context, *CreateImageMetadataRequest) (*CreateImageMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateImageMetadata not implemented")
+}
+func (UnimplementedImageMetadataServiceServer) GetImageMetadata(context.Context, *GetImageMetadataRequest) (*GetImageMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetImageMetadata not implemented")
+}
+func (UnimplementedImageMetadataServiceServer) ListImageMetadata(context.Context, *ListImageMetadataRequest) (*ListImageMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListImageMetadata not implemented")
+}
+func (UnimplementedImageMetadataServiceServer) DeleteImageMetadata(context.Context, *DeleteImageMetadataRequest) (*DeleteImageMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteImageMetadata not implemented")
+}
+func (UnimplementedImageMetadataServiceServer) mustEmbedUnimplementedImageMetadataServiceServer() {}
+
+// UnsafeImageMetadataServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ImageMetadataServiceServer will
+// result in compilation errors.
+type UnsafeImageMetadataServiceServer interface {
+	mustEmbedUnimplementedImageMetadataServiceServer()
+}
+
+func RegisterImageMetadataServiceServer(s grpc.ServiceRegistrar, srv ImageMetadataServiceServer) {
+	s.RegisterService(&ImageMetadataService_ServiceDesc, srv)
+}
+
+func _ImageMetadataService_CreateImageMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateImageMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageMetadataServiceServer).CreateImageMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageMetadataService_CreateImageMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageMetadataServiceServer).CreateImageMetadata(ctx, req.(*CreateImageMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageMetadataService_GetImageMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetImageMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageMetadataServiceServer).GetImageMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageMetadataService_GetImageMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageMetadataServiceServer).GetImageMetadata(ctx, req.(*GetImageMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageMetadataService_ListImageMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListImageMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageMetadataServiceServer).ListImageMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageMetadataService_ListImageMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageMetadataServiceServer).ListImageMetadata(ctx, req.(*ListImageMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageMetadataService_DeleteImageMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteImageMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageMetadataServiceServer).DeleteImageMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageMetadataService_DeleteImageMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageMetadataServiceServer).DeleteImageMetadata(ctx, req.(*DeleteImageMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ImageMetadataService_ServiceDesc is the grpc.ServiceDesc for ImageMetadataService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ImageMetadataService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "image_metadata.v1.ImageMetadataService",
+	HandlerType: (*ImageMetadataServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateImageMetadata",
+			Handler:    _ImageMetadataService_CreateImageMetadata_Handler,
+		},
+		{
+			MethodName: "GetImageMetadata",
+			Handler:    _ImageMetadataService_GetImageMetadata_Handler,
+		},
+		{
+			MethodName: "ListImageMetadata",
+			Handler:    _ImageMetadataService_ListImageMetadata_Handler,
+		},
+		{
+			MethodName: "DeleteImageMetadata",
+			Handler:    _ImageMetadataService_DeleteImageMetadata_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "image_metadata/v1/image_metadata.proto",
+}